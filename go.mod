@@ -11,6 +11,7 @@ require (
 	github.com/onsi/gomega v1.18.1
 	github.com/stretchr/testify v1.8.1
 	go.uber.org/zap v1.19.0
+	gopkg.in/yaml.v3 v3.0.1
 	moul.io/http2curl v1.0.0
 )
 
@@ -43,5 +44,4 @@ require (
 	google.golang.org/protobuf v1.27.1 // indirect
 	gopkg.in/tomb.v1 v1.0.0-20141024135613-dd632973f1e7 // indirect
 	gopkg.in/yaml.v2 v2.4.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -1,8 +1,10 @@
 package artifact
 
 import (
+	"context"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
 
 	"github.com/jenkins-zh/jenkins-client/pkg/core"
@@ -25,6 +27,11 @@ type Client struct {
 
 // List get the list of artifacts from a build
 func (q *Client) List(jobName string, buildID int) (artifacts []Artifact, err error) {
+	return q.ListWithContext(context.Background(), jobName, buildID)
+}
+
+// ListWithContext get the list of artifacts from a build with a context
+func (q *Client) ListWithContext(ctx context.Context, jobName string, buildID int) (artifacts []Artifact, err error) {
 	path := job.ParseJobPath(jobName)
 	var api string
 	if buildID < 1 {
@@ -32,25 +39,39 @@ func (q *Client) List(jobName string, buildID int) (artifacts []Artifact, err er
 	} else {
 		api = fmt.Sprintf("%s/%d/wfapi/artifacts", path, buildID)
 	}
-	err = q.RequestWithData(http.MethodGet, api, nil, nil, 200, &artifacts)
+	err = q.RequestWithDataWithContext(ctx, http.MethodGet, api, nil, nil, 200, &artifacts)
 	return
 }
 
 // GetArtifact download artifact using stream
 func (q *Client) GetArtifact(projectName, pipelineName string, buildID int, filename string) (io.ReadCloser, error) {
-	return q.GetArtifactFromMultiBranchPipeline(projectName, pipelineName, false, "", buildID, filename)
+	return q.GetArtifactWithContext(context.Background(), projectName, pipelineName, buildID, filename)
+}
+
+// GetArtifactWithContext download artifact using stream with a context
+func (q *Client) GetArtifactWithContext(ctx context.Context, projectName, pipelineName string, buildID int, filename string) (io.ReadCloser, error) {
+	return q.GetArtifactFromMultiBranchPipelineWithContext(ctx, projectName, pipelineName, false, "", buildID, filename)
 }
 
 // GetArtifactFromMultiBranchPipeline download multi pipeline artifact using stream
 func (q *Client) GetArtifactFromMultiBranchPipeline(projectName, pipelineName string, isMultiBranch bool, branchName string, buildID int, filename string) (io.ReadCloser, error) {
+	return q.GetArtifactFromMultiBranchPipelineWithContext(context.Background(), projectName, pipelineName, isMultiBranch, branchName, buildID, filename)
+}
+
+// GetArtifactFromMultiBranchPipelineWithContext download multi pipeline artifact using stream with a context
+func (q *Client) GetArtifactFromMultiBranchPipelineWithContext(ctx context.Context, projectName, pipelineName string, isMultiBranch bool, branchName string, buildID int, filename string) (io.ReadCloser, error) {
 	artifactURL := generateArtifactURL(projectName, pipelineName, isMultiBranch, branchName, buildID, filename)
-	resp, err := q.RequestWithResponse(http.MethodGet, artifactURL, nil, nil)
+	resp, err := q.RequestWithResponseWithContext(ctx, http.MethodGet, artifactURL, nil, nil)
 	if err != nil {
 		return nil, err
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to get artifact. the HTTP status code is %d", resp.StatusCode)
+		defer func() {
+			_ = resp.Body.Close()
+		}()
+		data, _ := ioutil.ReadAll(resp.Body)
+		return nil, core.NewHTTPError(resp, data)
 	}
 
 	return resp.Body, nil
@@ -1,6 +1,7 @@
 package casc
 
 import (
+	"context"
 	"fmt"
 	"net/url"
 
@@ -14,9 +15,17 @@ type Manager struct {
 
 // Export exports the config of configuration-as-code
 func (c *Manager) Export() (config string, err error) {
+	return c.ExportWithContext(context.Background())
+}
+
+// ExportWithContext exports the config of configuration-as-code with a context
+func (c *Manager) ExportWithContext(ctx context.Context) (config string, err error) {
+	if err = c.Require(ctx, core.RequireCasC); err != nil {
+		return
+	}
 	request := core.NewRequest("/configuration-as-code/export", &c.JenkinsCore)
-	request.WithPostMethod()
-	if err = request.Do(); err == nil {
+	request.WithPostMethod().AsReadOnly()
+	if err = request.DoWithContext(ctx); err == nil {
 		config = string(request.GetData())
 	}
 	return
@@ -24,9 +33,17 @@ func (c *Manager) Export() (config string, err error) {
 
 // Schema get the schema of configuration-as-code
 func (c *Manager) Schema() (schema string, err error) {
+	return c.SchemaWithContext(context.Background())
+}
+
+// SchemaWithContext get the schema of configuration-as-code with a context
+func (c *Manager) SchemaWithContext(ctx context.Context) (schema string, err error) {
+	if err = c.Require(ctx, core.RequireCasC); err != nil {
+		return
+	}
 	request := core.NewRequest("/configuration-as-code/schema", &c.JenkinsCore)
-	request.WithPostMethod()
-	if err = request.Do(); err == nil {
+	request.WithPostMethod().AsReadOnly()
+	if err = request.DoWithContext(ctx); err == nil {
 		schema = string(request.GetData())
 	}
 	return
@@ -34,13 +51,29 @@ func (c *Manager) Schema() (schema string, err error) {
 
 // Reload reloads the config of configuration-as-code
 func (c *Manager) Reload() (err error) {
+	return c.ReloadWithContext(context.Background())
+}
+
+// ReloadWithContext reloads the config of configuration-as-code with a context
+func (c *Manager) ReloadWithContext(ctx context.Context) (err error) {
+	if err = c.Require(ctx, core.RequireCasC); err != nil {
+		return
+	}
 	request := core.NewRequest("/configuration-as-code/reload", &c.JenkinsCore)
-	err = request.WithPostMethod().Do()
+	err = request.WithPostMethod().DoWithContext(ctx)
 	return
 }
 
 // Replace replaces the new source
 func (c *Manager) Replace(source string) (err error) {
+	return c.ReplaceWithContext(context.Background(), source)
+}
+
+// ReplaceWithContext replaces the new source with a context
+func (c *Manager) ReplaceWithContext(ctx context.Context, source string) (err error) {
+	if err = c.Require(ctx, core.RequireCasC); err != nil {
+		return
+	}
 	formValue := make(url.Values)
 	formValue.Set("json", fmt.Sprintf(`{"newSource": "%s"}`, source))
 	formValue.Set("_.newSource", source)
@@ -48,7 +81,7 @@ func (c *Manager) Replace(source string) (err error) {
 	// Jenkins does not have a standard API. This is a form submit, so the expected code is not 200
 	request := core.NewRequest("/configuration-as-code/replace", &c.JenkinsCore)
 	request.WithPostMethod().AsFormRequest().WithValues(formValue).AcceptStatusCode(302)
-	err = request.Do()
+	err = request.DoWithContext(ctx)
 	if urlErr, ok := err.(*url.Error); ok && urlErr.Err.Error() == "302 response missing Location header" {
 		err = nil
 	}
@@ -57,19 +90,35 @@ func (c *Manager) Replace(source string) (err error) {
 
 // CheckNewSource checks the new source of CasC
 func (c *Manager) CheckNewSource(source string) (err error) {
+	return c.CheckNewSourceWithContext(context.Background(), source)
+}
+
+// CheckNewSourceWithContext checks the new source of CasC with a context
+func (c *Manager) CheckNewSourceWithContext(ctx context.Context, source string) (err error) {
+	if err = c.Require(ctx, core.RequireCasC); err != nil {
+		return
+	}
 	formValue := make(url.Values)
 	formValue.Set("newSource", source)
 
 	request := core.NewRequest("/configuration-as-code/checkNewSource", &c.JenkinsCore)
-	request.WithPostMethod().AsFormRequest().WithValues(formValue)
-	err = request.Do()
+	request.WithPostMethod().AsReadOnly().AsFormRequest().WithValues(formValue)
+	err = request.DoWithContext(ctx)
 	return
 }
 
 // Apply applies the config of configuration-as-code
 func (c *Manager) Apply() (err error) {
+	return c.ApplyWithContext(context.Background())
+}
+
+// ApplyWithContext applies the config of configuration-as-code with a context
+func (c *Manager) ApplyWithContext(ctx context.Context) (err error) {
+	if err = c.Require(ctx, core.RequireCasC); err != nil {
+		return
+	}
 	request := core.NewRequest("/configuration-as-code/apply", &c.JenkinsCore)
 	request.WithPostMethod()
-	err = request.Do()
+	err = request.DoWithContext(ctx)
 	return
 }
@@ -0,0 +1,111 @@
+// Package cassette records the HTTP interactions with Jenkins into files, then replays them in the tests
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Cassette holds the recorded HTTP interactions
+type Cassette struct {
+	Interactions []Interaction `yaml:"interactions" json:"interactions"`
+}
+
+// Interaction is a pair of the HTTP request and response
+type Interaction struct {
+	Request  Request  `yaml:"request" json:"request"`
+	Response Response `yaml:"response" json:"response"`
+}
+
+// Request is the recorded HTTP request
+type Request struct {
+	Method string      `yaml:"method" json:"method"`
+	URL    string      `yaml:"url" json:"url"`
+	Header http.Header `yaml:"header,omitempty" json:"header,omitempty"`
+	Body   string      `yaml:"body,omitempty" json:"body,omitempty"`
+}
+
+// Response is the recorded HTTP response
+type Response struct {
+	StatusCode int         `yaml:"statusCode" json:"statusCode"`
+	Header     http.Header `yaml:"header,omitempty" json:"header,omitempty"`
+	Body       string      `yaml:"body,omitempty" json:"body,omitempty"`
+}
+
+// isJSON returns true if the file should be in JSON format, or it's YAML
+func isJSON(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".json")
+}
+
+// Load reads a cassette from a YAML or JSON file, the format depends on the file extension
+func Load(path string) (cassette *Cassette, err error) {
+	var data []byte
+	if data, err = ioutil.ReadFile(path); err != nil {
+		return
+	}
+
+	cassette = &Cassette{}
+	if isJSON(path) {
+		err = json.Unmarshal(data, cassette)
+	} else {
+		err = yaml.Unmarshal(data, cassette)
+	}
+	if err != nil {
+		cassette = nil
+		err = fmt.Errorf("cannot parse the cassette %s, error is %v", path, err)
+	}
+	return
+}
+
+// Save writes the cassette into a YAML or JSON file, the format depends on the file extension
+func (c *Cassette) Save(path string) (err error) {
+	var data []byte
+	if isJSON(path) {
+		data, err = json.MarshalIndent(c, "", "  ")
+	} else {
+		data, err = yaml.Marshal(c)
+	}
+	if err != nil {
+		return
+	}
+
+	if err = os.MkdirAll(filepath.Dir(path), 0755); err == nil {
+		err = ioutil.WriteFile(path, data, 0644)
+	}
+	return
+}
+
+// toHTTPRequest converts the recorded request to a HTTP request
+func (r Request) toHTTPRequest() (request *http.Request, err error) {
+	if request, err = http.NewRequest(r.Method, r.URL, bytes.NewBufferString(r.Body)); err == nil {
+		request.Header = r.Header
+	}
+	return
+}
+
+// toHTTPResponse converts the recorded response to a HTTP response
+func (r Response) toHTTPResponse(request *http.Request) *http.Response {
+	header := r.Header
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", r.StatusCode, http.StatusText(r.StatusCode)),
+		StatusCode:    r.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewBufferString(r.Body)),
+		ContentLength: int64(len(r.Body)),
+		Request:       request,
+	}
+}
@@ -0,0 +1,149 @@
+package cassette
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jenkins-zh/jenkins-client/pkg/core"
+	"github.com/stretchr/testify/assert"
+)
+
+func newFakeJenkins() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/crumbIssuer/api/json":
+			_, _ = w.Write([]byte(`{"crumbRequestField":"Jenkins-Crumb","crumb":"crumb"}`))
+		case "/api/json":
+			http.SetCookie(w, &http.Cookie{Name: "JSESSIONID.fake", Value: "session"})
+			_, _ = w.Write([]byte(`{"nodeName":"master"}`))
+		case "/user/admin/descriptorByName/jenkins.security.ApiTokenProperty/generateNewToken":
+			_, _ = w.Write([]byte(`{"status":"ok","data":{"tokenName":"fake","tokenValue":"generated-token"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	for _, file := range []string{"cassette.yaml", "cassette.json"} {
+		t.Run(file, func(t *testing.T) {
+			server := newFakeJenkins()
+			path := filepath.Join(t.TempDir(), file)
+
+			recorder := NewRecorder(nil, "api-token")
+			jenkinsCore := &core.JenkinsCore{URL: server.URL, UserName: "admin", Token: "api-token", RoundTripper: recorder}
+			requests := func(jenkinsCore *core.JenkinsCore) {
+				statusCode, data, err := jenkinsCore.Request(http.MethodGet, "/api/json?pretty=true", nil, nil)
+				assert.Nil(t, err)
+				assert.Equal(t, http.StatusOK, statusCode)
+				assert.Equal(t, `{"nodeName":"master"}`, string(data))
+
+				statusCode, _, err = jenkinsCore.Request(http.MethodGet, "/missing", nil, nil)
+				assert.Nil(t, err)
+				assert.Equal(t, http.StatusNotFound, statusCode)
+
+				err = core.NewRequest("/user/admin/descriptorByName/jenkins.security.ApiTokenProperty/generateNewToken",
+					jenkinsCore).AsPostFormRequest().WithValues(url.Values{"newTokenName": {"fake"}}).Do()
+				assert.Nil(t, err)
+			}
+			requests(jenkinsCore)
+			assert.Nil(t, recorder.Save(path))
+			server.Close()
+
+			data, err := ioutil.ReadFile(path)
+			assert.Nil(t, err)
+			assert.False(t, strings.Contains(string(data), "api-token"))
+			assert.False(t, strings.Contains(string(data), "generated-token"))
+			assert.False(t, strings.Contains(string(data), "session"))
+			assert.True(t, strings.Contains(string(data), Redacted))
+
+			// replay without the server
+			replayer, err := LoadReplayer(path, "api-token")
+			assert.Nil(t, err)
+			assert.Equal(t, 4, len(replayer.Unused()))
+			jenkinsCore.RoundTripper = replayer
+			requests(jenkinsCore)
+			assert.Empty(t, replayer.Unused())
+
+			// all the interactions are used
+			_, _, err = jenkinsCore.Request(http.MethodGet, "/missing", nil, nil)
+			assert.NotNil(t, err)
+		})
+	}
+}
+
+func TestReplayMatch(t *testing.T) {
+	replayer := NewReplayer(&Cassette{Interactions: []Interaction{{
+		Request:  Request{Method: http.MethodPost, URL: "http://localhost/job/fake/build?delay=0", Body: "a=b"},
+		Response: Response{StatusCode: http.StatusCreated},
+	}}})
+
+	tests := []struct {
+		name   string
+		method string
+		url    string
+		body   string
+		match  bool
+	}{{
+		name:   "different method",
+		method: http.MethodGet,
+		url:    "http://localhost/job/fake/build?delay=0",
+		body:   "a=b",
+	}, {
+		name:   "different query",
+		method: http.MethodPost,
+		url:    "http://localhost/job/fake/build?delay=1",
+		body:   "a=b",
+	}, {
+		name:   "different body",
+		method: http.MethodPost,
+		url:    "http://localhost/job/fake/build?delay=0",
+		body:   "a=c",
+	}, {
+		name:   "matched",
+		method: http.MethodPost,
+		url:    "http://localhost/job/fake/build?delay=0",
+		body:   "a=b",
+		match:  true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request, _ := http.NewRequest(tt.method, tt.url, strings.NewReader(tt.body))
+			request.Header.Set("Authorization", "Basic fake")
+			response, err := replayer.RoundTrip(request)
+			if tt.match {
+				assert.Nil(t, err)
+				assert.Equal(t, http.StatusCreated, response.StatusCode)
+			} else {
+				assert.NotNil(t, err)
+			}
+		})
+	}
+}
+
+func TestScrubber(t *testing.T) {
+	scrubber := NewScrubber("plain-secret")
+
+	request := Request{
+		URL:    "http://localhost/api?token=abc&name=fake",
+		Header: http.Header{"Authorization": {"Basic abc"}, "Content-Type": {"application/x-www-form-urlencoded"}},
+		Body:   `password=abc&json={"secret":"abc","name":"fake"}&desc=plain-secret`,
+	}
+	scrubber.ScrubRequest(&request)
+	assert.Equal(t, "http://localhost/api?name=fake&token=%5BREDACTED%5D", request.URL)
+	assert.Equal(t, Redacted, request.Header.Get("Authorization"))
+	values, err := url.ParseQuery(request.Body)
+	assert.Nil(t, err)
+	assert.Equal(t, Redacted, values.Get("password"))
+	assert.Equal(t, Redacted, values.Get("desc"))
+	assert.Equal(t, `{"secret":"[REDACTED]","name":"fake"}`, values.Get("json"))
+
+	response := Response{Body: `{"privateKey" : "abc", "crumbRequestField":"Jenkins-Crumb"}`}
+	scrubber.ScrubResponse(&response)
+	assert.Equal(t, `{"privateKey" : "[REDACTED]", "crumbRequestField":"Jenkins-Crumb"}`, response.Body)
+}
@@ -0,0 +1,181 @@
+package cassette
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+
+	"github.com/jenkins-zh/jenkins-client/pkg/core"
+)
+
+// Recorder is a RoundTripper which records the interactions with the real server
+type Recorder struct {
+	// Next sends the real requests, http.DefaultTransport is used if it's nil
+	Next     http.RoundTripper
+	Scrubber *Scrubber
+
+	mutex    sync.Mutex
+	cassette Cassette
+}
+
+// NewRecorder creates a Recorder which scrubs the default sensitive data and the given secrets
+func NewRecorder(next http.RoundTripper, secrets ...string) *Recorder {
+	return &Recorder{Next: next, Scrubber: NewScrubber(secrets...)}
+}
+
+// RoundTrip sends the request, then records the request and response
+func (r *Recorder) RoundTrip(request *http.Request) (response *http.Response, err error) {
+	next := r.Next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	var requestBody []byte
+	if request.Body != nil {
+		if requestBody, err = ioutil.ReadAll(request.Body); err != nil {
+			return
+		}
+		_ = request.Body.Close()
+		request.Body = ioutil.NopCloser(bytes.NewReader(requestBody))
+	}
+
+	if response, err = next.RoundTrip(request); err != nil {
+		return
+	}
+
+	var responseBody []byte
+	responseBody, err = ioutil.ReadAll(response.Body)
+	_ = response.Body.Close()
+	if err != nil {
+		return
+	}
+	response.Body = ioutil.NopCloser(bytes.NewReader(responseBody))
+
+	interaction := Interaction{
+		Request: Request{
+			Method: request.Method,
+			URL:    request.URL.String(),
+			Header: request.Header.Clone(),
+			Body:   string(requestBody),
+		},
+		Response: Response{
+			StatusCode: response.StatusCode,
+			Header:     response.Header.Clone(),
+			Body:       string(responseBody),
+		},
+	}
+	if r.Scrubber != nil {
+		r.Scrubber.ScrubRequest(&interaction.Request)
+		r.Scrubber.ScrubResponse(&interaction.Response)
+	}
+
+	r.mutex.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+	r.mutex.Unlock()
+	return
+}
+
+// Cassette returns a copy of the recorded interactions
+func (r *Recorder) Cassette() *Cassette {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return &Cassette{Interactions: append([]Interaction{}, r.cassette.Interactions...)}
+}
+
+// Save writes the recorded interactions into a YAML or JSON file
+func (r *Recorder) Save(path string) error {
+	return r.Cassette().Save(path)
+}
+
+// Replayer is a RoundTripper which responds with the recorded interactions instead of a real server.
+// A request matches an interaction if they have the same method, path, query and body.
+// Each interaction is used once, in the recorded order.
+type Replayer struct {
+	Cassette *Cassette
+	// Scrubber should be the same one of the recorder, then the secrets in the requests could match
+	Scrubber *Scrubber
+
+	mutex sync.Mutex
+	used  []bool
+}
+
+// NewReplayer creates a Replayer with the default scrubber
+func NewReplayer(cassette *Cassette, secrets ...string) *Replayer {
+	return &Replayer{
+		Cassette: cassette,
+		Scrubber: NewScrubber(secrets...),
+		used:     make([]bool, len(cassette.Interactions)),
+	}
+}
+
+// LoadReplayer creates a Replayer from a cassette file
+func LoadReplayer(path string, secrets ...string) (replayer *Replayer, err error) {
+	var cassette *Cassette
+	if cassette, err = Load(path); err == nil {
+		replayer = NewReplayer(cassette, secrets...)
+	}
+	return
+}
+
+// RoundTrip responds with the first unused interaction which matches the request
+func (r *Replayer) RoundTrip(request *http.Request) (response *http.Response, err error) {
+	target := Request{
+		Method: request.Method,
+		URL:    request.URL.String(),
+		Header: request.Header,
+	}
+	if request.Body != nil {
+		var data []byte
+		if data, err = ioutil.ReadAll(request.Body); err != nil {
+			return
+		}
+		_ = request.Body.Close()
+		target.Body = string(data)
+	}
+	if r.Scrubber != nil {
+		r.Scrubber.ScrubRequest(&target)
+	}
+
+	var targetRequest *http.Request
+	if targetRequest, err = target.toHTTPRequest(); err != nil {
+		return
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if len(r.used) < len(r.Cassette.Interactions) {
+		r.used = append(r.used, make([]bool, len(r.Cassette.Interactions)-len(r.used))...)
+	}
+
+	for i, interaction := range r.Cassette.Interactions {
+		if r.used[i] {
+			continue
+		}
+
+		var recorded *http.Request
+		if recorded, err = interaction.Request.toHTTPRequest(); err != nil {
+			return
+		}
+		if core.NewRequestMatcher(recorded).WithQuery().WithBody().WithoutHeader().Matches(targetRequest) {
+			r.used[i] = true
+			response = interaction.Response.toHTTPResponse(request)
+			return
+		}
+	}
+	err = fmt.Errorf("no recorded interaction matches the request: %s %s", target.Method, target.URL)
+	return
+}
+
+// Unused returns the interactions which are not replayed yet, it's useful to verify that all of them are used
+func (r *Replayer) Unused() (interactions []Interaction) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	for i, interaction := range r.Cassette.Interactions {
+		if i >= len(r.used) || !r.used[i] {
+			interactions = append(interactions, interaction)
+		}
+	}
+	return
+}
@@ -0,0 +1,96 @@
+package cassette
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/jenkins-zh/jenkins-client/pkg/core"
+)
+
+// Redacted replaces the secrets in the cassette
+const Redacted = core.Redacted
+
+// DefaultSensitiveHeaders are the headers which carry the credentials
+var DefaultSensitiveHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "Jenkins-Crumb"}
+
+// DefaultSensitiveKeys are the keywords of the sensitive query parameters, form fields, and JSON fields
+var DefaultSensitiveKeys = core.SensitiveKeys
+
+// Scrubber removes the secrets from the interactions
+type Scrubber struct {
+	// Headers are the names of the sensitive headers
+	Headers []string
+	// Keys are the keywords of the sensitive fields, a field is sensitive if its name contains one of them
+	Keys []string
+	// Secrets are the plain text which will be replaced everywhere, such as the API token
+	Secrets []string
+}
+
+// NewScrubber creates a Scrubber with the default sensitive headers and keys
+func NewScrubber(secrets ...string) *Scrubber {
+	return &Scrubber{
+		Headers: DefaultSensitiveHeaders,
+		Keys:    DefaultSensitiveKeys,
+		Secrets: secrets,
+	}
+}
+
+// ScrubRequest removes the secrets from the request
+func (s *Scrubber) ScrubRequest(request *Request) {
+	request.URL = s.scrubURL(request.URL)
+	request.Header = s.scrubHeader(request.Header)
+	request.Body = s.scrubBody(request.Body, request.Header.Get("Content-Type"))
+}
+
+// ScrubResponse removes the secrets from the response
+func (s *Scrubber) ScrubResponse(response *Response) {
+	response.Header = s.scrubHeader(response.Header)
+	response.Body = s.scrubBody(response.Body, response.Header.Get("Content-Type"))
+}
+
+func (s *Scrubber) scrubText(text string) string {
+	for _, secret := range s.Secrets {
+		if secret != "" {
+			text = strings.ReplaceAll(text, secret, Redacted)
+		}
+	}
+	return text
+}
+
+func (s *Scrubber) scrubHeader(header http.Header) (result http.Header) {
+	if header == nil {
+		return
+	}
+
+	result = http.Header{}
+	for key, values := range header {
+		sensitive := false
+		for _, name := range s.Headers {
+			sensitive = sensitive || strings.EqualFold(key, name)
+		}
+
+		for _, value := range values {
+			if sensitive {
+				value = Redacted
+			}
+			result.Add(key, s.scrubText(value))
+		}
+	}
+	return
+}
+
+func (s *Scrubber) scrubURL(rawURL string) string {
+	target, err := url.Parse(rawURL)
+	if err != nil {
+		return s.scrubText(rawURL)
+	}
+	return s.scrubText(core.RedactURL(target, s.Keys))
+}
+
+func (s *Scrubber) scrubBody(body, contentType string) string {
+	if body == "" {
+		return body
+	}
+	return s.scrubText(core.RedactBody(body, contentType, s.Keys))
+}
@@ -0,0 +1,276 @@
+// Package cli runs the Jenkins CLI commands over HTTP without jenkins-cli.jar, such as groovysh,
+// reload-job-config, and disconnect-node. It speaks the plain CLI protocol over two POST streams of /cli.
+package cli
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/jenkins-zh/jenkins-client/pkg/core"
+	"github.com/jenkins-zh/jenkins-client/pkg/util"
+)
+
+const (
+	// DefaultEncoding is the encoding of the standard streams
+	DefaultEncoding = "UTF-8"
+	// DefaultLocale is the locale of the messages which come from Jenkins
+	DefaultLocale = "en_US"
+	// DefaultKeepAlive is the interval of the keep-alive frames, it's the same as jenkins-cli.jar
+	DefaultKeepAlive = 3 * time.Second
+
+	// DuplexHeader is set by Jenkins if it serves the full-duplex HTTP transport
+	DuplexHeader = "Hudson-Duplex"
+)
+
+// ErrNotJenkins indicates that the server does not serve the full-duplex HTTP transport of the CLI
+var ErrNotJenkins = errors.New("there's no Jenkins CLI over HTTP")
+
+// Client runs the CLI commands over HTTP
+type Client struct {
+	core.JenkinsCore
+}
+
+// Options are the standard streams and the settings of a command
+type Options struct {
+	// Stdin is sent to the command, there is no standard input if it's nil.
+	// The sending stops once the command exits, but a blocking Read is not interrupted,
+	// so the caller should close a Stdin which might not reach EOF, such as a terminal or a pipe.
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+
+	// Encoding is DefaultEncoding if it's empty
+	Encoding string
+	// Locale is DefaultLocale if it's empty
+	Locale string
+	// KeepAlive is the interval of the keep-alive frames, it's DefaultKeepAlive if it's zero,
+	// and there is no keep-alive if it's negative
+	KeepAlive time.Duration
+}
+
+func (o *Options) setDefaults() {
+	if o.Stdout == nil {
+		o.Stdout = ioutil.Discard
+	}
+	if o.Stderr == nil {
+		o.Stderr = ioutil.Discard
+	}
+	if o.Encoding == "" {
+		o.Encoding = DefaultEncoding
+	}
+	if o.Locale == "" {
+		o.Locale = DefaultLocale
+	}
+	if o.KeepAlive == 0 {
+		o.KeepAlive = DefaultKeepAlive
+	}
+}
+
+// Run runs a command, such as Run(options, "disconnect-node", "agent-1"), then returns its exit code
+func (c *Client) Run(options Options, args ...string) (exitCode int, err error) {
+	return c.RunWithContext(context.Background(), options, args...)
+}
+
+// RunWithContext runs a command with a context, then returns its exit code.
+// The standard output and error are streamed into the writers while the command is running.
+func (c *Client) RunWithContext(ctx context.Context, options Options, args ...string) (exitCode int, err error) {
+	if len(args) == 0 {
+		err = errors.New("the command is required")
+		return
+	}
+	if c.DryRun {
+		err = fmt.Errorf("cannot run the CLI command %q in the dry-run mode", args[0])
+		return
+	}
+	options.setDefaults()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	// the streams cannot be buffered by the audit
+	ctx = core.WithStreaming(ctx)
+
+	client := c.GetClient()
+	// the command could take a long time, it's limited by the context instead
+	client.Timeout = 0
+
+	session := newSession()
+	var download *http.Response
+	if download, err = c.open(ctx, client, session, "download", http.NoBody); err != nil {
+		return
+	}
+	defer func() {
+		_ = download.Body.Close()
+	}()
+
+	reader, writer := io.Pipe()
+	uploadDone := make(chan struct{})
+	go func() {
+		defer close(uploadDone)
+		if upload, uploadErr := c.open(ctx, client, session, "upload", reader); uploadErr == nil {
+			_, _ = io.Copy(ioutil.Discard, upload.Body)
+			_ = upload.Body.Close()
+		} else {
+			_ = reader.CloseWithError(uploadErr)
+		}
+	}()
+	defer func() {
+		_ = writer.Close()
+		cancel()
+		<-uploadDone
+	}()
+
+	frameWriter := NewFrameWriter(writer)
+	if err = start(frameWriter, options, args); err != nil {
+		return
+	}
+	go sendStdin(ctx, frameWriter, options.Stdin)
+	if options.KeepAlive > 0 {
+		go keepAlive(ctx, frameWriter, options)
+	}
+	return receive(download.Body, options)
+}
+
+// open sends one side of the full-duplex HTTP transport
+func (c *Client) open(ctx context.Context, client *http.Client, session, side string,
+	body io.Reader) (response *http.Response, err error) {
+	var api string
+	if api, err = util.URLJoinAsString(c.URL, "/cli?remoting=false"); err != nil {
+		return
+	}
+
+	var request *http.Request
+	if request, err = http.NewRequestWithContext(ctx, http.MethodPost, api, body); err != nil {
+		return
+	}
+	request.Header.Set("Session", session)
+	request.Header.Set("Side", side)
+	request.Header.Set("Content-Type", "application/octet-stream")
+	if err = c.AuthHandle(request); err != nil {
+		return
+	}
+
+	if response, err = client.Do(request); err != nil || side != "download" {
+		return
+	}
+	if response.StatusCode != http.StatusOK {
+		data, _ := c.ReadBody(response)
+		_ = response.Body.Close()
+		err = core.NewHTTPError(response, data)
+		return
+	}
+	if response.Header.Get(DuplexHeader) == "" {
+		_ = response.Body.Close()
+		err = fmt.Errorf("%w at %s", ErrNotJenkins, c.URL)
+		return
+	}
+
+	// Jenkins sends a zero byte, then the client could see the HTTP headers
+	first := make([]byte, 1)
+	if _, err = io.ReadFull(response.Body, first); err == nil && first[0] != 0 {
+		err = fmt.Errorf("unexpected first byte of the CLI stream: %d", first[0])
+	}
+	if err != nil {
+		_ = response.Body.Close()
+	}
+	return
+}
+
+// start sends the arguments, the encoding, and the locale, then starts the command
+func start(frameWriter *FrameWriter, options Options, args []string) (err error) {
+	for _, arg := range args {
+		if err = frameWriter.SendString(OpArg, arg); err != nil {
+			return
+		}
+	}
+	if err = frameWriter.SendString(OpEncoding, options.Encoding); err == nil {
+		if err = frameWriter.SendString(OpLocale, options.Locale); err == nil {
+			err = frameWriter.Send(OpStart, nil)
+		}
+	}
+	return
+}
+
+// sendStdin sends the standard input chunk by chunk, then the end of it. It stops once the context is done.
+func sendStdin(ctx context.Context, frameWriter *FrameWriter, stdin io.Reader) {
+	if stdin != nil {
+		writer := frameWriter.Writer(OpStdin)
+		buf := make([]byte, 32*1024)
+		for ctx.Err() == nil {
+			n, err := stdin.Read(buf)
+			if ctx.Err() != nil {
+				return
+			}
+			if n > 0 {
+				if _, writeErr := writer.Write(buf[:n]); writeErr != nil {
+					return
+				}
+			}
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				return
+			}
+		}
+	}
+	_ = frameWriter.Send(OpEndStdin, nil)
+}
+
+// keepAlive sends the encoding periodically, then the proxies do not close the idle connection
+func keepAlive(ctx context.Context, frameWriter *FrameWriter, options Options) {
+	ticker := time.NewTicker(options.KeepAlive)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if frameWriter.SendString(OpEncoding, options.Encoding) != nil {
+				return
+			}
+		}
+	}
+}
+
+// receive copies the standard output and error until the command exits
+func receive(reader io.Reader, options Options) (exitCode int, err error) {
+	for {
+		var (
+			op      Op
+			payload []byte
+		)
+		if op, payload, err = ReadFrame(reader); err != nil {
+			if err == io.EOF {
+				err = errors.New("the CLI connection is closed before the command exits")
+			}
+			return
+		}
+
+		switch op {
+		case OpStdout:
+			_, err = options.Stdout.Write(payload)
+		case OpStderr:
+			_, err = options.Stderr.Write(payload)
+		case OpExit:
+			exitCode, err = DecodeInt(payload)
+			return
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// newSession returns a random UUID which pairs the two sides of the transport
+func newSession() string {
+	data := make([]byte, 16)
+	_, _ = rand.Read(data)
+	data[6] = data[6]&0x0F | 0x40
+	data[8] = data[8]&0x3F | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", data[0:4], data[4:6], data[6:8], data[8:10], data[10:])
+}
@@ -0,0 +1,216 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jenkins-zh/jenkins-client/pkg/cli"
+	"github.com/jenkins-zh/jenkins-client/pkg/core"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestModifiedUTF8(t *testing.T) {
+	tests := []struct {
+		text   string
+		expect []byte
+	}{{
+		text:   "",
+		expect: []byte{0, 0},
+	}, {
+		text:   "A\x00",
+		expect: []byte{0, 3, 'A', 0xC0, 0x80},
+	}, {
+		text:   "中",
+		expect: []byte{0, 3, 0xE4, 0xB8, 0xAD},
+	}, {
+		text:   "😀",
+		expect: []byte{0, 6, 0xED, 0xA0, 0xBD, 0xED, 0xB8, 0x80},
+	}}
+	for _, tt := range tests {
+		data, err := cli.EncodeUTF(tt.text)
+		assert.Nil(t, err)
+		assert.Equal(t, tt.expect, data, tt.text)
+
+		text, err := cli.DecodeUTF(data)
+		assert.Nil(t, err)
+		assert.Equal(t, tt.text, text)
+	}
+
+	_, err := cli.EncodeUTF(strings.Repeat("a", 0x10000))
+	assert.NotNil(t, err)
+	_, err = cli.DecodeUTF([]byte{0, 2, 'a'})
+	assert.NotNil(t, err)
+}
+
+func TestFrames(t *testing.T) {
+	buf := &bytes.Buffer{}
+	writer := cli.NewFrameWriter(buf)
+	assert.Nil(t, writer.SendString(cli.OpArg, "help"))
+	assert.Nil(t, writer.SendInt(cli.OpExit, -1))
+	assert.Equal(t, []byte{0, 0, 0, 6, byte(cli.OpArg), 0, 4, 'h', 'e', 'l', 'p', 0, 0, 0, 4, byte(cli.OpExit),
+		0xFF, 0xFF, 0xFF, 0xFF}, buf.Bytes())
+
+	op, payload, err := cli.ReadFrame(buf)
+	assert.Nil(t, err)
+	assert.Equal(t, cli.OpArg, op)
+	assert.Equal(t, []byte{0, 4, 'h', 'e', 'l', 'p'}, payload)
+	op, payload, err = cli.ReadFrame(buf)
+	assert.Nil(t, err)
+	assert.Equal(t, cli.OpExit, op)
+	code, err := cli.DecodeInt(payload)
+	assert.Nil(t, err)
+	assert.Equal(t, -1, code)
+
+	_, _, err = cli.ReadFrame(bytes.NewReader([]byte{0, 0, 0, 4, byte(cli.OpStdout), 'a'}))
+	assert.NotNil(t, err)
+}
+
+func newCLIServer(command cli.CommandFunc) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/crumbIssuer/api/json", http.NotFound)
+	standIn := cli.NewServer(command)
+	mux.HandleFunc("/cli", func(w http.ResponseWriter, r *http.Request) {
+		if userName, token, ok := r.BasicAuth(); !ok || userName != "admin" || token != "token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		standIn.ServeHTTP(w, r)
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestRun(t *testing.T) {
+	var received *cli.Command
+	server := newCLIServer(func(command *cli.Command) int {
+		received = command
+		data, _ := ioutil.ReadAll(command.Stdin)
+		_, _ = command.Stdout.Write(bytes.ToUpper(data))
+		_, _ = command.Stderr.Write([]byte("warning\n"))
+		return 3
+	})
+	defer server.Close()
+
+	client := &cli.Client{JenkinsCore: core.JenkinsCore{URL: server.URL, UserName: "admin", Token: "token"}}
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	exitCode, err := client.Run(cli.Options{
+		Stdin:  strings.NewReader("println 'hello'"),
+		Stdout: stdout,
+		Stderr: stderr,
+	}, "groovy", "=", "中文")
+	assert.Nil(t, err)
+	assert.Equal(t, 3, exitCode)
+	assert.Equal(t, "PRINTLN 'HELLO'", stdout.String())
+	assert.Equal(t, "warning\n", stderr.String())
+	if assert.NotNil(t, received) {
+		assert.Equal(t, []string{"groovy", "=", "中文"}, received.Args)
+		assert.Equal(t, cli.DefaultEncoding, received.Encoding)
+		assert.Equal(t, cli.DefaultLocale, received.Locale)
+	}
+
+	t.Run("audit", func(t *testing.T) {
+		sink := &core.MemoryAuditSink{}
+		client := &cli.Client{JenkinsCore: core.JenkinsCore{URL: server.URL, UserName: "admin", Token: "token",
+			AuditSink: sink}}
+		_, err := client.Run(cli.Options{Stdin: strings.NewReader("data")}, "groovy", "=")
+		assert.Nil(t, err)
+
+		entries := sink.Entries()
+		if assert.Equal(t, 2, len(entries), "both sides of the stream are recorded") {
+			for _, entry := range entries {
+				assert.Equal(t, http.MethodPost, entry.Method)
+				assert.Equal(t, server.URL+"/cli?remoting=false", entry.URL)
+				assert.Empty(t, entry.Body)
+			}
+		}
+	})
+
+	t.Run("unauthorized", func(t *testing.T) {
+		client := &cli.Client{JenkinsCore: core.JenkinsCore{URL: server.URL}}
+		_, err := client.Run(cli.Options{}, "who-am-i")
+		assert.True(t, errors.Is(err, core.ErrUnauthorized))
+	})
+
+	t.Run("not Jenkins", func(t *testing.T) {
+		other := httptest.NewServer(http.NotFoundHandler())
+		defer other.Close()
+		client := &cli.Client{JenkinsCore: core.JenkinsCore{URL: other.URL}}
+		_, err := client.Run(cli.Options{}, "help")
+		assert.True(t, core.IsNotFound(err))
+
+		proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/cli" {
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		defer proxy.Close()
+		client = &cli.Client{JenkinsCore: core.JenkinsCore{URL: proxy.URL}}
+		_, err = client.Run(cli.Options{}, "help")
+		assert.True(t, errors.Is(err, cli.ErrNotJenkins))
+	})
+}
+
+func TestRunCancel(t *testing.T) {
+	server := newCLIServer(func(command *cli.Command) int {
+		_, _ = ioutil.ReadAll(command.Stdin)
+		return 0
+	})
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	stdin, stdinWriter := io.Pipe()
+	defer func() {
+		_ = stdinWriter.Close()
+	}()
+	client := &cli.Client{JenkinsCore: core.JenkinsCore{URL: server.URL, UserName: "admin", Token: "token"}}
+	_, err := client.RunWithContext(ctx, cli.Options{Stdin: stdin, KeepAlive: 10 * time.Millisecond}, "groovysh")
+	assert.NotNil(t, err)
+	assert.Equal(t, context.DeadlineExceeded, ctx.Err())
+}
+
+// blockingReader blocks in Read until a chunk is sent or it's closed
+type blockingReader struct {
+	chunks chan []byte
+	reads  int32
+}
+
+func (r *blockingReader) Read(p []byte) (n int, err error) {
+	atomic.AddInt32(&r.reads, 1)
+	data, ok := <-r.chunks
+	if !ok {
+		return 0, io.EOF
+	}
+	return copy(p, data), nil
+}
+
+func TestRunStopsStdin(t *testing.T) {
+	server := newCLIServer(func(command *cli.Command) int {
+		return 0
+	})
+	defer server.Close()
+
+	stdin := &blockingReader{chunks: make(chan []byte)}
+	defer close(stdin.chunks)
+	client := &cli.Client{JenkinsCore: core.JenkinsCore{URL: server.URL, UserName: "admin", Token: "token"}}
+	exitCode, err := client.Run(cli.Options{Stdin: stdin}, "who-am-i")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, exitCode)
+
+	// the pending Read returns, then the standard input is not read anymore
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&stdin.reads) == 1
+	}, time.Second, time.Millisecond)
+	stdin.chunks <- []byte("ignored")
+	assert.Never(t, func() bool {
+		return atomic.LoadInt32(&stdin.reads) > 1
+	}, 100*time.Millisecond, 10*time.Millisecond)
+}
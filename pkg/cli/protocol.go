@@ -0,0 +1,207 @@
+package cli
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Op is the operation of a frame, the values are the ordinals of hudson.cli.PlainCLIProtocol.Op
+type Op byte
+
+const (
+	// OpArg is a UTF-8 command name or argument, from the client to the server
+	OpArg Op = iota
+	// OpLocale is the UTF-8 client locale
+	OpLocale
+	// OpEncoding is the UTF-8 client encoding, the client sends it periodically as a keep-alive as well
+	OpEncoding
+	// OpStart starts running the command
+	OpStart
+	// OpExit is the 32-bit exit code, from the server to the client
+	OpExit
+	// OpStdin is a chunk of the standard input
+	OpStdin
+	// OpEndStdin is the end of the standard input
+	OpEndStdin
+	// OpStdout is a chunk of the standard output
+	OpStdout
+	// OpStderr is a chunk of the standard error
+	OpStderr
+)
+
+// maxFrameSize limits the payload of a frame, it protects the reader from a broken stream
+const maxFrameSize = 16 << 20
+
+// FrameWriter writes the frames, it's safe for the concurrent use
+type FrameWriter struct {
+	writer io.Writer
+	// flush is called after each frame if it's not nil
+	flush func()
+	mutex sync.Mutex
+}
+
+// NewFrameWriter creates a FrameWriter
+func NewFrameWriter(writer io.Writer) *FrameWriter {
+	frameWriter := &FrameWriter{writer: writer}
+	if flusher, ok := writer.(interface{ Flush() }); ok {
+		frameWriter.flush = flusher.Flush
+	}
+	return frameWriter
+}
+
+// Send writes a frame, it consists of the length of the payload (32-bit big-endian), the op, and the payload
+func (w *FrameWriter) Send(op Op, payload []byte) (err error) {
+	frame := make([]byte, 5+len(payload))
+	binary.BigEndian.PutUint32(frame, uint32(len(payload)))
+	frame[4] = byte(op)
+	copy(frame[5:], payload)
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if _, err = w.writer.Write(frame); err == nil && w.flush != nil {
+		w.flush()
+	}
+	return
+}
+
+// SendString writes a frame with a text payload which is encoded like java.io.DataOutput#writeUTF
+func (w *FrameWriter) SendString(op Op, text string) (err error) {
+	var payload []byte
+	if payload, err = EncodeUTF(text); err == nil {
+		err = w.Send(op, payload)
+	}
+	return
+}
+
+// SendInt writes a frame with a 32-bit big-endian payload
+func (w *FrameWriter) SendInt(op Op, value int) error {
+	payload := make([]byte, 4)
+	binary.BigEndian.PutUint32(payload, uint32(int32(value)))
+	return w.Send(op, payload)
+}
+
+// Writer returns an io.Writer which sends every chunk as a frame of the op
+func (w *FrameWriter) Writer(op Op) io.Writer {
+	return &opWriter{frameWriter: w, op: op}
+}
+
+type opWriter struct {
+	frameWriter *FrameWriter
+	op          Op
+}
+
+// Write sends the data as a frame
+func (w *opWriter) Write(data []byte) (n int, err error) {
+	if len(data) == 0 {
+		return
+	}
+	if err = w.frameWriter.Send(w.op, data); err == nil {
+		n = len(data)
+	}
+	return
+}
+
+// ReadFrame reads a frame, the error is io.EOF if the stream ends between the frames
+func ReadFrame(reader io.Reader) (op Op, payload []byte, err error) {
+	header := make([]byte, 5)
+	if _, err = io.ReadFull(reader, header); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			err = errors.New("the stream ends in the middle of a frame")
+		}
+		return
+	}
+
+	length := binary.BigEndian.Uint32(header)
+	if length > maxFrameSize {
+		err = fmt.Errorf("the frame is too large: %d bytes", length)
+		return
+	}
+	op = Op(header[4])
+	payload = make([]byte, length)
+	if _, err = io.ReadFull(reader, payload); err == io.EOF || err == io.ErrUnexpectedEOF {
+		err = errors.New("the stream ends in the middle of a frame")
+	}
+	return
+}
+
+// DecodeInt decodes a 32-bit big-endian payload, such as the exit code
+func DecodeInt(payload []byte) (value int, err error) {
+	if len(payload) != 4 {
+		err = fmt.Errorf("expect a 4 bytes integer, got %d bytes", len(payload))
+		return
+	}
+	value = int(int32(binary.BigEndian.Uint32(payload)))
+	return
+}
+
+// EncodeUTF encodes the text like java.io.DataOutput#writeUTF, it's the modified UTF-8 with a 16-bit length
+func EncodeUTF(text string) (data []byte, err error) {
+	data = make([]byte, 2, 2+len(text))
+	for _, r := range text {
+		switch {
+		case r >= 0x01 && r <= 0x7F:
+			data = append(data, byte(r))
+		case r <= 0x7FF:
+			// the null character takes two bytes, then there is no zero byte in the text
+			data = append(data, byte(0xC0|r>>6), byte(0x80|r&0x3F))
+		case r <= 0xFFFF:
+			data = appendThreeBytes(data, r)
+		default:
+			high, low := utf16.EncodeRune(r)
+			data = appendThreeBytes(appendThreeBytes(data, high), low)
+		}
+	}
+
+	length := len(data) - 2
+	if length > 0xFFFF {
+		err = fmt.Errorf("the text is too long: %d bytes", length)
+		return
+	}
+	binary.BigEndian.PutUint16(data, uint16(length))
+	return
+}
+
+func appendThreeBytes(data []byte, r rune) []byte {
+	return append(data, byte(0xE0|r>>12), byte(0x80|(r>>6)&0x3F), byte(0x80|r&0x3F))
+}
+
+// DecodeUTF decodes the text which is encoded like java.io.DataOutput#writeUTF
+func DecodeUTF(data []byte) (text string, err error) {
+	if len(data) < 2 || int(binary.BigEndian.Uint16(data)) != len(data)-2 {
+		err = errors.New("invalid length of the modified UTF-8 text")
+		return
+	}
+	data = data[2:]
+
+	var units []uint16
+	for i := 0; i < len(data); {
+		b := data[i]
+		switch {
+		case b < 0x80:
+			units = append(units, uint16(b))
+			i++
+		case b&0xE0 == 0xC0 && i+1 < len(data):
+			units = append(units, uint16(b&0x1F)<<6|uint16(data[i+1]&0x3F))
+			i += 2
+		case b&0xF0 == 0xE0 && i+2 < len(data):
+			units = append(units, uint16(b&0x0F)<<12|uint16(data[i+1]&0x3F)<<6|uint16(data[i+2]&0x3F))
+			i += 3
+		default:
+			err = fmt.Errorf("invalid modified UTF-8 byte at %d", i)
+			return
+		}
+	}
+
+	runes := utf16.Decode(units)
+	buf := make([]byte, 0, len(runes))
+	for _, r := range runes {
+		buf = utf8.AppendRune(buf, r)
+	}
+	text = string(buf)
+	return
+}
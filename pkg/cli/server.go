@@ -0,0 +1,180 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Command is a CLI command which runs on the stand-in server
+type Command struct {
+	Args     []string
+	Encoding string
+	Locale   string
+	// Stdin is closed once the client sends the end of the standard input
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// CommandFunc runs a command on the stand-in server, then returns the exit code
+type CommandFunc func(command *Command) int
+
+// Server is a stand-in of the Jenkins CLI over HTTP for the tests, it speaks the same framing protocol.
+// Serve it on the path /cli of a test server.
+type Server struct {
+	// Command runs the commands
+	Command CommandFunc
+	// Timeout is how long the download side waits for the upload side, it's 15 seconds by default
+	Timeout time.Duration
+
+	mutex    sync.Mutex
+	sessions map[string]*serverSession
+}
+
+// serverSession pairs the download side and the upload side of a command
+type serverSession struct {
+	upload chan io.Reader
+	done   chan struct{}
+}
+
+// NewServer creates a stand-in server which runs the commands with the function
+func NewServer(command CommandFunc) *Server {
+	return &Server{Command: command, Timeout: 15 * time.Second, sessions: map[string]*serverSession{}}
+}
+
+func (s *Server) getSession(id string) *serverSession {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	session, ok := s.sessions[id]
+	if !ok {
+		session = &serverSession{upload: make(chan io.Reader, 1), done: make(chan struct{})}
+		s.sessions[id] = session
+	}
+	return session
+}
+
+func (s *Server) removeSession(id string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.sessions, id)
+}
+
+// ServeHTTP serves the download side and the upload side of the full-duplex HTTP transport
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	id := r.Header.Get("Session")
+	if r.Method != http.MethodPost || id == "" || r.URL.Query().Get("remoting") != "false" {
+		http.Error(w, "the plain CLI protocol is supported only", http.StatusBadRequest)
+		return
+	}
+
+	session := s.getSession(id)
+	w.Header().Set(DuplexHeader, "true")
+	switch r.Header.Get("Side") {
+	case "download":
+		defer s.removeSession(id)
+		defer close(session.done)
+		s.download(w, session)
+	case "upload":
+		session.upload <- r.Body
+		select {
+		case <-session.done:
+		case <-time.After(s.Timeout):
+		}
+	default:
+		http.Error(w, "unknown side", http.StatusBadRequest)
+	}
+}
+
+// download waits for the upload side, runs the command, then sends the exit code
+func (s *Server) download(w http.ResponseWriter, session *serverSession) {
+	flusher, _ := w.(http.Flusher)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte{0})
+	if flusher != nil {
+		flusher.Flush()
+	}
+
+	var upload io.Reader
+	select {
+	case upload = <-session.upload:
+	case <-time.After(s.Timeout):
+		return
+	}
+
+	frameWriter := NewFrameWriter(w)
+	command, err := readCommand(upload)
+	if err != nil {
+		_, _ = fmt.Fprintf(frameWriter.Writer(OpStderr), "%v\n", err)
+		_ = frameWriter.SendInt(OpExit, -1)
+		return
+	}
+
+	stdin, stdinWriter := io.Pipe()
+	command.Stdin = stdin
+	command.Stdout = frameWriter.Writer(OpStdout)
+	command.Stderr = frameWriter.Writer(OpStderr)
+	go pipeStdin(upload, stdinWriter)
+
+	exitCode := s.Command(command)
+	_ = stdin.Close()
+	_ = frameWriter.SendInt(OpExit, exitCode)
+}
+
+// readCommand reads the frames until the client starts the command
+func readCommand(upload io.Reader) (command *Command, err error) {
+	command = &Command{}
+	for {
+		var (
+			op      Op
+			payload []byte
+			text    string
+		)
+		if op, payload, err = ReadFrame(upload); err != nil {
+			return
+		}
+		if op == OpStart {
+			return
+		}
+		if op == OpArg || op == OpEncoding || op == OpLocale {
+			if text, err = DecodeUTF(payload); err != nil {
+				return
+			}
+		}
+
+		switch op {
+		case OpArg:
+			command.Args = append(command.Args, text)
+		case OpEncoding:
+			command.Encoding = text
+		case OpLocale:
+			command.Locale = text
+		default:
+			err = fmt.Errorf("unexpected op %d before the command starts", op)
+			return
+		}
+	}
+}
+
+// pipeStdin copies the standard input frames into the pipe of the command
+func pipeStdin(upload io.Reader, stdin *io.PipeWriter) {
+	for {
+		op, payload, err := ReadFrame(upload)
+		if err != nil {
+			_ = stdin.CloseWithError(errors.New("the standard input is broken"))
+			return
+		}
+
+		switch op {
+		case OpStdin:
+			if _, err = stdin.Write(payload); err != nil {
+				return
+			}
+		case OpEndStdin:
+			_ = stdin.Close()
+		}
+	}
+}
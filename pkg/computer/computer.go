@@ -1,6 +1,7 @@
 package computer
 
 import (
+	"context"
 	"encoding/xml"
 	"fmt"
 	"io/ioutil"
@@ -19,24 +20,39 @@ type Client struct {
 	core.JenkinsCore
 }
 
-// List get the computer list
-func (c *Client) List() (computers List, err error) {
-	err = c.RequestWithData(http.MethodGet, "/computer/api/json",
+// List get the computer list, the options could limit the fields of the computers
+func (c *Client) List(options ...core.QueryOption) (computers List, err error) {
+	return c.ListWithContext(context.Background(), options...)
+}
+
+// ListWithContext get the computer list with a context
+func (c *Client) ListWithContext(ctx context.Context, options ...core.QueryOption) (computers List, err error) {
+	err = c.RequestWithDataWithContext(ctx, http.MethodGet, core.WithQuery("/computer/api/json", options...),
 		nil, nil, 200, &computers)
 	return
 }
 
 // Launch starts up a agent
 func (c *Client) Launch(name string) (err error) {
+	return c.LaunchWithContext(context.Background(), name)
+}
+
+// LaunchWithContext starts up a agent with a context
+func (c *Client) LaunchWithContext(ctx context.Context, name string) (err error) {
 	api := fmt.Sprintf("/computer/%s/launchSlaveAgent", name)
-	_, err = c.RequestWithoutData(http.MethodPost, api, nil, nil, 200)
+	_, err = c.RequestWithoutDataWithContext(ctx, http.MethodPost, api, nil, nil, 200)
 	return
 }
 
 // Delete removes a agent from Jenkins
 func (c *Client) Delete(name string) (err error) {
+	return c.DeleteWithContext(context.Background(), name)
+}
+
+// DeleteWithContext removes a agent from Jenkins with a context
+func (c *Client) DeleteWithContext(ctx context.Context, name string) (err error) {
 	api := fmt.Sprintf("/computer/%s/doDelete", name)
-	_, err = c.RequestWithoutData(http.MethodPost, api, nil, nil, 200)
+	_, err = c.RequestWithoutDataWithContext(ctx, http.MethodPost, api, nil, nil, 200)
 	return
 }
 
@@ -47,9 +63,14 @@ type agentJNLP struct {
 
 // GetSecret returns the secret of an agent
 func (c *Client) GetSecret(name string) (secret string, err error) {
+	return c.GetSecretWithContext(context.Background(), name)
+}
+
+// GetSecretWithContext returns the secret of an agent with a context
+func (c *Client) GetSecretWithContext(ctx context.Context, name string) (secret string, err error) {
 	api := fmt.Sprintf("/computer/%s/slave-agent.jnlp", name)
 	var response *http.Response
-	if response, err = c.RequestWithResponse(http.MethodGet, api, nil, nil); err == nil {
+	if response, err = c.RequestWithResponseWithContext(ctx, http.MethodGet, api, nil, nil); err == nil {
 		if response.StatusCode == http.StatusOK {
 			var data []byte
 			if data, err = ioutil.ReadAll(response.Body); err == nil {
@@ -61,7 +82,8 @@ func (c *Client) GetSecret(name string) (secret string, err error) {
 				}
 			}
 		} else {
-			err = fmt.Errorf("unexpected status code: %d", response.StatusCode)
+			data, _ := ioutil.ReadAll(response.Body)
+			err = core.NewHTTPError(response, data)
 		}
 	}
 	return
@@ -69,12 +91,18 @@ func (c *Client) GetSecret(name string) (secret string, err error) {
 
 // GetLog fetch the log a computer
 func (c *Client) GetLog(name string) (log string, err error) {
+	return c.GetLogWithContext(context.Background(), name)
+}
+
+// GetLogWithContext fetch the log a computer with a context
+func (c *Client) GetLogWithContext(ctx context.Context, name string) (log string, err error) {
 	var response *http.Response
 	api := fmt.Sprintf("/computer/%s/logText/progressiveText", name)
-	if response, err = c.RequestWithResponse(http.MethodGet, api, nil, nil); err == nil {
+	if response, err = c.RequestWithResponseWithContext(ctx, http.MethodGet, api, nil, nil); err == nil {
 		statusCode := response.StatusCode
 		if statusCode != 200 {
-			err = fmt.Errorf("unexpected status code %d", statusCode)
+			data, _ := ioutil.ReadAll(response.Body)
+			err = core.NewHTTPError(response, data)
 			return
 		}
 
@@ -88,15 +116,20 @@ func (c *Client) GetLog(name string) (log string, err error) {
 
 // Create creates a computer by name
 func (c *Client) Create(name string) (err error) {
+	return c.CreateWithContext(context.Background(), name)
+}
+
+// CreateWithContext creates a computer by name with a context
+func (c *Client) CreateWithContext(ctx context.Context, name string) (err error) {
 	formData := url.Values{
 		"name": {name},
 		"mode": {"hudson.slaves.DumbSlave"},
 	}
 	payload := strings.NewReader(formData.Encode())
-	if _, err = c.RequestWithoutData(http.MethodPost, "/computer/createItem",
+	if _, err = c.RequestWithoutDataWithContext(ctx, http.MethodPost, "/computer/createItem",
 		map[string]string{httpdownloader.ContentType: httpdownloader.ApplicationForm}, payload, 200); err == nil {
 		payload = GetPayloadForCreateAgent(name)
-		_, err = c.RequestWithoutData(http.MethodPost, "/computer/doCreateItem",
+		_, err = c.RequestWithoutDataWithContext(ctx, http.MethodPost, "/computer/doCreateItem",
 			map[string]string{httpdownloader.ContentType: httpdownloader.ApplicationForm}, payload, 200)
 	}
 	return
@@ -0,0 +1,217 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/jenkins-zh/jenkins-client/pkg/core"
+	"github.com/jenkins-zh/jenkins-client/pkg/util"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultConfigFile is the config file of jcli
+const DefaultConfigFile = "~/.jenkins-cli.yaml"
+
+// The environment variables which override the config file or the current Jenkins server.
+// They are prefixed with JCLI_ because Jenkins injects JENKINS_URL and the like into every build.
+const (
+	// EnvConfigFile is the path of the config file
+	EnvConfigFile = "JCLI_CONFIG"
+	// EnvServer is the name of the selected Jenkins server
+	EnvServer = "JCLI_SERVER"
+	// EnvURL overrides the URL of the current Jenkins server
+	EnvURL = "JCLI_URL"
+	// EnvUserName overrides the username of the current Jenkins server
+	EnvUserName = "JCLI_USERNAME"
+	// EnvToken overrides the API token of the current Jenkins server
+	EnvToken = "JCLI_TOKEN"
+	// EnvProxy overrides the proxy of the current Jenkins server
+	EnvProxy = "JCLI_PROXY"
+	// EnvProxyAuth overrides the proxy auth of the current Jenkins server
+	EnvProxyAuth = "JCLI_PROXY_AUTH"
+	// EnvInsecureSkipVerify overrides the insecureSkipVerify of the current Jenkins server
+	EnvInsecureSkipVerify = "JCLI_INSECURE_SKIP_VERIFY"
+)
+
+// ErrServerNotFound indicates that there is no Jenkins server with the given name
+var ErrServerNotFound = errors.New("cannot find Jenkins server")
+
+// JenkinsServer holds the configuration of a Jenkins server
+type JenkinsServer struct {
+	Name               string `yaml:"name"`
+	URL                string `yaml:"url"`
+	UserName           string `yaml:"username"`
+	Token              string `yaml:"token"`
+	Proxy              string `yaml:"proxy,omitempty"`
+	ProxyAuth          string `yaml:"proxyAuth,omitempty"`
+	InsecureSkipVerify bool   `yaml:"insecureSkipVerify,omitempty"`
+	Description        string `yaml:"description,omitempty"`
+
+	// Extra keeps the unknown fields, then they will be written back as is
+	Extra map[string]interface{} `yaml:",inline"`
+}
+
+// Config is the config file of jcli, it contains the Jenkins servers
+type Config struct {
+	Current        string          `yaml:"current"`
+	Language       string          `yaml:"language,omitempty"`
+	JenkinsServers []JenkinsServer `yaml:"jenkins_servers"`
+
+	// Extra keeps the unknown fields, such as the hooks and mirrors of jcli
+	Extra map[string]interface{} `yaml:",inline"`
+}
+
+// GetConfigPath returns the path of the config file, it could be overridden by the environment variable
+func GetConfigPath(path string) string {
+	if path == "" {
+		path = util.GetEnvOrDefault(EnvConfigFile, DefaultConfigFile)
+	}
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, path[1:])
+		}
+	}
+	return path
+}
+
+// Load reads the config file, the default one is used if the path is empty
+func Load(path string) (config *Config, err error) {
+	var data []byte
+	if data, err = ioutil.ReadFile(GetConfigPath(path)); err != nil {
+		err = fmt.Errorf("cannot read the config file, error is %v", err)
+		return
+	}
+	return Parse(data)
+}
+
+// Parse parses the config from YAML data
+func Parse(data []byte) (config *Config, err error) {
+	config = &Config{}
+	if err = yaml.Unmarshal(data, config); err != nil {
+		config = nil
+		err = fmt.Errorf("cannot parse the config file, error is %v", err)
+	}
+	return
+}
+
+// Save writes the config into the file, the default one is used if the path is empty
+func (c *Config) Save(path string) (err error) {
+	path = GetConfigPath(path)
+
+	var data []byte
+	if data, err = yaml.Marshal(c); err != nil {
+		return
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0755); err == nil {
+		// the config file contains the tokens
+		err = ioutil.WriteFile(path, data, 0600)
+	}
+	return
+}
+
+// GetServer returns the Jenkins server by name
+func (c *Config) GetServer(name string) (server *JenkinsServer, err error) {
+	for i := range c.JenkinsServers {
+		if c.JenkinsServers[i].Name == name {
+			server = &c.JenkinsServers[i]
+			return
+		}
+	}
+	err = fmt.Errorf("%w: %s", ErrServerNotFound, name)
+	return
+}
+
+// GetCurrent returns the current Jenkins server, the environment variable takes precedence over the config
+func (c *Config) GetCurrent() (server *JenkinsServer, err error) {
+	return c.GetServer(util.GetEnvOrDefault(EnvServer, c.Current))
+}
+
+// SetCurrent changes the current Jenkins server
+func (c *Config) SetCurrent(name string) (err error) {
+	if _, err = c.GetServer(name); err == nil {
+		c.Current = name
+	}
+	return
+}
+
+// SetServer adds a Jenkins server, or replaces the existing one which has the same name
+func (c *Config) SetServer(server JenkinsServer) {
+	if existing, err := c.GetServer(server.Name); err == nil {
+		*existing = server
+		return
+	}
+	c.JenkinsServers = append(c.JenkinsServers, server)
+}
+
+// RemoveServer removes a Jenkins server by name
+func (c *Config) RemoveServer(name string) (err error) {
+	for i := range c.JenkinsServers {
+		if c.JenkinsServers[i].Name == name {
+			c.JenkinsServers = append(c.JenkinsServers[:i], c.JenkinsServers[i+1:]...)
+			if c.Current == name {
+				c.Current = ""
+			}
+			return
+		}
+	}
+	err = fmt.Errorf("%w: %s", ErrServerNotFound, name)
+	return
+}
+
+// WithEnv returns a copy of the Jenkins server which is overridden by the environment variables
+func (s JenkinsServer) WithEnv() JenkinsServer {
+	s.URL = util.GetEnvOrDefault(EnvURL, s.URL)
+	s.UserName = util.GetEnvOrDefault(EnvUserName, s.UserName)
+	s.Token = util.GetEnvOrDefault(EnvToken, s.Token)
+	s.Proxy = util.GetEnvOrDefault(EnvProxy, s.Proxy)
+	s.ProxyAuth = util.GetEnvOrDefault(EnvProxyAuth, s.ProxyAuth)
+	if insecure, err := strconv.ParseBool(util.GetEnvOrDefault(EnvInsecureSkipVerify,
+		strconv.FormatBool(s.InsecureSkipVerify))); err == nil {
+		s.InsecureSkipVerify = insecure
+	}
+	return s
+}
+
+// NewJenkinsCore creates a JenkinsCore from the Jenkins server
+func (s JenkinsServer) NewJenkinsCore() *core.JenkinsCore {
+	return &core.JenkinsCore{
+		URL:                s.URL,
+		UserName:           s.UserName,
+		Token:              s.Token,
+		Proxy:              s.Proxy,
+		ProxyAuth:          s.ProxyAuth,
+		InsecureSkipVerify: s.InsecureSkipVerify,
+	}
+}
+
+// NewJenkinsCore loads the config file, then creates a JenkinsCore from the Jenkins server.
+// The current Jenkins server is used with the environment variables applied if the name is empty,
+// a named Jenkins server is never overridden by the environment variables.
+func NewJenkinsCore(path, name string) (jenkinsCore *core.JenkinsCore, err error) {
+	var (
+		config *Config
+		server *JenkinsServer
+	)
+	if config, err = Load(path); err != nil {
+		return
+	}
+
+	if name == "" {
+		if server, err = config.GetCurrent(); err == nil {
+			overridden := server.WithEnv()
+			server = &overridden
+		}
+	} else {
+		server, err = config.GetServer(name)
+	}
+	if err == nil {
+		jenkinsCore = server.NewJenkinsCore()
+		jenkinsCore.Language = config.Language
+	}
+	return
+}
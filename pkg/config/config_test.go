@@ -0,0 +1,182 @@
+package config
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const sampleConfig = `current: dev
+language: zh-CN
+jenkins_servers:
+- name: dev
+  url: http://localhost:8080
+  username: admin
+  token: token
+  insecureSkipVerify: true
+  description: local Jenkins
+- name: prod
+  url: https://jenkins.example.com
+  username: ops
+  token: prod-token
+  proxy: http://proxy:3128
+  proxyAuth: user:pass
+  unknownField: keep
+mirrors:
+- name: tsinghua
+  url: https://mirrors.tuna.tsinghua.edu.cn/jenkins/
+`
+
+func writeSampleConfig(t *testing.T) (path string) {
+	path = filepath.Join(t.TempDir(), ".jenkins-cli.yaml")
+	assert.Nil(t, ioutil.WriteFile(path, []byte(sampleConfig), 0600))
+	return
+}
+
+func TestLoad(t *testing.T) {
+	config, err := Load(writeSampleConfig(t))
+	assert.Nil(t, err)
+	assert.Equal(t, "dev", config.Current)
+	assert.Equal(t, "zh-CN", config.Language)
+	assert.Equal(t, 2, len(config.JenkinsServers))
+
+	server, err := config.GetCurrent()
+	assert.Nil(t, err)
+	assert.Equal(t, "http://localhost:8080", server.URL)
+	assert.True(t, server.InsecureSkipVerify)
+
+	server, err = config.GetServer("prod")
+	assert.Nil(t, err)
+	assert.Equal(t, "user:pass", server.ProxyAuth)
+
+	_, err = config.GetServer("fake")
+	assert.True(t, errors.Is(err, ErrServerNotFound))
+
+	_, err = Load(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.NotNil(t, err)
+
+	_, err = Parse([]byte("jenkins_servers: fake"))
+	assert.NotNil(t, err)
+}
+
+func TestSave(t *testing.T) {
+	path := writeSampleConfig(t)
+	config, err := Load(path)
+	assert.Nil(t, err)
+
+	config.SetServer(JenkinsServer{Name: "dev", URL: "http://dev:8080"})
+	config.SetServer(JenkinsServer{Name: "test", URL: "http://test:8080"})
+	assert.Nil(t, config.SetCurrent("test"))
+	assert.True(t, errors.Is(config.SetCurrent("fake"), ErrServerNotFound))
+	assert.Nil(t, config.RemoveServer("test"))
+	assert.Equal(t, "", config.Current)
+	assert.True(t, errors.Is(config.RemoveServer("fake"), ErrServerNotFound))
+
+	target := filepath.Join(t.TempDir(), "sub", "config.yaml")
+	assert.Nil(t, config.Save(target))
+
+	saved, err := Load(target)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(saved.JenkinsServers))
+	assert.Equal(t, "http://dev:8080", saved.JenkinsServers[0].URL)
+	// the unknown fields are kept
+	assert.Equal(t, "keep", saved.JenkinsServers[1].Extra["unknownField"])
+	assert.NotNil(t, saved.Extra["mirrors"])
+}
+
+func TestNewJenkinsCore(t *testing.T) {
+	path := writeSampleConfig(t)
+
+	tests := []struct {
+		name         string
+		server       string
+		env          map[string]string
+		wantURL      string
+		wantUser     string
+		wantToken    string
+		wantProxy    string
+		wantInsecure bool
+		wantErr      bool
+	}{{
+		name:         "the current server",
+		wantURL:      "http://localhost:8080",
+		wantUser:     "admin",
+		wantToken:    "token",
+		wantInsecure: true,
+	}, {
+		name:      "a named server",
+		server:    "prod",
+		wantURL:   "https://jenkins.example.com",
+		wantUser:  "ops",
+		wantToken: "prod-token",
+		wantProxy: "http://proxy:3128",
+	}, {
+		name:      "select the server by the environment variable",
+		env:       map[string]string{EnvServer: "prod", EnvToken: "env-token"},
+		wantURL:   "https://jenkins.example.com",
+		wantUser:  "ops",
+		wantToken: "env-token",
+		wantProxy: "http://proxy:3128",
+	}, {
+		name:      "override the current server by the environment variables",
+		env:       map[string]string{EnvURL: "http://env:8080", EnvUserName: "env", EnvInsecureSkipVerify: "false"},
+		wantURL:   "http://env:8080",
+		wantUser:  "env",
+		wantToken: "token",
+	}, {
+		name:      "a named server is not overridden by the environment variables",
+		server:    "prod",
+		env:       map[string]string{"JENKINS_URL": "http://agent:8080", EnvURL: "http://env:8080", EnvToken: "env-token"},
+		wantURL:   "https://jenkins.example.com",
+		wantUser:  "ops",
+		wantToken: "prod-token",
+		wantProxy: "http://proxy:3128",
+	}, {
+		name:         "JENKINS_URL of a build does not redirect the current server",
+		env:          map[string]string{"JENKINS_URL": "http://agent:8080"},
+		wantURL:      "http://localhost:8080",
+		wantUser:     "admin",
+		wantToken:    "token",
+		wantInsecure: true,
+	}, {
+		name:    "not found server",
+		server:  "fake",
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// avoid the side effects of the environment
+			for _, k := range []string{EnvServer, EnvURL, EnvUserName, EnvToken, EnvProxy, EnvProxyAuth,
+				EnvInsecureSkipVerify, "JENKINS_URL"} {
+				t.Setenv(k, "")
+				_ = os.Unsetenv(k)
+			}
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			jenkinsCore, err := NewJenkinsCore(path, tt.server)
+			if tt.wantErr {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.wantURL, jenkinsCore.URL)
+			assert.Equal(t, tt.wantUser, jenkinsCore.UserName)
+			assert.Equal(t, tt.wantToken, jenkinsCore.Token)
+			assert.Equal(t, tt.wantProxy, jenkinsCore.Proxy)
+			assert.Equal(t, tt.wantInsecure, jenkinsCore.InsecureSkipVerify)
+		})
+	}
+}
+
+func TestGetConfigPath(t *testing.T) {
+	assert.Equal(t, "/fake/config.yaml", GetConfigPath("/fake/config.yaml"))
+
+	t.Setenv(EnvConfigFile, "/env/config.yaml")
+	assert.Equal(t, "/env/config.yaml", GetConfigPath(""))
+}
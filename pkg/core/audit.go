@@ -0,0 +1,220 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"moul.io/http2curl"
+)
+
+// maxAuditBody is the max length of the request body in an audit entry
+const maxAuditBody = 4096
+
+// SensitiveHeaders are the headers which are redacted in the audit entries
+var SensitiveHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Jenkins-Crumb"}
+
+// AuditEntry describes a mutating request
+type AuditEntry struct {
+	Time   time.Time `json:"time"`
+	Method string    `json:"method"`
+	URL    string    `json:"url"`
+	// Body is the request body with the secrets redacted
+	Body string `json:"body,omitempty"`
+	// Curl is the equivalent curl command with the secrets redacted
+	Curl string `json:"curl"`
+	// DryRun is true if the request was not sent
+	DryRun     bool   `json:"dryRun"`
+	StatusCode int    `json:"statusCode,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// AuditSink receives the audit entries of the mutating requests
+type AuditSink interface {
+	Audit(ctx context.Context, entry AuditEntry)
+}
+
+// MemoryAuditSink keeps the audit entries in memory, it's useful to review a plan of the dry-run
+type MemoryAuditSink struct {
+	mutex   sync.Mutex
+	entries []AuditEntry
+}
+
+// Audit keeps the entry
+func (s *MemoryAuditSink) Audit(_ context.Context, entry AuditEntry) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.entries = append(s.entries, entry)
+}
+
+// Entries returns the audit entries
+func (s *MemoryAuditSink) Entries() []AuditEntry {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]AuditEntry{}, s.entries...)
+}
+
+// Plan returns the curl commands of the entries, one command per line
+func (s *MemoryAuditSink) Plan() string {
+	buf := &strings.Builder{}
+	for _, entry := range s.Entries() {
+		buf.WriteString(entry.Curl + "\n")
+	}
+	return buf.String()
+}
+
+// JSONAuditSink writes the audit entries as JSON lines
+type JSONAuditSink struct {
+	Writer io.Writer
+
+	mutex sync.Mutex
+}
+
+// Audit writes the entry as a JSON line
+func (s *JSONAuditSink) Audit(_ context.Context, entry AuditEntry) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if err := json.NewEncoder(s.Writer).Encode(entry); err != nil {
+		Logger.Error("cannot write the audit entry", zap.Error(err))
+	}
+}
+
+type readOnlyKey struct{}
+
+// WithReadOnly marks the requests with this context as read-only, then they are sent even in the dry-run mode.
+// It's for the POST requests which do not change anything, such as exporting the configuration.
+func WithReadOnly(ctx context.Context) context.Context {
+	return context.WithValue(ctx, readOnlyKey{}, true)
+}
+
+type streamingKey struct{}
+
+// WithStreaming marks the requests with this context as streaming, then the audit does not buffer their bodies.
+// They are still recorded with the method and the URL, but without the body.
+func WithStreaming(ctx context.Context) context.Context {
+	return context.WithValue(ctx, streamingKey{}, true)
+}
+
+type expectedStatusKey struct{}
+
+// withExpectedStatus tells the dry-run which status code the caller expects
+func withExpectedStatus(ctx context.Context, code int) context.Context {
+	return context.WithValue(ctx, expectedStatusKey{}, code)
+}
+
+// isMutating returns true if the request might change the state of Jenkins
+func isMutating(request *http.Request) bool {
+	switch request.Method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		readOnly, _ := request.Context().Value(readOnlyKey{}).(bool)
+		return !readOnly
+	}
+	return false
+}
+
+// auditRoundTripper records the mutating requests, and does not send them in the dry-run mode
+type auditRoundTripper struct {
+	next   http.RoundTripper
+	sink   AuditSink
+	dryRun bool
+	client *JenkinsCore
+}
+
+func (j *JenkinsCore) withAudit(roundTripper http.RoundTripper) http.RoundTripper {
+	if j.AuditSink == nil && !j.DryRun {
+		return roundTripper
+	}
+	return &auditRoundTripper{next: roundTripper, sink: j.AuditSink, dryRun: j.DryRun, client: j}
+}
+
+// RoundTrip records the mutating request, then sends it or responds a synthetic success in the dry-run mode
+func (a *auditRoundTripper) RoundTrip(request *http.Request) (response *http.Response, err error) {
+	if !isMutating(request) {
+		return a.next.RoundTrip(request)
+	}
+
+	var body []byte
+	if streaming, _ := request.Context().Value(streamingKey{}).(bool); !streaming && request.Body != nil {
+		if body, err = ioutil.ReadAll(request.Body); err != nil {
+			return
+		}
+		_ = request.Body.Close()
+		request.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+	entry := newAuditEntry(request, body)
+	entry.DryRun = a.dryRun
+
+	if a.dryRun {
+		code := http.StatusOK
+		if expected, ok := request.Context().Value(expectedStatusKey{}).(int); ok && expected >= 200 && expected < 300 {
+			code = expected
+		}
+		entry.StatusCode = code
+		a.audit(request.Context(), entry)
+		response = &http.Response{
+			Status:        fmt.Sprintf("%d %s", code, http.StatusText(code)),
+			StatusCode:    code,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        http.Header{"Content-Type": {"application/json"}},
+			Body:          ioutil.NopCloser(strings.NewReader("{}")),
+			ContentLength: 2,
+			Request:       request,
+		}
+		return
+	}
+
+	if response, err = a.next.RoundTrip(request); err != nil {
+		entry.Error = err.Error()
+	} else {
+		entry.StatusCode = response.StatusCode
+	}
+	a.audit(request.Context(), entry)
+	return
+}
+
+func (a *auditRoundTripper) audit(ctx context.Context, entry AuditEntry) {
+	if a.sink != nil {
+		a.sink.Audit(ctx, entry)
+	} else {
+		a.client.loggerOf(ctx).Info("dry-run", zap.String("method", entry.Method), zap.String("URL", entry.URL),
+			zap.String("curl", entry.Curl))
+	}
+}
+
+// newAuditEntry creates an audit entry from the request, the secrets are redacted
+func newAuditEntry(request *http.Request, body []byte) (entry AuditEntry) {
+	entry = AuditEntry{
+		Time:   time.Now(),
+		Method: request.Method,
+		URL:    RedactURL(request.URL, SensitiveKeys),
+		Body:   RedactBody(string(body), request.Header.Get("Content-Type"), SensitiveKeys),
+	}
+
+	curlRequest := request.Clone(context.Background())
+	curlRequest.URL, _ = url.Parse(entry.URL)
+	curlRequest.Body = ioutil.NopCloser(strings.NewReader(entry.Body))
+	for _, name := range SensitiveHeaders {
+		if curlRequest.Header.Get(name) != "" {
+			curlRequest.Header.Set(name, Redacted)
+		}
+	}
+	if command, err := http2curl.GetCurlCommand(curlRequest); err == nil {
+		entry.Curl = command.String()
+	}
+
+	if len(entry.Body) > maxAuditBody {
+		entry.Body = truncateText(entry.Body, maxAuditBody) + "...(truncated)"
+	}
+	return
+}
@@ -0,0 +1,121 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newAuditServer(mutations *int64) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/crumbIssuer/api/json":
+			_, _ = w.Write([]byte(`{"crumbRequestField":"Jenkins-Crumb","crumb":"crumb"}`))
+		case "/api/json":
+			_, _ = w.Write([]byte(`{"nodeName":"master"}`))
+		case "/pipeline-model-converter/toJson":
+			atomic.AddInt64(mutations, 1)
+			_, _ = w.Write([]byte(`{"status":"ok","data":{"result":"success","json":{"pipeline":{}}}}`))
+		default:
+			atomic.AddInt64(mutations, 1)
+			w.WriteHeader(http.StatusCreated)
+		}
+	}))
+}
+
+func TestDryRun(t *testing.T) {
+	var mutations int64
+	server := newAuditServer(&mutations)
+	defer server.Close()
+
+	sink := &MemoryAuditSink{}
+	jenkinsCore := &JenkinsCore{URL: server.URL, UserName: "admin", Token: "api-token", DryRun: true, AuditSink: sink}
+
+	// the GET requests are sent as usual
+	statusCode, data, err := jenkinsCore.Request(http.MethodGet, "/api/json", nil, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, statusCode)
+	assert.Equal(t, `{"nodeName":"master"}`, string(data))
+
+	// the synthetic response has the expected status code
+	_, err = jenkinsCore.RequestWithoutData(http.MethodPost, "/job/fake/build", nil, nil, http.StatusCreated)
+	assert.Nil(t, err)
+
+	err = NewRequest("/credentials/store/system/domain/_/createCredentials", jenkinsCore).AsPostFormRequest().
+		WithValues(url.Values{"json": {`{"credentials": {"id": "fake", "password": "secret-value"}}`}}).Do()
+	assert.Nil(t, err)
+
+	statusCode, _, err = jenkinsCore.Request(http.MethodDelete, "/job/fake?token=abc", nil, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, statusCode)
+	assert.Equal(t, int64(0), atomic.LoadInt64(&mutations))
+
+	// the read-only requests are sent
+	err = NewRequest("/configuration-as-code/export", jenkinsCore).WithPostMethod().AsReadOnly().
+		AcceptStatusCode(http.StatusCreated).Do()
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), atomic.LoadInt64(&mutations))
+
+	// the converters of the pipeline are sent
+	client := &Client{JenkinsCore: *jenkinsCore}
+	result, err := client.ToJSON("pipeline {}")
+	assert.Nil(t, err)
+	assert.Equal(t, "success", result.GetStatus())
+	assert.Equal(t, int64(2), atomic.LoadInt64(&mutations))
+
+	entries := sink.Entries()
+	if assert.Equal(t, 3, len(entries)) {
+		assert.True(t, entries[0].DryRun)
+		assert.Equal(t, http.MethodPost, entries[0].Method)
+		assert.Equal(t, http.StatusCreated, entries[0].StatusCode)
+		assert.Contains(t, entries[0].Curl, "/job/fake/build")
+
+		values, err := url.ParseQuery(entries[1].Body)
+		assert.Nil(t, err)
+		assert.Equal(t, `{"credentials": {"id": "fake", "password": "[REDACTED]"}}`, values.Get("json"))
+		assert.Equal(t, server.URL+"/job/fake?token=%5BREDACTED%5D", entries[2].URL)
+	}
+	plan := sink.Plan()
+	assert.Equal(t, 3, strings.Count(plan, "curl "))
+	assert.NotContains(t, plan, "api-token")
+	assert.NotContains(t, plan, "secret-value")
+}
+
+func TestAuditSink(t *testing.T) {
+	var mutations int64
+	server := newAuditServer(&mutations)
+	defer server.Close()
+
+	buf := &bytes.Buffer{}
+	jenkinsCore := &JenkinsCore{URL: server.URL, AuditSink: &JSONAuditSink{Writer: buf}}
+	_, err := jenkinsCore.RequestWithoutDataWithContext(context.Background(), http.MethodPost, "/job/fake/build",
+		nil, nil, http.StatusCreated)
+	assert.Nil(t, err)
+	_, _, err = jenkinsCore.Request(http.MethodGet, "/api/json", nil, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), atomic.LoadInt64(&mutations))
+
+	entry := AuditEntry{}
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.False(t, entry.DryRun)
+	assert.Equal(t, http.StatusCreated, entry.StatusCode)
+	assert.Equal(t, server.URL+"/job/fake/build", entry.URL)
+	assert.Contains(t, entry.Curl, "Jenkins-Crumb: [REDACTED]")
+}
+
+func TestAuditTruncatedBody(t *testing.T) {
+	request, _ := http.NewRequest(http.MethodPost, "http://localhost/job/fake/description", nil)
+	body := strings.Repeat("a", maxAuditBody-1) + "中文"
+	entry := newAuditEntry(request, []byte(body))
+	assert.True(t, utf8.ValidString(entry.Body))
+	assert.Equal(t, strings.Repeat("a", maxAuditBody-1)+"...(truncated)", entry.Body)
+}
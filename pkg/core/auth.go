@@ -0,0 +1,286 @@
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Authenticator authenticates the requests to Jenkins
+type Authenticator interface {
+	Authenticate(request *http.Request) error
+}
+
+// TLSAuthenticator authenticates the client via the TLS connection, such as the client certificates.
+// The transport is only shared with the other clients if the implementation is a TLSIdentity as well.
+type TLSAuthenticator interface {
+	ConfigureTLS(config *tls.Config) error
+}
+
+// TLSIdentity identifies the TLS material of a TLSAuthenticator, such as the paths of the certificates.
+// The clients whose authenticators have the same identity share the transport.
+type TLSIdentity interface {
+	TLSIdentity() string
+}
+
+// BasicAuth authenticates the requests with the username and password or API token
+type BasicAuth struct {
+	UserName string
+	Token    string
+}
+
+// Authenticate sets the basic auth header if both the username and token are not empty
+func (a BasicAuth) Authenticate(request *http.Request) error {
+	if a.UserName != "" && a.Token != "" {
+		request.SetBasicAuth(a.UserName, a.Token)
+	}
+	return nil
+}
+
+// BearerToken authenticates the requests with a bearer token, such as the Jenkins behind an OIDC proxy
+type BearerToken struct {
+	Token string
+}
+
+// Authenticate sets the bearer token header
+func (a BearerToken) Authenticate(request *http.Request) error {
+	if a.Token != "" {
+		request.Header.Set("Authorization", "Bearer "+a.Token)
+	}
+	return nil
+}
+
+// getAuthenticator returns the authenticator, the basic auth is the default one
+func (j *JenkinsCore) getAuthenticator() Authenticator {
+	if j.Authenticator != nil {
+		return j.Authenticator
+	}
+	return BasicAuth{UserName: j.UserName, Token: j.Token}
+}
+
+// watchedFile reads a file again once it's changed
+type watchedFile struct {
+	path    string
+	modTime time.Time
+	size    int64
+	data    []byte
+}
+
+// read returns the content of the file, changed is true if the file was read from the disk
+func (f *watchedFile) read() (data []byte, changed bool, err error) {
+	path := expandHome(f.path)
+
+	var info os.FileInfo
+	if info, err = os.Stat(path); err != nil {
+		return
+	}
+	if f.data == nil || !info.ModTime().Equal(f.modTime) || info.Size() != f.size {
+		if f.data, err = ioutil.ReadFile(path); err != nil {
+			return
+		}
+		f.modTime, f.size, changed = info.ModTime(), info.Size(), true
+	}
+	data = f.data
+	return
+}
+
+func expandHome(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, path[1:])
+		}
+	}
+	return path
+}
+
+// TokenFile authenticates the requests with the token which is read from a file.
+// The file is read again once it's changed, so the token could be rotated by another process.
+type TokenFile struct {
+	// UserName is optional, the token is sent as a bearer token if it's empty, or as basic auth
+	UserName string
+
+	mutex sync.Mutex
+	file  watchedFile
+}
+
+// NewTokenFile creates a TokenFile authenticator
+func NewTokenFile(path, userName string) *TokenFile {
+	return &TokenFile{UserName: userName, file: watchedFile{path: path}}
+}
+
+// Authenticate sets the token which is read from the file
+func (a *TokenFile) Authenticate(request *http.Request) (err error) {
+	a.mutex.Lock()
+	data, _, err := a.file.read()
+	a.mutex.Unlock()
+	if err != nil {
+		err = fmt.Errorf("cannot read the token file, error is %v", err)
+		return
+	}
+
+	token := strings.TrimSpace(string(data))
+	if a.UserName != "" {
+		return BasicAuth{UserName: a.UserName, Token: token}.Authenticate(request)
+	}
+	return BearerToken{Token: token}.Authenticate(request)
+}
+
+// Netrc authenticates the requests with the login and password of the matched machine in a netrc file
+type Netrc struct {
+	mutex    sync.Mutex
+	file     watchedFile
+	machines map[string]BasicAuth
+}
+
+// NewNetrc creates a Netrc authenticator, the default path is ~/.netrc
+func NewNetrc(path string) *Netrc {
+	if path == "" {
+		path = "~/.netrc"
+	}
+	return &Netrc{file: watchedFile{path: path}}
+}
+
+// Authenticate sets the basic auth of the machine which matches the host of the request
+func (a *Netrc) Authenticate(request *http.Request) (err error) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	var (
+		data    []byte
+		changed bool
+	)
+	if data, changed, err = a.file.read(); err != nil {
+		err = fmt.Errorf("cannot read the netrc file, error is %v", err)
+		return
+	}
+	if changed {
+		a.machines = parseNetrc(data)
+	}
+
+	auth, ok := a.machines[request.URL.Hostname()]
+	if !ok {
+		// the empty name represents the default entry
+		auth = a.machines[""]
+	}
+	return auth.Authenticate(request)
+}
+
+// parseNetrc parses the netrc file, the key of the default entry is an empty string
+func parseNetrc(data []byte) (machines map[string]BasicAuth) {
+	machines = map[string]BasicAuth{}
+
+	var (
+		machine string
+		current *BasicAuth
+		inMacro bool
+	)
+	save := func() {
+		if current != nil {
+			machines[machine] = *current
+		}
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if inMacro {
+			// a macro definition ends with an empty line
+			inMacro = line != ""
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		for i := 0; i < len(fields); i++ {
+			var value string
+			if i+1 < len(fields) {
+				value = fields[i+1]
+			}
+			switch fields[i] {
+			case "machine":
+				save()
+				machine, current = value, &BasicAuth{}
+				i++
+			case "default":
+				save()
+				machine, current = "", &BasicAuth{}
+			case "login":
+				if current != nil {
+					current.UserName = value
+				}
+				i++
+			case "password":
+				if current != nil {
+					current.Token = value
+				}
+				i++
+			case "account":
+				i++
+			case "macdef":
+				inMacro = true
+				i = len(fields)
+			}
+		}
+	}
+	save()
+	return
+}
+
+// ClientCertificate authenticates the client with the certificates which are loaded from the PEM files
+type ClientCertificate struct {
+	CertFile string
+	KeyFile  string
+	// CAFile is optional, it's the certificate authority of the Jenkins server
+	CAFile string
+}
+
+// NewClientCertificate creates a ClientCertificate authenticator
+func NewClientCertificate(certFile, keyFile, caFile string) *ClientCertificate {
+	return &ClientCertificate{CertFile: certFile, KeyFile: keyFile, CAFile: caFile}
+}
+
+// Authenticate does nothing, the client is authenticated by the TLS connection
+func (a *ClientCertificate) Authenticate(*http.Request) error {
+	return nil
+}
+
+// TLSIdentity returns the paths of the certificates, the certificates are loaded once for the same paths
+func (a *ClientCertificate) TLSIdentity() string {
+	return fmt.Sprintf("client-certificate:%s:%s:%s", expandHome(a.CertFile), expandHome(a.KeyFile),
+		expandHome(a.CAFile))
+}
+
+// ConfigureTLS loads the certificates into the TLS config
+func (a *ClientCertificate) ConfigureTLS(config *tls.Config) (err error) {
+	var cert tls.Certificate
+	if cert, err = tls.LoadX509KeyPair(expandHome(a.CertFile), expandHome(a.KeyFile)); err != nil {
+		err = fmt.Errorf("cannot load the client certificate, error is %v", err)
+		return
+	}
+	config.Certificates = append(config.Certificates, cert)
+
+	if a.CAFile != "" {
+		var data []byte
+		if data, err = ioutil.ReadFile(expandHome(a.CAFile)); err != nil {
+			return
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			err = fmt.Errorf("no valid certificate in %s", a.CAFile)
+			return
+		}
+		config.RootCAs = pool
+	}
+	return
+}
@@ -0,0 +1,173 @@
+package core
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthenticator(t *testing.T) {
+	tests := []struct {
+		name          string
+		authenticator Authenticator
+		expect        string
+	}{{
+		name:          "basic auth",
+		authenticator: BasicAuth{UserName: "admin", Token: "token"},
+		expect:        "Basic YWRtaW46dG9rZW4=",
+	}, {
+		name:          "basic auth without token",
+		authenticator: BasicAuth{UserName: "admin"},
+	}, {
+		name:          "bearer token",
+		authenticator: BearerToken{Token: "token"},
+		expect:        "Bearer token",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request, _ := http.NewRequest(http.MethodGet, "http://localhost", nil)
+			assert.Nil(t, tt.authenticator.Authenticate(request))
+			assert.Equal(t, tt.expect, request.Header.Get("Authorization"))
+		})
+	}
+}
+
+func TestJenkinsCoreAuthenticator(t *testing.T) {
+	request, _ := http.NewRequest(http.MethodGet, "http://localhost", nil)
+	jenkinsCore := &JenkinsCore{UserName: "admin", Token: "token", RoundTripper: http.DefaultTransport,
+		Authenticator: BearerToken{Token: "bearer"}}
+	assert.Nil(t, jenkinsCore.AuthHandle(request))
+	assert.Equal(t, "Bearer bearer", request.Header.Get("Authorization"))
+
+	request, _ = http.NewRequest(http.MethodGet, "http://localhost", nil)
+	jenkinsCore.Authenticator = nil
+	assert.Nil(t, jenkinsCore.AuthHandle(request))
+	assert.Equal(t, "Basic YWRtaW46dG9rZW4=", request.Header.Get("Authorization"))
+}
+
+func TestTokenFile(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	assert.Nil(t, ioutil.WriteFile(tokenFile, []byte("token\n"), 0600))
+
+	authenticator := NewTokenFile(tokenFile, "")
+	request, _ := http.NewRequest(http.MethodGet, "http://localhost", nil)
+	assert.Nil(t, authenticator.Authenticate(request))
+	assert.Equal(t, "Bearer token", request.Header.Get("Authorization"))
+
+	// the token is refreshed once the file changed
+	assert.Nil(t, ioutil.WriteFile(tokenFile, []byte("new-token"), 0600))
+	authenticator.UserName = "admin"
+	assert.Nil(t, authenticator.Authenticate(request))
+	userName, token, _ := request.BasicAuth()
+	assert.Equal(t, "admin", userName)
+	assert.Equal(t, "new-token", token)
+
+	assert.Nil(t, os.Remove(tokenFile))
+	assert.NotNil(t, authenticator.Authenticate(request))
+}
+
+func TestNetrc(t *testing.T) {
+	netrcFile := filepath.Join(t.TempDir(), ".netrc")
+	assert.Nil(t, ioutil.WriteFile(netrcFile, []byte(`# comment
+machine jenkins.example.com login admin password token
+macdef init
+echo machine fake login fake
+
+machine other.example.com
+	login other
+	account fake
+	password other-token
+default login anonymous password none
+`), 0600))
+
+	tests := []struct {
+		url          string
+		wantUserName string
+		wantToken    string
+	}{{
+		url:          "https://jenkins.example.com/api/json",
+		wantUserName: "admin",
+		wantToken:    "token",
+	}, {
+		url:          "http://other.example.com:8080",
+		wantUserName: "other",
+		wantToken:    "other-token",
+	}, {
+		url:          "http://fake",
+		wantUserName: "anonymous",
+		wantToken:    "none",
+	}}
+	authenticator := NewNetrc(netrcFile)
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			request, _ := http.NewRequest(http.MethodGet, tt.url, nil)
+			assert.Nil(t, authenticator.Authenticate(request))
+			userName, token, _ := request.BasicAuth()
+			assert.Equal(t, tt.wantUserName, userName)
+			assert.Equal(t, tt.wantToken, token)
+		})
+	}
+
+	assert.Equal(t, "~/.netrc", NewNetrc("").file.path)
+}
+
+// writeClientCertificate generates a self-signed client certificate, then writes it into PEM files
+func writeClientCertificate(t *testing.T, dir string) (certFile, keyFile string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "jenkins-client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.Nil(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	assert.Nil(t, err)
+
+	certFile, keyFile = filepath.Join(dir, "client.crt"), filepath.Join(dir, "client.key")
+	assert.Nil(t, ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	assert.Nil(t, ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600))
+	return
+}
+
+func TestClientCertificate(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.TLS.PeerCertificates[0].Subject.CommonName))
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	dir := t.TempDir()
+	caFile := filepath.Join(dir, "ca.crt")
+	assert.Nil(t, ioutil.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE",
+		Bytes: server.Certificate().Raw}), 0600))
+	certFile, keyFile := writeClientCertificate(t, dir)
+
+	jenkinsCore := &JenkinsCore{URL: server.URL, Authenticator: NewClientCertificate(certFile, keyFile, caFile)}
+	statusCode, data, err := jenkinsCore.Request(http.MethodGet, "/api/json", nil, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, statusCode)
+	assert.Equal(t, "jenkins-client", string(data))
+
+	jenkinsCore.Authenticator = NewClientCertificate(filepath.Join(dir, "missing.crt"), keyFile, "")
+	_, _, err = jenkinsCore.Request(http.MethodGet, "/api/json", nil, nil)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,369 @@
+package core
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// DefaultCacheSize is the capacity of the default in-memory cache store
+	DefaultCacheSize = 256
+
+	// CacheStatusHeader is set in the responses which go through the cache, its value is one of the cache status
+	CacheStatusHeader = "X-Jenkins-Client-Cache"
+	// CacheHit means the response comes from the cache without sending a request
+	CacheHit = "HIT"
+	// CacheRevalidated means Jenkins responds 304, then the cached response is used
+	CacheRevalidated = "REVALIDATED"
+	// CacheMiss means the response comes from Jenkins
+	CacheMiss = "MISS"
+)
+
+// CachedResponse is a response in the cache
+type CachedResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+
+	// ETag and LastModified are the validators of the conditional requests
+	ETag         string
+	LastModified string
+	// Expires is the deadline of the fresh response, it's zero if the response needs a revalidation every time
+	Expires time.Time
+}
+
+// CacheStore stores the cached responses, the implementations must be safe for the concurrent use
+type CacheStore interface {
+	Get(key string) (*CachedResponse, bool)
+	Set(key string, response *CachedResponse)
+	Delete(key string)
+}
+
+// PrefixDeleter is implemented by the stores which could remove the responses by the prefix of their keys,
+// then the responses of a path with any query are removed once the path is changed
+type PrefixDeleter interface {
+	DeletePrefix(prefix string)
+}
+
+// LRUStore is an in-memory CacheStore which evicts the least recently used response
+type LRUStore struct {
+	capacity int
+
+	mutex   sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List
+}
+
+type lruEntry struct {
+	key      string
+	response *CachedResponse
+}
+
+// NewLRUStore creates a LRUStore with the capacity, DefaultCacheSize is used if the capacity is not positive
+func NewLRUStore(capacity int) *LRUStore {
+	if capacity <= 0 {
+		capacity = DefaultCacheSize
+	}
+	return &LRUStore{
+		capacity: capacity,
+		entries:  map[string]*list.Element{},
+		order:    list.New(),
+	}
+}
+
+// Get returns the cached response by key
+func (s *LRUStore) Get(key string) (response *CachedResponse, ok bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var element *list.Element
+	if element, ok = s.entries[key]; ok {
+		s.order.MoveToFront(element)
+		response = element.Value.(*lruEntry).response
+	}
+	return
+}
+
+// Set puts a response into the store, the least recently used one is evicted if the store is full
+func (s *LRUStore) Set(key string, response *CachedResponse) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if element, ok := s.entries[key]; ok {
+		element.Value.(*lruEntry).response = response
+		s.order.MoveToFront(element)
+		return
+	}
+
+	s.entries[key] = s.order.PushFront(&lruEntry{key: key, response: response})
+	for s.order.Len() > s.capacity {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.entries, oldest.Value.(*lruEntry).key)
+	}
+}
+
+// Delete removes a response from the store
+func (s *LRUStore) Delete(key string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if element, ok := s.entries[key]; ok {
+		s.order.Remove(element)
+		delete(s.entries, key)
+	}
+}
+
+// DeletePrefix removes the responses whose key starts with the prefix
+func (s *LRUStore) DeletePrefix(prefix string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for key, element := range s.entries {
+		if strings.HasPrefix(key, prefix) {
+			s.order.Remove(element)
+			delete(s.entries, key)
+		}
+	}
+}
+
+// Len returns the count of the cached responses
+func (s *LRUStore) Len() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.order.Len()
+}
+
+// CacheStats is the statistics of a ResponseCache
+type CacheStats struct {
+	Hits          uint64
+	Revalidations uint64
+	Misses        uint64
+}
+
+// ResponseCache caches the successful responses of the GET requests of the remote API, such as the status,
+// the computers, the queue, and the plugins. It sends the conditional requests (If-None-Match and If-Modified-Since) if Jenkins returns the validators,
+// or keeps the responses for TTL if there are no validators.
+// The other endpoints, such as the logs, the artifacts, and the config.xml, are never cached.
+// The responses of a path are removed once a request of another method to the path succeeds.
+// The responses are cached per user, then the users with different permissions never share the responses.
+// The clients with a TLSAuthenticator which is not a TLSIdentity don't use the cache.
+type ResponseCache struct {
+	// Store keeps the responses, an in-memory LRU store with DefaultCacheSize is used if it's nil
+	Store CacheStore
+	// TTL is how long the responses without validators are fresh, they are not cached if it's zero
+	TTL time.Duration
+
+	storeOnce sync.Once
+
+	hits          uint64
+	revalidations uint64
+	misses        uint64
+}
+
+// NewResponseCache creates a ResponseCache with the default in-memory LRU store
+func NewResponseCache(ttl time.Duration) *ResponseCache {
+	return &ResponseCache{Store: NewLRUStore(DefaultCacheSize), TTL: ttl}
+}
+
+// getStore returns the Store, the default one is created at the first time if it's nil
+func (c *ResponseCache) getStore() CacheStore {
+	c.storeOnce.Do(func() {
+		if c.Store == nil {
+			c.Store = NewLRUStore(DefaultCacheSize)
+		}
+	})
+	return c.Store
+}
+
+// Stats returns the statistics of the cache
+func (c *ResponseCache) Stats() CacheStats {
+	return CacheStats{
+		Hits:          atomic.LoadUint64(&c.hits),
+		Revalidations: atomic.LoadUint64(&c.revalidations),
+		Misses:        atomic.LoadUint64(&c.misses),
+	}
+}
+
+// cacheablePath returns true if the responses of the path are cacheable, the streaming and progressive
+// endpoints change while they're being read
+func cacheablePath(path string) bool {
+	path = strings.TrimSuffix(path, "/")
+	return strings.HasSuffix(path, "/api/json")
+}
+
+// cacheKey returns the key of a request, it consists of the identity of the user and the URL
+func cacheKey(request *http.Request, tlsIdentity string) string {
+	return fmt.Sprintf("%s %s", cacheIdentity(request, tlsIdentity), request.URL.String())
+}
+
+// cacheIdentity returns the identity of the user who sends the request.
+// The tlsIdentity is the TLS material of the client, the credential is hashed, then it's not kept in the store.
+func cacheIdentity(request *http.Request, tlsIdentity string) string {
+	identity := tlsIdentity + request.Header.Get("Authorization")
+	for _, cookie := range request.Cookies() {
+		if strings.HasPrefix(cookie.Name, "JSESSIONID") {
+			identity += cookie.String()
+		}
+	}
+	sum := sha256.Sum256([]byte(identity))
+	return hex.EncodeToString(sum[:8])
+}
+
+// cachedRoundTripper responds from the cache if possible
+type cachedRoundTripper struct {
+	next        http.RoundTripper
+	cache       *ResponseCache
+	tlsIdentity string
+	// maxSize is the MaxResponseSize of the client, the larger responses are not cached
+	maxSize int64
+}
+
+func (j *JenkinsCore) withCache(roundTripper http.RoundTripper) http.RoundTripper {
+	if j.Cache == nil {
+		return roundTripper
+	}
+
+	cached := &cachedRoundTripper{next: roundTripper, cache: j.Cache, maxSize: j.MaxResponseSize}
+	if _, ok := j.Authenticator.(TLSAuthenticator); ok {
+		identity, ok := j.Authenticator.(TLSIdentity)
+		if !ok {
+			// the user of the connection is unknown, the cached responses might belong to others
+			return roundTripper
+		}
+		cached.tlsIdentity = identity.TLSIdentity()
+	}
+	return cached
+}
+
+// RoundTrip responds from the cache, or sends the (conditional) request to Jenkins
+func (c *cachedRoundTripper) RoundTrip(request *http.Request) (response *http.Response, err error) {
+	if request.Method != http.MethodGet && cacheablePath(request.URL.Path) {
+		if response, err = c.next.RoundTrip(request); err == nil && response.StatusCode < http.StatusBadRequest {
+			c.invalidate(request)
+		}
+		return
+	}
+	// the crumb is bound to the web session, it should be always fresh
+	if request.Method != http.MethodGet || request.Header.Get("Range") != "" ||
+		strings.Contains(request.URL.Path, "/crumbIssuer/") || !cacheablePath(request.URL.Path) {
+		return c.next.RoundTrip(request)
+	}
+
+	store := c.cache.getStore()
+	key := cacheKey(request, c.tlsIdentity)
+	cached, ok := store.Get(key)
+	now := time.Now()
+	if ok && !cached.Expires.IsZero() && now.Before(cached.Expires) {
+		atomic.AddUint64(&c.cache.hits, 1)
+		response = cached.toResponse(request, CacheHit)
+		return
+	}
+
+	outgoing := request
+	if ok && (cached.ETag != "" || cached.LastModified != "") {
+		outgoing = request.Clone(request.Context())
+		if cached.ETag != "" {
+			outgoing.Header.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			outgoing.Header.Set("If-Modified-Since", cached.LastModified)
+		}
+	} else if ok {
+		store.Delete(key)
+		ok = false
+	}
+
+	if response, err = c.next.RoundTrip(outgoing); err != nil {
+		return
+	}
+
+	if ok && response.StatusCode == http.StatusNotModified {
+		_ = response.Body.Close()
+		atomic.AddUint64(&c.cache.revalidations, 1)
+		response = cached.toResponse(request, CacheRevalidated)
+		return
+	}
+
+	atomic.AddUint64(&c.cache.misses, 1)
+	response.Header.Set(CacheStatusHeader, CacheMiss)
+	if response.StatusCode != http.StatusOK || strings.Contains(response.Header.Get("Cache-Control"), "no-store") {
+		return
+	}
+
+	entry := &CachedResponse{
+		StatusCode:   response.StatusCode,
+		Header:       response.Header.Clone(),
+		ETag:         response.Header.Get("ETag"),
+		LastModified: response.Header.Get("Last-Modified"),
+	}
+	if entry.ETag == "" && entry.LastModified == "" {
+		if c.cache.TTL <= 0 {
+			return
+		}
+		entry.Expires = now.Add(c.cache.TTL)
+	}
+
+	var reader io.Reader = response.Body
+	if c.maxSize > 0 {
+		reader = io.LimitReader(response.Body, c.maxSize+1)
+	}
+	var data []byte
+	if data, err = ioutil.ReadAll(reader); err != nil {
+		_ = response.Body.Close()
+		response = nil
+		return
+	}
+	if c.maxSize > 0 && int64(len(data)) > c.maxSize {
+		// leave the rest of the body to the caller, then it fails with the ResponseTooLargeError
+		response.Body = &multiReadCloser{Reader: io.MultiReader(bytes.NewReader(data), response.Body), Closer: response.Body}
+		return
+	}
+	_ = response.Body.Close()
+	entry.Body = data
+	response.Body = ioutil.NopCloser(bytes.NewReader(data))
+	store.Set(key, entry)
+	return
+}
+
+// invalidate removes the cached responses of the path of the request for the current user
+func (c *cachedRoundTripper) invalidate(request *http.Request) {
+	target := *request.URL
+	target.RawQuery, target.Fragment = "", ""
+	key := fmt.Sprintf("%s %s", cacheIdentity(request, c.tlsIdentity), target.String())
+
+	store := c.cache.getStore()
+	store.Delete(key)
+	if deleter, ok := store.(PrefixDeleter); ok {
+		deleter.DeletePrefix(key + "?")
+	}
+}
+
+// multiReadCloser reads the buffered data and the rest of a body, then closes the body
+type multiReadCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// toResponse creates a HTTP response from the cached one
+func (r *CachedResponse) toResponse(request *http.Request, status string) *http.Response {
+	header := r.Header.Clone()
+	header.Set(CacheStatusHeader, status)
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", r.StatusCode, http.StatusText(r.StatusCode)),
+		StatusCode:    r.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewReader(r.Body)),
+		ContentLength: int64(len(r.Body)),
+		Request:       request,
+	}
+}
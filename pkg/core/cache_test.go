@@ -0,0 +1,199 @@
+package core
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newCachedServer(requests *int64) *httptest.Server {
+	version := "1"
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(requests, 1)
+		switch r.URL.Path {
+		case "/etag/api/json":
+			w.Header().Set("ETag", `"v1"`)
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		case "/user/api/json":
+			userName, _, _ := r.BasicAuth()
+			_, _ = w.Write([]byte(userName))
+			return
+		case "/crumbIssuer/api/json":
+			_, _ = w.Write([]byte(`{"crumbRequestField":"Jenkins-Crumb","crumb":"crumb"}`))
+			return
+		case "/missing/api/json":
+			w.WriteHeader(http.StatusNotFound)
+		case "/job/a/api/json":
+			if r.Method == http.MethodPost {
+				version = r.URL.Query().Get("version")
+			}
+			_, _ = w.Write([]byte(version))
+			return
+		case "/large/api/json":
+			_, _ = w.Write([]byte(strings.Repeat("a", 64)))
+			return
+		}
+		_, _ = w.Write([]byte("fake body"))
+	}))
+}
+
+func TestResponseCache(t *testing.T) {
+	var requests int64
+	server := newCachedServer(&requests)
+	defer server.Close()
+
+	metrics := NewMetrics()
+	cache := NewResponseCache(time.Minute)
+	jenkinsCore := &JenkinsCore{URL: server.URL, Cache: cache, Observers: []Observer{metrics}}
+
+	t.Run("conditional requests", func(t *testing.T) {
+		atomic.StoreInt64(&requests, 0)
+		for i := 0; i < 3; i++ {
+			_, data, err := jenkinsCore.Request(http.MethodGet, "/etag/api/json", nil, nil)
+			assert.Nil(t, err)
+			assert.Equal(t, "fake body", string(data))
+		}
+		assert.Equal(t, int64(3), atomic.LoadInt64(&requests))
+		assert.Equal(t, uint64(2), cache.Stats().Revalidations)
+		assert.Equal(t, uint64(2), metrics.CacheCount(http.MethodGet, "/etag/api/json", CacheRevalidated))
+	})
+
+	t.Run("TTL without validators", func(t *testing.T) {
+		atomic.StoreInt64(&requests, 0)
+		for i := 0; i < 3; i++ {
+			_, data, err := jenkinsCore.Request(http.MethodGet, "/plain/api/json", nil, nil)
+			assert.Nil(t, err)
+			assert.Equal(t, "fake body", string(data))
+		}
+		assert.Equal(t, int64(1), atomic.LoadInt64(&requests))
+		assert.Equal(t, uint64(2), cache.Stats().Hits)
+		assert.Equal(t, uint64(1), metrics.CacheCount(http.MethodGet, "/plain/api/json", CacheMiss))
+	})
+
+	t.Run("per user", func(t *testing.T) {
+		for _, userName := range []string{"alice", "bob", "alice"} {
+			userCore := &JenkinsCore{URL: server.URL, UserName: userName, Token: "token", Cache: cache}
+			_, data, err := userCore.Request(http.MethodGet, "/user/api/json", nil, nil)
+			assert.Nil(t, err)
+			assert.Equal(t, userName, string(data))
+		}
+	})
+
+	t.Run("not cacheable", func(t *testing.T) {
+		atomic.StoreInt64(&requests, 0)
+		for i := 0; i < 2; i++ {
+			statusCode, _, err := jenkinsCore.Request(http.MethodGet, "/missing/api/json", nil, nil)
+			assert.Nil(t, err)
+			assert.Equal(t, http.StatusNotFound, statusCode)
+			_, _, err = jenkinsCore.Request(http.MethodPost, "/plain/api/json", nil, nil)
+			assert.Nil(t, err)
+		}
+		// two GET requests, two crumb requests, and two POST requests
+		assert.Equal(t, int64(6), atomic.LoadInt64(&requests))
+	})
+
+	t.Run("streaming endpoints", func(t *testing.T) {
+		atomic.StoreInt64(&requests, 0)
+		for _, api := range []string{"/job/a/1/logText/progressiveText?start=0", "/job/a/1/artifact/a.txt", "/queue/item/1/"} {
+			for i := 0; i < 2; i++ {
+				_, data, err := jenkinsCore.Request(http.MethodGet, api, nil, nil)
+				assert.Nil(t, err)
+				assert.Equal(t, "fake body", string(data))
+			}
+		}
+		assert.Equal(t, int64(6), atomic.LoadInt64(&requests))
+	})
+
+	t.Run("config.xml", func(t *testing.T) {
+		atomic.StoreInt64(&requests, 0)
+		for i := 0; i < 2; i++ {
+			_, _, err := jenkinsCore.Request(http.MethodGet, "/job/a/config.xml", nil, nil)
+			assert.Nil(t, err)
+		}
+		assert.Equal(t, int64(2), atomic.LoadInt64(&requests))
+	})
+
+	t.Run("invalidate the path after a change", func(t *testing.T) {
+		for _, api := range []string{"/job/a/api/json", "/job/a/api/json?tree=name"} {
+			_, data, err := jenkinsCore.Request(http.MethodGet, api, nil, nil)
+			assert.Nil(t, err)
+			assert.Equal(t, "1", string(data))
+		}
+		_, _, err := jenkinsCore.Request(http.MethodPost, "/job/a/api/json?version=2", nil, nil)
+		assert.Nil(t, err)
+		for _, api := range []string{"/job/a/api/json", "/job/a/api/json?tree=name"} {
+			_, data, err := jenkinsCore.Request(http.MethodGet, api, nil, nil)
+			assert.Nil(t, err)
+			assert.Equal(t, "2", string(data))
+		}
+	})
+
+	t.Run("larger than MaxResponseSize", func(t *testing.T) {
+		atomic.StoreInt64(&requests, 0)
+		limitedCore := &JenkinsCore{URL: server.URL, Cache: cache, MaxResponseSize: 32}
+		for i := 0; i < 2; i++ {
+			_, _, err := limitedCore.Request(http.MethodGet, "/large/api/json", nil, nil)
+			assert.True(t, IsResponseTooLarge(err))
+		}
+		assert.Equal(t, int64(2), atomic.LoadInt64(&requests))
+	})
+
+	t.Run("per TLS identity", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodGet, server.URL+"/api/json", nil)
+		assert.NotEqual(t, cacheKey(request, "client-certificate:a"), cacheKey(request, "client-certificate:b"))
+
+		atomic.StoreInt64(&requests, 0)
+		tlsCore := &JenkinsCore{URL: server.URL, Cache: cache, Authenticator: &fakeTLSAuthenticator{}}
+		for i := 0; i < 2; i++ {
+			_, _, err := tlsCore.Request(http.MethodGet, "/plain/api/json", nil, nil)
+			assert.Nil(t, err)
+		}
+		assert.Equal(t, int64(2), atomic.LoadInt64(&requests), "the cache is not shared without a TLS identity")
+	})
+}
+
+func TestZeroResponseCache(t *testing.T) {
+	var requests int64
+	server := newCachedServer(&requests)
+	defer server.Close()
+
+	cache := &ResponseCache{TTL: time.Minute}
+	jenkinsCore := &JenkinsCore{URL: server.URL, Cache: cache}
+	for i := 0; i < 2; i++ {
+		_, data, err := jenkinsCore.Request(http.MethodGet, "/plain/api/json", nil, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, "fake body", string(data))
+	}
+	assert.Equal(t, int64(1), atomic.LoadInt64(&requests))
+	assert.Equal(t, 1, cache.Store.(*LRUStore).Len())
+}
+
+func TestLRUStore(t *testing.T) {
+	store := NewLRUStore(2)
+	store.Set("a", &CachedResponse{})
+	store.Set("b", &CachedResponse{})
+	_, ok := store.Get("a")
+	assert.True(t, ok)
+
+	store.Set("c", &CachedResponse{})
+	assert.Equal(t, 2, store.Len())
+	_, ok = store.Get("b")
+	assert.False(t, ok, "the least recently used one should be evicted")
+	_, ok = store.Get("a")
+	assert.True(t, ok)
+
+	store.Delete("a")
+	assert.Equal(t, 1, store.Len())
+
+	store.Set("c?a", &CachedResponse{})
+	store.DeletePrefix("c?")
+	assert.Equal(t, 1, store.Len())
+}
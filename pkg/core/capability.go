@@ -0,0 +1,291 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ErrCapabilityMissing indicates that Jenkins or a plugin which the method depends on is missing or too old
+var ErrCapabilityMissing = errors.New("capability missing")
+
+// Requirement is a capability which a method depends on, it's about Jenkins core if the Plugin is empty
+type Requirement struct {
+	Plugin string
+	// MinVersion is the minimum version, any version is fine if it's empty
+	MinVersion string
+}
+
+// String returns the text of the requirement, e.g. plugin blueocean-rest >= 1.0
+func (r Requirement) String() (text string) {
+	if r.Plugin == "" {
+		text = "Jenkins"
+	} else {
+		text = "plugin " + r.Plugin
+	}
+	if r.MinVersion != "" {
+		text += " >= " + r.MinVersion
+	}
+	return
+}
+
+var (
+	// RequireBlueOcean is required by the BlueOcean REST API
+	RequireBlueOcean = Requirement{Plugin: "blueocean-rest", MinVersion: "1.0"}
+	// RequirePipelineRestAPI is required by the wfapi endpoints, such as the pending input actions
+	RequirePipelineRestAPI = Requirement{Plugin: "pipeline-rest-api", MinVersion: "2.0"}
+	// RequireRestfulBuild is required by the restFul/build endpoint which returns the build after triggering it
+	RequireRestfulBuild = Requirement{Plugin: "pipeline-restful-api"}
+	// RequireCasC is required by the configuration-as-code endpoints
+	RequireCasC = Requirement{Plugin: "configuration-as-code", MinVersion: "1.0"}
+	// RequireTokenAPI is required by the endpoint which generates the API tokens
+	RequireTokenAPI = Requirement{MinVersion: "2.129"}
+)
+
+// capabilityRetryInterval is how long a failed probe is kept, the capabilities are probed again after it
+const capabilityRetryInterval = time.Minute
+
+// CapabilityError indicates that a requirement is not satisfied
+type CapabilityError struct {
+	Requirement Requirement
+	// Installed is the current version, it's empty if the plugin is not installed or not active
+	Installed string
+}
+
+// Error returns the text of the error, e.g. capability missing: plugin blueocean-rest >= 1.0
+func (e *CapabilityError) Error() (msg string) {
+	msg = fmt.Sprintf("%v: %s", ErrCapabilityMissing, e.Requirement)
+	if e.Installed != "" {
+		msg = fmt.Sprintf("%s, the current version is %s", msg, e.Installed)
+	}
+	return
+}
+
+// Is reports whether the target is ErrCapabilityMissing
+func (e *CapabilityError) Is(target error) bool {
+	return target == ErrCapabilityMissing
+}
+
+// IsCapabilityMissing returns true if the error indicates that a required capability is missing
+func IsCapabilityMissing(err error) bool {
+	return errors.Is(err, ErrCapabilityMissing)
+}
+
+// Capabilities are the version of Jenkins and the active plugins
+type Capabilities struct {
+	// Version is the version of Jenkins core, it comes from the X-Jenkins header
+	Version string
+	// Plugins are the versions of the active plugins, the key is the short name
+	Plugins  map[string]string
+	ProbedAt time.Time
+}
+
+// AtLeast returns true if the version of Jenkins core is not older than the given one
+func (c *Capabilities) AtLeast(version string) bool {
+	return c.Version != "" && CompareVersion(c.Version, version) >= 0
+}
+
+// HasPlugin returns true if the plugin is active, and its version is not older than the minimum version
+func (c *Capabilities) HasPlugin(name, minVersion string) bool {
+	version, ok := c.Plugins[name]
+	return ok && (minVersion == "" || CompareVersion(version, minVersion) >= 0)
+}
+
+// Check returns a *CapabilityError if one of the requirements is not satisfied
+func (c *Capabilities) Check(requirements ...Requirement) error {
+	for _, requirement := range requirements {
+		if requirement.Plugin == "" {
+			if requirement.MinVersion != "" && !c.AtLeast(requirement.MinVersion) {
+				return &CapabilityError{Requirement: requirement, Installed: c.Version}
+			}
+		} else if !c.HasPlugin(requirement.Plugin, requirement.MinVersion) {
+			return &CapabilityError{Requirement: requirement, Installed: c.Plugins[requirement.Plugin]}
+		}
+	}
+	return nil
+}
+
+// CompareVersion compares two versions of Jenkins or plugins, such as 2.387.1 and 1.25.2-rc1.
+// It returns -1 if a is older than b, 1 if a is newer than b, or 0 if they are the same.
+func CompareVersion(a, b string) int {
+	split := func(version string) []string {
+		return strings.FieldsFunc(version, func(r rune) bool {
+			return r == '.' || r == '-' || r == '_'
+		})
+	}
+	segmentsA, segmentsB := split(a), split(b)
+	for i := 0; i < len(segmentsA) || i < len(segmentsB); i++ {
+		var segmentA, segmentB string
+		if i < len(segmentsA) {
+			segmentA = segmentsA[i]
+		}
+		if i < len(segmentsB) {
+			segmentB = segmentsB[i]
+		}
+
+		numberA, errA := strconv.Atoi(segmentA)
+		numberB, errB := strconv.Atoi(segmentB)
+		switch {
+		case errA == nil && errB == nil:
+			if numberA != numberB {
+				return compareInt(numberA, numberB)
+			}
+		case segmentA == "" || segmentB == "":
+			// a pre-release, such as 2.0-rc1, is older than the release 2.0
+			if errA != nil && segmentA != "" {
+				return -1
+			} else if errB != nil && segmentB != "" {
+				return 1
+			}
+			return compareInt(len(segmentA), len(segmentB))
+		case segmentA != segmentB:
+			if errA == nil {
+				return 1
+			} else if errB == nil {
+				return -1
+			}
+			return strings.Compare(segmentA, segmentB)
+		}
+	}
+	return 0
+}
+
+func compareInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// capabilityCache holds the capabilities which are probed once, or the error of a failed probe for a while
+type capabilityCache struct {
+	mutex        sync.Mutex
+	probed       bool
+	capabilities *Capabilities
+	err          error
+	retryAt      time.Time
+}
+
+// capabilityCacheMutex protects the lazy initialization of the capability cache
+var capabilityCacheMutex sync.Mutex
+
+func (j *JenkinsCore) getCapabilityCache() *capabilityCache {
+	capabilityCacheMutex.Lock()
+	defer capabilityCacheMutex.Unlock()
+	if j.capabilities == nil {
+		j.capabilities = &capabilityCache{}
+	}
+	return j.capabilities
+}
+
+// GetCapabilities returns the version of Jenkins and the active plugins, they are probed once then cached.
+// The error of a failed probe is kept for a while, and the errors of the context are not kept at all.
+func (j *JenkinsCore) GetCapabilities(ctx context.Context) (capabilities *Capabilities, err error) {
+	cache := j.getCapabilityCache()
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	if cache.probed {
+		return cache.capabilities, nil
+	}
+	if cache.err != nil && time.Now().Before(cache.retryAt) {
+		return nil, cache.err
+	}
+
+	if capabilities, err = j.probeCapabilities(ctx); err == nil {
+		cache.probed = true
+		cache.capabilities, cache.err = capabilities, nil
+	} else if ctx.Err() == nil {
+		cache.err, cache.retryAt = err, time.Now().Add(capabilityRetryInterval)
+	}
+	return
+}
+
+// RefreshCapabilities probes the capabilities again, it's useful after installing or upgrading plugins
+func (j *JenkinsCore) RefreshCapabilities(ctx context.Context) (capabilities *Capabilities, err error) {
+	cache := j.getCapabilityCache()
+	cache.mutex.Lock()
+	cache.probed = false
+	cache.err = nil
+	cache.mutex.Unlock()
+	return j.GetCapabilities(ctx)
+}
+
+// SetCapabilities sets the capabilities instead of probing them
+func (j *JenkinsCore) SetCapabilities(capabilities *Capabilities) {
+	cache := j.getCapabilityCache()
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	cache.probed = true
+	cache.capabilities, cache.err = capabilities, nil
+}
+
+// Require returns a *CapabilityError if one of the requirements is not satisfied.
+// It does nothing if CheckCapabilities is false and the capabilities are not set, or if the probe fails,
+// for instance, the current user has no permission to read the plugins. Then the request is sent as usual.
+// It returns the error of the context if the context is done during the probe.
+func (j *JenkinsCore) Require(ctx context.Context, requirements ...Requirement) (err error) {
+	cache := j.getCapabilityCache()
+	cache.mutex.Lock()
+	probed := cache.probed
+	cache.mutex.Unlock()
+	if !j.CheckCapabilities && !probed {
+		return
+	}
+
+	var capabilities *Capabilities
+	if capabilities, err = j.GetCapabilities(ctx); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			err = ctxErr
+			return
+		}
+		j.loggerOf(ctx).Debug("cannot probe the capabilities", zap.Error(err))
+		err = nil
+		return
+	}
+	err = capabilities.Check(requirements...)
+	return
+}
+
+// probeCapabilities reads the version of Jenkins and the active plugins
+func (j *JenkinsCore) probeCapabilities(ctx context.Context) (capabilities *Capabilities, err error) {
+	pluginList := struct {
+		Plugins []struct {
+			ShortName string `json:"shortName"`
+			Version   string `json:"version"`
+			Active    bool   `json:"active"`
+		} `json:"plugins"`
+	}{}
+
+	var response *http.Response
+	api := "/pluginManager/api/json?depth=1&tree=plugins[shortName,version,active]"
+	if response, err = j.RequestWithResponseHeaderWithContext(ctx, http.MethodGet, api, nil, nil, &pluginList); err != nil {
+		return
+	}
+	_ = response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		err = NewHTTPError(response, nil)
+		return
+	}
+
+	capabilities = &Capabilities{
+		Version:  response.Header.Get("X-Jenkins"),
+		Plugins:  map[string]string{},
+		ProbedAt: time.Now(),
+	}
+	for _, plugin := range pluginList.Plugins {
+		if plugin.Active {
+			capabilities.Plugins[plugin.ShortName] = plugin.Version
+		}
+	}
+	return
+}
@@ -0,0 +1,123 @@
+package core
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareVersion(t *testing.T) {
+	tests := []struct {
+		a, b   string
+		expect int
+	}{
+		{a: "2.387.1", b: "2.387.1", expect: 0},
+		{a: "2.387.1", b: "2.387", expect: 1},
+		{a: "2.361", b: "2.387.1", expect: -1},
+		{a: "1.10", b: "1.9", expect: 1},
+		{a: "2.0-rc1", b: "2.0", expect: -1},
+		{a: "2.0", b: "2.0-rc1", expect: 1},
+		{a: "1436.vfa_244484591f", b: "1.0", expect: 1},
+		{a: "2.0-beta", b: "2.0-alpha", expect: 1},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expect, CompareVersion(tt.a, tt.b), "%s vs %s", tt.a, tt.b)
+	}
+}
+
+func TestCapabilitiesCheck(t *testing.T) {
+	capabilities := &Capabilities{Version: "2.387.1", Plugins: map[string]string{"blueocean-rest": "0.9"}}
+	assert.True(t, capabilities.AtLeast("2.300"))
+	assert.Nil(t, capabilities.Check(Requirement{MinVersion: "2.300"}))
+
+	err := capabilities.Check(RequireBlueOcean)
+	assert.True(t, IsCapabilityMissing(err))
+	assert.Equal(t, "capability missing: plugin blueocean-rest >= 1.0, the current version is 0.9", err.Error())
+
+	err = capabilities.Check(RequireCasC)
+	assert.Equal(t, "capability missing: plugin configuration-as-code >= 1.0", err.Error())
+	err = capabilities.Check(Requirement{MinVersion: "2.400"})
+	assert.Equal(t, "capability missing: Jenkins >= 2.400, the current version is 2.387.1", err.Error())
+}
+
+func TestRequire(t *testing.T) {
+	var probes int32
+	forbidden := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&probes, 1)
+		if forbidden {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Header().Set("X-Jenkins", "2.387.1")
+		_, _ = w.Write([]byte(`{"plugins":[{"shortName":"blueocean-rest","version":"1.25.2","active":true},
+{"shortName":"configuration-as-code","version":"1.0","active":false}]}`))
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	t.Run("not enabled", func(t *testing.T) {
+		jenkinsCore := &JenkinsCore{URL: server.URL}
+		assert.Nil(t, jenkinsCore.Require(ctx, RequireCasC))
+		assert.Equal(t, int32(0), atomic.LoadInt32(&probes))
+	})
+
+	t.Run("probe once", func(t *testing.T) {
+		jenkinsCore := &JenkinsCore{URL: server.URL, CheckCapabilities: true}
+		assert.Nil(t, jenkinsCore.Require(ctx, RequireBlueOcean))
+		assert.True(t, IsCapabilityMissing(jenkinsCore.Require(ctx, RequireCasC)), "inactive plugin")
+		assert.Equal(t, int32(1), atomic.LoadInt32(&probes))
+
+		capabilities, err := jenkinsCore.GetCapabilities(ctx)
+		if assert.Nil(t, err) {
+			assert.Equal(t, "2.387.1", capabilities.Version)
+		}
+	})
+
+	t.Run("cannot probe", func(t *testing.T) {
+		forbidden = true
+		defer func() {
+			forbidden = false
+		}()
+		jenkinsCore := &JenkinsCore{URL: server.URL, CheckCapabilities: true}
+		assert.Nil(t, jenkinsCore.Require(ctx, RequireCasC))
+		_, err := jenkinsCore.GetCapabilities(ctx)
+		assert.True(t, IsForbidden(err))
+
+		// the failure is kept for a while, then it's probed again
+		count := atomic.LoadInt32(&probes)
+		forbidden = false
+		_, err = jenkinsCore.GetCapabilities(ctx)
+		assert.True(t, IsForbidden(err))
+		assert.Equal(t, count, atomic.LoadInt32(&probes))
+		jenkinsCore.getCapabilityCache().retryAt = time.Now()
+		assert.True(t, IsCapabilityMissing(jenkinsCore.Require(ctx, RequireCasC)))
+	})
+
+	t.Run("cancelled probe", func(t *testing.T) {
+		jenkinsCore := &JenkinsCore{URL: server.URL, CheckCapabilities: true}
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		assert.Equal(t, context.Canceled, jenkinsCore.Require(cancelled, RequireBlueOcean))
+
+		capabilities, err := jenkinsCore.GetCapabilities(ctx)
+		if assert.Nil(t, err, "the error of the context is not kept") {
+			assert.Equal(t, "2.387.1", capabilities.Version)
+		}
+	})
+
+	t.Run("set capabilities", func(t *testing.T) {
+		jenkinsCore := &JenkinsCore{URL: server.URL}
+		jenkinsCore.SetCapabilities(&Capabilities{Plugins: map[string]string{}})
+		assert.True(t, IsCapabilityMissing(jenkinsCore.Require(ctx, RequireBlueOcean)))
+		assert.True(t, IsCapabilityMissing(jenkinsCore.Require(ctx, RequireTokenAPI)))
+
+		jenkinsCore.SetCapabilities(&Capabilities{Version: "2.387.1"})
+		assert.Nil(t, jenkinsCore.Require(ctx, RequireTokenAPI))
+	})
+}
@@ -1,13 +1,11 @@
 package core
 
 import (
-	"crypto/tls"
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
-	"io/ioutil"
-	"log"
 	"net/http"
 	"net/url"
 	"strings"
@@ -18,13 +16,12 @@ import (
 	"moul.io/http2curl"
 
 	ext "github.com/linuxsuren/cobra-extension/version"
-	httpdownloader "github.com/linuxsuren/http-downloader/pkg/net"
 )
 
 // language is for global Accept Language
 var language string
 
-// SetLanguage set the language
+// SetLanguage set the global language, it's used by the clients without a Language
 func SetLanguage(lan string) {
 	language = lan
 }
@@ -40,11 +37,57 @@ type JenkinsCore struct {
 	Proxy              string
 	ProxyAuth          string
 
+	// Logger is the logger of this client, the global Logger is used if it's nil
+	Logger *zap.Logger
+	// Language is the Accept-Language of the requests, the global language is used if it's empty
+	Language string
+
+	// Authenticator authenticates the requests, the basic auth of UserName and Token is used if it's nil
+	Authenticator Authenticator
+
+	// MaxIdleConnsPerHost is the max idle connections to Jenkins, the default value comes from http.DefaultTransport
+	MaxIdleConnsPerHost int
+	// IdleConnTimeout is the max duration which an idle connection keeps alive
+	IdleConnTimeout time.Duration
+	// EnableHTTP2 tries to connect Jenkins via HTTP/2
+	EnableHTTP2 bool
+	// MaxResponseSize is the max bytes of a response body, there is no limit if it's zero
+	MaxResponseSize int64
+	// Observers are notified before and after each HTTP request
+	Observers []Observer
+	// Cache caches the responses of the GET requests, there is no cache if it's nil
+	Cache *ResponseCache
+	// DryRun does not send the mutating requests (POST, PUT, PATCH, and DELETE), they are recorded into AuditSink
+	// and get a synthetic success response instead
+	DryRun bool
+	// AuditSink records the mutating requests, including the real ones and the dry-run ones
+	AuditSink AuditSink
+
 	Debug        bool
 	Output       io.Writer
 	RoundTripper http.RoundTripper
 
+	// RetryPolicy decides how to retry the failed requests, there is no retry if it's nil
+	RetryPolicy *RetryPolicy
+
+	// CacheCrumb reuses the crumb for the same web session instead of fetching it before every POST request
+	CacheCrumb bool
+	// SkipCrumbWithAPIToken does not send the crumb if the requests are authenticated by an API token
+	SkipCrumbWithAPIToken bool
+
+	// Cookies are sent with every request in addition to the ones in Jar
 	Cookies []*http.Cookie
+	// Jar keeps the cookies of the web session for all the requests of this core,
+	// a SessionJar which is scoped to the URL is created if it's nil
+	Jar http.CookieJar
+
+	// CheckCapabilities probes the version of Jenkins and the active plugins once, then the methods which depend on
+	// a missing plugin fail fast with a *CapabilityError instead of an opaque 404
+	CheckCapabilities bool
+
+	crumbs       *crumbCache
+	capabilities *capabilityCache
+	transport    *ownedTransport
 }
 
 // JenkinsCrumb crumb for Jenkins
@@ -53,19 +96,17 @@ type JenkinsCrumb struct {
 	Crumb             string
 }
 
-// GetClient get the default http Jenkins client
+// GetClient get the default http Jenkins client.
+// All the requests of the client fail with the same error if the transport cannot be created.
 func (j *JenkinsCore) GetClient() (client *http.Client) {
 	var roundTripper http.RoundTripper
 	if j.RoundTripper != nil {
 		roundTripper = j.RoundTripper
-	} else {
-		tr := &http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: j.InsecureSkipVerify},
-		}
-		if err := httpdownloader.SetProxy(j.Proxy, j.ProxyAuth, tr); err != nil {
-			log.Fatal(err)
-		}
+	} else if tr, err := j.GetTransport(); err == nil {
 		roundTripper = tr
+	} else {
+		j.GetLogger().Error("cannot create the HTTP transport", zap.Error(err))
+		roundTripper = &errorRoundTripper{err: fmt.Errorf("cannot create the HTTP transport, error is %v", err)}
 	}
 
 	// make sure have a default timeout here
@@ -74,8 +115,26 @@ func (j *JenkinsCore) GetClient() (client *http.Client) {
 	}
 
 	client = &http.Client{
-		Transport: roundTripper,
+		Transport: j.observe(j.withCache(j.withAudit(roundTripper))),
 		Timeout:   j.Timeout * time.Second,
+		Jar:       j.GetJar(),
+	}
+	return
+}
+
+type clientTimeoutKey struct{}
+
+// WithClientTimeout sets the timeout of the HTTP client for the requests with this context instead of Timeout.
+// It's for the requests which take longer than usual, such as running a script.
+func WithClientTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, clientTimeoutKey{}, timeout)
+}
+
+// getClientWithContext returns the HTTP client whose timeout could be set by the context
+func (j *JenkinsCore) getClientWithContext(ctx context.Context) (client *http.Client) {
+	client = j.GetClient()
+	if timeout, ok := ctx.Value(clientTimeoutKey{}).(time.Duration); ok {
+		client.Timeout = timeout
 	}
 	return
 }
@@ -84,26 +143,38 @@ func (j *JenkinsCore) GetClient() (client *http.Client) {
 func (j *JenkinsCore) ProxyHandle(request *http.Request) {
 	if j.ProxyAuth != "" {
 		basicAuth := "Basic " + base64.StdEncoding.EncodeToString([]byte(j.ProxyAuth))
-		Logger.Debug("setting proxy for HTTP request", zap.String("header", basicAuth))
+		j.loggerOf(request.Context()).Debug("setting proxy for HTTP request", zap.String("header", basicAuth))
 		request.Header.Add("Proxy-Authorization", basicAuth)
 	}
 }
 
-// AuthHandle takes care of the auth
+// AuthHandle takes care of the auth, the request ID, the language, the cookies, and the crumb
 func (j *JenkinsCore) AuthHandle(request *http.Request) (err error) {
-	if j.UserName != "" && j.Token != "" {
-		request.SetBasicAuth(j.UserName, j.Token)
+	if err = j.getAuthenticator().Authenticate(request); err != nil {
+		return
 	}
 
 	// not add the User-Agent for tests
 	if j.RoundTripper == nil {
 		request.Header.Set("User-Agent", ext.GetCombinedVersion())
 	}
+	id := RequestIDFromContext(request.Context())
+	if id == "" {
+		id = newRequestID()
+	}
+	request.Header.Set(RequestIDHeader, id)
+	if lan := j.GetLanguage(); lan != "" {
+		request.Header.Set("Accept-Language", lan)
+	}
+
+	for _, c := range j.Cookies {
+		request.AddCookie(c)
+	}
 
 	j.ProxyHandle(request)
 
 	// all post request to Jenkins must be has the crumb
-	if request.Method == http.MethodPost {
+	if request.Method == http.MethodPost && !j.skipCrumb() {
 		err = j.CrumbHandle(request)
 	}
 	return
@@ -111,7 +182,20 @@ func (j *JenkinsCore) AuthHandle(request *http.Request) (err error) {
 
 // CrumbHandle handle crum with http request
 func (j *JenkinsCore) CrumbHandle(request *http.Request) error {
-	if c, err := j.GetCrumb(); err == nil && c != nil {
+	if j.CacheCrumb {
+		if c, ok := j.getCachedCrumb(); ok {
+			if c != nil {
+				request.Header.Set(c.CrumbRequestField, c.Crumb)
+			}
+			return nil
+		}
+	}
+
+	c, err := j.GetCrumbWithContext(request.Context())
+	if err == nil && j.CacheCrumb {
+		j.setCachedCrumb(c)
+	}
+	if err == nil && c != nil {
 		// cannot get the crumb could be a normal situation
 		j.CrumbRequestField = c.CrumbRequestField
 		j.Crumb = c.Crumb
@@ -125,19 +209,24 @@ func (j *JenkinsCore) CrumbHandle(request *http.Request) error {
 
 // GetCrumb get the crumb from Jenkins
 func (j *JenkinsCore) GetCrumb() (crumbIssuer *JenkinsCrumb, err error) {
+	return j.GetCrumbWithContext(context.Background())
+}
+
+// GetCrumbWithContext get the crumb from Jenkins with a context
+func (j *JenkinsCore) GetCrumbWithContext(ctx context.Context) (crumbIssuer *JenkinsCrumb, err error) {
 	var (
-		statusCode int
-		data       []byte
+		response *http.Response
+		data     []byte
 	)
 
-	if statusCode, data, err = j.Request(http.MethodGet, "/crumbIssuer/api/json", nil, nil); err == nil {
-		if statusCode == 200 {
+	if response, data, err = j.request(ctx, http.MethodGet, "/crumbIssuer/api/json", nil, nil); err == nil {
+		if response.StatusCode == 200 {
 			err = json.Unmarshal(data, &crumbIssuer)
-		} else if statusCode == 404 {
+		} else if response.StatusCode == 404 {
 			// return 404 if Jenkins does no have crumb
 			//err = fmt.Errorf("crumb is disabled")
 		} else {
-			err = fmt.Errorf("unexpected status code: %d", statusCode)
+			err = NewHTTPError(response, data)
 		}
 	}
 	return
@@ -146,16 +235,27 @@ func (j *JenkinsCore) GetCrumb() (crumbIssuer *JenkinsCrumb, err error) {
 // RequestWithData requests the api and parse the data into an interface
 func (j *JenkinsCore) RequestWithData(method, api string, headers map[string]string,
 	payload io.Reader, successCode int, obj interface{}) (err error) {
-	var (
-		statusCode int
-		data       []byte
-	)
+	return j.RequestWithDataWithContext(context.Background(), method, api, headers, payload, successCode, obj)
+}
 
-	if statusCode, data, err = j.Request(method, api, headers, payload); err == nil {
-		if statusCode == successCode {
-			err = json.Unmarshal(data, obj)
-		} else {
-			err = j.ErrorHandle(statusCode, data)
+// RequestWithDataWithContext requests the api with a context and parse the data into an interface
+func (j *JenkinsCore) RequestWithDataWithContext(ctx context.Context, method, api string, headers map[string]string,
+	payload io.Reader, successCode int, obj interface{}) (err error) {
+	var response *http.Response
+	if response, err = j.do(withExpectedStatus(ctx, successCode), method, api, headers, payload); err != nil {
+		return
+	}
+	defer func() {
+		_ = response.Body.Close()
+	}()
+
+	if response.StatusCode == successCode {
+		// decode the body directly instead of holding the whole body in memory
+		err = json.NewDecoder(j.LimitBody(response)).Decode(obj)
+	} else {
+		var data []byte
+		if data, err = j.ReadBody(response); err == nil {
+			err = j.responseError(response, data)
 		}
 	}
 	return
@@ -163,14 +263,22 @@ func (j *JenkinsCore) RequestWithData(method, api string, headers map[string]str
 
 // RequestWithoutData requests the api without handling data
 func (j *JenkinsCore) RequestWithoutData(method, api string, headers map[string]string,
+	payload io.Reader, successCode int) (statusCode int, err error) {
+	return j.RequestWithoutDataWithContext(context.Background(), method, api, headers, payload, successCode)
+}
+
+// RequestWithoutDataWithContext requests the api with a context without handling data
+func (j *JenkinsCore) RequestWithoutDataWithContext(ctx context.Context, method, api string, headers map[string]string,
 	payload io.Reader, successCode int) (statusCode int, err error) {
 	var (
-		data []byte
+		response *http.Response
+		data     []byte
 	)
 
-	if statusCode, data, err = j.Request(method, api, headers, payload); err == nil &&
-		statusCode != successCode {
-		err = j.ErrorHandle(statusCode, data)
+	if response, data, err = j.request(withExpectedStatus(ctx, successCode), method, api, headers, payload); err == nil {
+		if statusCode = response.StatusCode; statusCode != successCode {
+			err = j.responseError(response, data)
+		}
 	}
 	return
 }
@@ -183,6 +291,8 @@ type RequestBuilder struct {
 	api         string
 	headers     map[string]string
 	payload     io.Reader
+	idempotent  bool
+	readOnly    bool
 
 	responseCode int
 	data         []byte
@@ -249,6 +359,18 @@ func (r *RequestBuilder) WithValues(values url.Values) *RequestBuilder {
 	return r.WithPayload(strings.NewReader(values.Encode()))
 }
 
+// AsIdempotent marks this request as idempotent, then it could be retried even if it's a POST request
+func (r *RequestBuilder) AsIdempotent() *RequestBuilder {
+	r.idempotent = true
+	return r
+}
+
+// AsReadOnly marks this request as read-only, then it's sent even in the dry-run mode
+func (r *RequestBuilder) AsReadOnly() *RequestBuilder {
+	r.readOnly = true
+	return r
+}
+
 // AddHeader adds a header
 func (r *RequestBuilder) AddHeader(key, val string) *RequestBuilder {
 	r.headers[key] = val
@@ -277,7 +399,23 @@ func (r *RequestBuilder) GetObject(obj interface{}) error {
 
 // Do runs the HTTP request
 func (r *RequestBuilder) Do() (err error) {
-	if r.responseCode, r.data, err = r.client.Request(r.method, r.api, r.headers, r.payload); err == nil {
+	return r.DoWithContext(context.Background())
+}
+
+// DoWithContext runs the HTTP request with a context
+func (r *RequestBuilder) DoWithContext(ctx context.Context) (err error) {
+	if r.idempotent {
+		ctx = WithIdempotent(ctx)
+	}
+	if r.readOnly {
+		ctx = WithReadOnly(ctx)
+	}
+	if len(r.acceptCodes) > 0 {
+		ctx = withExpectedStatus(ctx, r.acceptCodes[0])
+	}
+	var response *http.Response
+	if response, r.data, err = r.client.request(ctx, r.method, r.api, r.headers, r.payload); err == nil {
+		r.responseCode = response.StatusCode
 		found := false
 		for _, code := range r.acceptCodes {
 			if code == r.responseCode {
@@ -286,47 +424,45 @@ func (r *RequestBuilder) Do() (err error) {
 			}
 		}
 		if !found {
-			err = r.client.ErrorHandle(r.responseCode, r.data)
+			err = r.client.responseError(response, r.data)
 		}
 	}
 	return
 }
 
-// ErrorHandle handles the error cases
+// ErrorHandle handles the error cases, the error is a *HTTPError
 func (j *JenkinsCore) ErrorHandle(statusCode int, data []byte) (err error) {
-	if statusCode >= 400 && statusCode < 500 {
-		err = j.PermissionError(statusCode)
-	} else {
-		err = fmt.Errorf("unexpected status code: %d", statusCode)
-	}
+	return j.responseError(&http.Response{StatusCode: statusCode}, data)
+}
 
-	Logger.Debug("get response", zap.String("data", string(data)))
-	return
+// responseError turns an unexpected response into a *HTTPError
+func (j *JenkinsCore) responseError(response *http.Response, data []byte) (err error) {
+	ctx := context.Background()
+	if response.Request != nil {
+		ctx = response.Request.Context()
+	}
+	j.loggerOf(ctx).Debug("get response", zap.Int("code", response.StatusCode), zap.String("data", string(data)))
+	return NewHTTPError(response, data)
 }
 
-// PermissionError handles the no permission
+// PermissionError handles the no permission, the error is a *HTTPError
 func (j *JenkinsCore) PermissionError(statusCode int) (err error) {
-	switch statusCode {
-	case 400:
-		err = fmt.Errorf("bad request, code %d", statusCode)
-	case 404:
-		err = fmt.Errorf("not found resources")
-	default:
-		err = fmt.Errorf("the current user has not permission, code %d", statusCode)
-	}
-	return
+	return &HTTPError{StatusCode: statusCode}
 }
 
 // RequestWithResponseHeader make a common request
 func (j *JenkinsCore) RequestWithResponseHeader(method, api string, headers map[string]string, payload io.Reader, obj interface{}) (
 	response *http.Response, err error) {
-	response, err = j.RequestWithResponse(method, api, headers, payload)
+	return j.RequestWithResponseHeaderWithContext(context.Background(), method, api, headers, payload, obj)
+}
+
+// RequestWithResponseHeaderWithContext make a common request with a context
+func (j *JenkinsCore) RequestWithResponseHeaderWithContext(ctx context.Context, method, api string, headers map[string]string,
+	payload io.Reader, obj interface{}) (response *http.Response, err error) {
+	response, err = j.RequestWithResponseWithContext(ctx, method, api, headers, payload)
 
 	if err == nil && obj != nil && response.StatusCode == 200 {
-		var data []byte
-		if data, err = ioutil.ReadAll(response.Body); err == nil {
-			err = json.Unmarshal(data, obj)
-		}
+		err = json.NewDecoder(j.LimitBody(response)).Decode(obj)
 	}
 
 	return
@@ -335,73 +471,109 @@ func (j *JenkinsCore) RequestWithResponseHeader(method, api string, headers map[
 // RequestWithResponse make a common request
 func (j *JenkinsCore) RequestWithResponse(method, api string, headers map[string]string, payload io.Reader) (
 	response *http.Response, err error) {
-	var (
-		req *http.Request
-	)
+	return j.RequestWithResponseWithContext(context.Background(), method, api, headers, payload)
+}
 
-	if req, err = http.NewRequest(method, fmt.Sprintf("%s%s", j.URL, api), payload); err != nil {
-		return
-	}
-	if err = j.AuthHandle(req); err != nil {
-		return
-	}
+// RequestWithResponseWithContext make a common request with a context
+func (j *JenkinsCore) RequestWithResponseWithContext(ctx context.Context, method, api string, headers map[string]string,
+	payload io.Reader) (response *http.Response, err error) {
+	ctx = ensureRequestID(ctx)
+	return j.retry(ctx, method, payload, j.refreshCrumbOnInvalid(ctx, method, func(payload io.Reader) (response *http.Response, err error) {
+		var (
+			req *http.Request
+		)
+
+		if req, err = http.NewRequestWithContext(ctx, method, fmt.Sprintf("%s%s", j.URL, api), payload); err != nil {
+			return
+		}
+		if err = j.AuthHandle(req); err != nil {
+			return
+		}
 
-	for k, v := range headers {
-		req.Header.Add(k, v)
-	}
+		for k, v := range headers {
+			req.Header.Add(k, v)
+		}
 
-	client := j.GetClient()
+		client := j.getClientWithContext(ctx)
 
-	if curlCmd, curlErr := http2curl.GetCurlCommand(req); curlErr == nil {
-		Logger.Debug("HTTP request as curl", zap.String("cmd", curlCmd.String()))
-	}
-	return client.Do(req)
+		if curlCmd, curlErr := http2curl.GetCurlCommand(req); curlErr == nil {
+			j.loggerOf(ctx).Debug("HTTP request as curl", zap.String("cmd", curlCmd.String()))
+		}
+		return client.Do(req)
+	}))
 }
 
 // Request make a common request
 func (j *JenkinsCore) Request(method, api string, headers map[string]string, payload io.Reader) (
 	statusCode int, data []byte, err error) {
-	var (
-		req        *http.Request
-		response   *http.Response
-		requestURL string
-	)
+	return j.RequestWithContext(context.Background(), method, api, headers, payload)
+}
+
+// RequestWithContext make a common request with a context, the request is canceled once the context is done
+func (j *JenkinsCore) RequestWithContext(ctx context.Context, method, api string, headers map[string]string,
+	payload io.Reader) (statusCode int, data []byte, err error) {
+	var response *http.Response
+	if response, data, err = j.request(ctx, method, api, headers, payload); err == nil {
+		statusCode = response.StatusCode
+	}
+	return
+}
 
+// request sends the HTTP request, then returns the response and its whole body
+func (j *JenkinsCore) request(ctx context.Context, method, api string, headers map[string]string,
+	payload io.Reader) (response *http.Response, data []byte, err error) {
+	if response, err = j.do(ctx, method, api, headers, payload); err == nil {
+		defer func() {
+			_ = response.Body.Close()
+		}()
+		data, err = j.ReadBody(response)
+	}
+	return
+}
+
+// do sends the HTTP request, the caller is responsible for closing the response body
+func (j *JenkinsCore) do(ctx context.Context, method, api string, headers map[string]string,
+	payload io.Reader) (response *http.Response, err error) {
+	var requestURL string
 	if requestURL, err = util.URLJoinAsString(j.URL, api); err != nil {
 		err = fmt.Errorf("cannot parse the URL of Jenkins, error is %v", err)
 		return
 	}
 
-	Logger.Debug("send HTTP request", zap.String("URL", requestURL), zap.String("method", method))
-	if req, err = http.NewRequest(method, requestURL, payload); err != nil {
+	ctx = ensureRequestID(ctx)
+	return j.retry(ctx, method, payload, j.refreshCrumbOnInvalid(ctx, method, func(payload io.Reader) (*http.Response, error) {
+		return j.send(ctx, method, requestURL, headers, payload)
+	}))
+}
+
+// send sends the HTTP request once
+func (j *JenkinsCore) send(ctx context.Context, method, requestURL string, headers map[string]string,
+	payload io.Reader) (response *http.Response, err error) {
+	var req *http.Request
+
+	logger := j.loggerOf(ctx)
+	logger.Debug("send HTTP request", zap.String("URL", requestURL), zap.String("method", method))
+	if req, err = http.NewRequestWithContext(ctx, method, requestURL, payload); err != nil {
 		return
 	}
-	if language != "" {
-		req.Header.Set("Accept-Language", language)
-	}
 	if err = j.AuthHandle(req); err != nil {
 		return
 	}
 
-	for _, c := range j.Cookies {
-		req.AddCookie(c)
-	}
-
 	for k, v := range headers {
 		req.Header.Add(k, v)
 	}
 
 	if curlCmd, curlErr := http2curl.GetCurlCommand(req); curlErr == nil {
-		Logger.Debug("HTTP request as curl", zap.String("cmd", curlCmd.String()))
+		logger.Debug("HTTP request as curl", zap.String("cmd", curlCmd.String()))
 	}
 
-	client := j.GetClient()
+	client := j.getClientWithContext(ctx)
 	if response, err = client.Do(req); err == nil {
-		if len(response.Cookies()) > 0 {
-			j.Cookies = response.Cookies()
+		// make sure the error knows which request it comes from
+		if response.Request == nil {
+			response.Request = req
 		}
-		statusCode = response.StatusCode
-		data, err = ioutil.ReadAll(response.Body)
 	}
 	return
 }
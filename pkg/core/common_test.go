@@ -2,11 +2,15 @@ package core
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/jenkins-zh/jenkins-client/pkg/mock/mhttp"
 	"github.com/stretchr/testify/assert"
@@ -99,6 +103,42 @@ var _ = Describe("common test", func() {
 			Expect(statusCode).To(Equal(200))
 			Expect(string(data)).To(Equal(""))
 		})
+
+		It("carry the context into the request", func() {
+			type ctxKey struct{}
+			ctx := context.WithValue(context.Background(), ctxKey{}, "value")
+			roundTripper.EXPECT().RoundTrip(gomock.Any()).
+				DoAndReturn(func(req *http.Request) (*http.Response, error) {
+					Expect(req.Context().Value(ctxKey{})).To(Equal("value"))
+					return &http.Response{
+						StatusCode: 200,
+						Request:    req,
+						Body:       ioutil.NopCloser(bytes.NewBufferString("")),
+					}, nil
+				})
+
+			statusCode, _, err := jenkinsCore.RequestWithContext(ctx, method, api, headers, payload)
+			Expect(err).To(BeNil())
+			Expect(statusCode).To(Equal(200))
+		})
+
+		It("cancel the request via context", func() {
+			done := make(chan struct{})
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				<-done
+			}))
+			defer server.Close()
+			defer close(done)
+
+			jenkinsCore.RoundTripper = nil
+			jenkinsCore.URL = server.URL
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			_, _, err := jenkinsCore.RequestWithContext(ctx, method, api, headers, payload)
+			Expect(err).To(HaveOccurred())
+			Expect(errors.Is(err, context.Canceled)).To(BeTrue())
+		})
 	})
 
 	Context("GetCrumb", func() {
@@ -290,3 +330,19 @@ func TestRemoveSliceItem(t *testing.T) {
 		})
 	}
 }
+
+func TestWithClientTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(1100 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	jenkinsCore := &JenkinsCore{URL: server.URL, Timeout: 1}
+	_, _, err := jenkinsCore.Request(http.MethodGet, "/scriptText", nil, nil)
+	assert.NotNil(t, err)
+
+	ctx := WithClientTimeout(context.Background(), 0)
+	statusCode, _, err := jenkinsCore.RequestWithContext(ctx, http.MethodGet, "/scriptText", nil, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, statusCode)
+}
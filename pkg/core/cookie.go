@@ -0,0 +1,256 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SessionJar is a http.CookieJar which is scoped to a Jenkins URL, the cookies of the other hosts are ignored.
+// It honours the domain, path, expiry, and secure attributes. It could be saved to a file,
+// then a CLI tool keeps the web session across runs.
+type SessionJar struct {
+	host string
+
+	mutex   sync.Mutex
+	entries map[string]*sessionCookie
+}
+
+// sessionCookie is a cookie in the jar, it's the format of the persisted file as well
+type sessionCookie struct {
+	Name     string    `json:"name"`
+	Value    string    `json:"value"`
+	Domain   string    `json:"domain"`
+	HostOnly bool      `json:"hostOnly"`
+	Path     string    `json:"path"`
+	Secure   bool      `json:"secure,omitempty"`
+	HTTPOnly bool      `json:"httpOnly,omitempty"`
+	Expires  time.Time `json:"expires,omitempty"`
+}
+
+func (c *sessionCookie) key() string {
+	return fmt.Sprintf("%s;%s;%s", c.Domain, c.Path, c.Name)
+}
+
+func (c *sessionCookie) expired(now time.Time) bool {
+	return !c.Expires.IsZero() && !now.Before(c.Expires)
+}
+
+// matches returns true if the cookie should be sent to the URL
+func (c *sessionCookie) matches(target *url.URL, now time.Time) bool {
+	host := canonicalHost(target)
+	if c.expired(now) || (c.Secure && target.Scheme != "https") {
+		return false
+	}
+	if c.HostOnly && host != c.Domain || !c.HostOnly && !domainMatches(host, c.Domain) {
+		return false
+	}
+	return pathMatches(requestPath(target), c.Path)
+}
+
+// NewSessionJar creates a SessionJar for the Jenkins URL
+func NewSessionJar(jenkinsURL string) (jar *SessionJar, err error) {
+	var target *url.URL
+	if target, err = url.Parse(jenkinsURL); err != nil {
+		err = fmt.Errorf("cannot parse the URL of Jenkins, error is %v", err)
+		return
+	}
+	jar = &SessionJar{host: canonicalHost(target), entries: map[string]*sessionCookie{}}
+	return
+}
+
+// LoadSessionJar creates a SessionJar for the Jenkins URL with the cookies in the file,
+// the jar is empty if the file does not exist
+func LoadSessionJar(jenkinsURL, path string) (jar *SessionJar, err error) {
+	if jar, err = NewSessionJar(jenkinsURL); err == nil {
+		if err = jar.Load(path); os.IsNotExist(err) {
+			err = nil
+		}
+	}
+	return
+}
+
+// SetCookies keeps the cookies of the response which comes from the URL
+func (s *SessionJar) SetCookies(target *url.URL, cookies []*http.Cookie) {
+	host := canonicalHost(target)
+	if host != s.host {
+		return
+	}
+
+	now := time.Now()
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, cookie := range cookies {
+		entry := &sessionCookie{
+			Name:     cookie.Name,
+			Value:    cookie.Value,
+			Domain:   host,
+			HostOnly: true,
+			Path:     cookie.Path,
+			Secure:   cookie.Secure,
+			HTTPOnly: cookie.HttpOnly,
+		}
+		if domain := strings.ToLower(strings.TrimPrefix(cookie.Domain, ".")); domain != "" && domain != host {
+			// a cookie for another domain, or for an IP address, is rejected
+			if net.ParseIP(host) != nil || !domainMatches(host, domain) {
+				continue
+			}
+			entry.Domain, entry.HostOnly = domain, false
+		}
+		if !strings.HasPrefix(entry.Path, "/") {
+			entry.Path = defaultPath(target)
+		}
+
+		switch {
+		case cookie.MaxAge < 0:
+			entry.Expires = now
+		case cookie.MaxAge > 0:
+			entry.Expires = now.Add(time.Duration(cookie.MaxAge) * time.Second)
+		case !cookie.Expires.IsZero():
+			entry.Expires = cookie.Expires
+		}
+
+		if entry.expired(now) {
+			delete(s.entries, entry.key())
+		} else {
+			s.entries[entry.key()] = entry
+		}
+	}
+}
+
+// Cookies returns the cookies which should be sent to the URL
+func (s *SessionJar) Cookies(target *url.URL) (cookies []*http.Cookie) {
+	if canonicalHost(target) != s.host {
+		return
+	}
+
+	now := time.Now()
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for key, entry := range s.entries {
+		if entry.expired(now) {
+			delete(s.entries, key)
+		} else if entry.matches(target, now) {
+			cookies = append(cookies, &http.Cookie{Name: entry.Name, Value: entry.Value})
+		}
+	}
+	return
+}
+
+// Clear removes all the cookies, then a new web session starts with the next request
+func (s *SessionJar) Clear() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.entries = map[string]*sessionCookie{}
+}
+
+// Save writes the cookies into a file which is only readable by the current user
+func (s *SessionJar) Save(path string) (err error) {
+	now := time.Now()
+	s.mutex.Lock()
+	entries := make([]*sessionCookie, 0, len(s.entries))
+	for _, entry := range s.entries {
+		if !entry.expired(now) {
+			entries = append(entries, entry)
+		}
+	}
+	s.mutex.Unlock()
+
+	var data []byte
+	if data, err = json.MarshalIndent(entries, "", "  "); err != nil {
+		return
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0700); err == nil {
+		err = ioutil.WriteFile(path, data, 0600)
+	}
+	return
+}
+
+// Load reads the cookies from a file which is written by Save, the cookies of the other hosts are ignored
+func (s *SessionJar) Load(path string) (err error) {
+	var data []byte
+	if data, err = ioutil.ReadFile(path); err != nil {
+		return
+	}
+
+	var entries []*sessionCookie
+	if err = json.Unmarshal(data, &entries); err != nil {
+		err = fmt.Errorf("cannot parse the cookie file %s, error is %v", path, err)
+		return
+	}
+
+	now := time.Now()
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, entry := range entries {
+		if !entry.expired(now) && domainMatches(s.host, entry.Domain) {
+			s.entries[entry.key()] = entry
+		}
+	}
+	return
+}
+
+// canonicalHost returns the lower case host without the port
+func canonicalHost(target *url.URL) string {
+	return strings.ToLower(target.Hostname())
+}
+
+// domainMatches returns true if the host is the domain or a subdomain of it
+func domainMatches(host, domain string) bool {
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
+
+func requestPath(target *url.URL) string {
+	if target.Path == "" {
+		return "/"
+	}
+	return target.Path
+}
+
+// defaultPath returns the directory of the request path, see also RFC 6265 section 5.1.4
+func defaultPath(target *url.URL) string {
+	path := requestPath(target)
+	if index := strings.LastIndex(path, "/"); index > 0 {
+		return path[:index]
+	}
+	return "/"
+}
+
+// pathMatches returns true if the request path is the cookie path or under it, see also RFC 6265 section 5.1.4
+func pathMatches(path, cookiePath string) bool {
+	if !strings.HasPrefix(path, cookiePath) {
+		return false
+	}
+	return len(path) == len(cookiePath) || strings.HasSuffix(cookiePath, "/") || path[len(cookiePath)] == '/'
+}
+
+// sessionJarMutex protects the lazy initialization of the cookie jar
+var sessionJarMutex sync.Mutex
+
+// GetJar returns the cookie jar of this core, a SessionJar for the URL is created if the Jar is nil
+func (j *JenkinsCore) GetJar() http.CookieJar {
+	sessionJarMutex.Lock()
+	defer sessionJarMutex.Unlock()
+	if j.Jar == nil {
+		if jar, err := NewSessionJar(j.URL); err == nil {
+			j.Jar = jar
+		}
+	}
+	return j.Jar
+}
+
+// ClearCookies removes the cookies of the jar, and the cached crumb which is tied to the web session
+func (j *JenkinsCore) ClearCookies() {
+	if jar, ok := j.GetJar().(interface{ Clear() }); ok {
+		jar.Clear()
+	}
+	j.InvalidateCrumb()
+}
@@ -0,0 +1,138 @@
+package core
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSessionJar(t *testing.T) {
+	jar, err := NewSessionJar("https://jenkins.example.com:8080/jenkins")
+	assert.Nil(t, err)
+
+	mustParse := func(raw string) *url.URL {
+		target, _ := url.Parse(raw)
+		return target
+	}
+	names := func(cookies []*http.Cookie) (result []string) {
+		for _, cookie := range cookies {
+			result = append(result, cookie.Name+"="+cookie.Value)
+		}
+		return
+	}
+
+	jar.SetCookies(mustParse("https://jenkins.example.com/jenkins/login"), []*http.Cookie{
+		{Name: "JSESSIONID.abc", Value: "session", Path: "/jenkins", HttpOnly: true},
+		{Name: "secure", Value: "1", Path: "/", Secure: true},
+		{Name: "expired", Value: "1", MaxAge: -1},
+		{Name: "short", Value: "1", Expires: time.Now().Add(-time.Minute)},
+		{Name: "other", Value: "1", Domain: "other.com"},
+		{Name: "parent", Value: "1", Domain: ".example.com", Path: "/"},
+	})
+	jar.SetCookies(mustParse("https://jenkins.example.com/jenkins/security/login"), []*http.Cookie{{Name: "login", Value: "1"}})
+	jar.SetCookies(mustParse("https://other.com/"), []*http.Cookie{{Name: "foreign", Value: "1"}})
+
+	tests := []struct {
+		name   string
+		url    string
+		expect []string
+	}{{
+		name:   "the path of Jenkins",
+		url:    "https://jenkins.example.com/jenkins/job/a",
+		expect: []string{"JSESSIONID.abc=session", "parent=1", "secure=1"},
+	}, {
+		name:   "the default path",
+		url:    "https://jenkins.example.com/jenkins/security/logout",
+		expect: []string{"JSESSIONID.abc=session", "login=1", "parent=1", "secure=1"},
+	}, {
+		name:   "not secure",
+		url:    "http://jenkins.example.com/jenkins/",
+		expect: []string{"JSESSIONID.abc=session", "parent=1"},
+	}, {
+		name:   "out of the path",
+		url:    "https://jenkins.example.com/jenkinsfile",
+		expect: []string{"parent=1", "secure=1"},
+	}, {
+		name: "another host",
+		url:  "https://other.com/jenkins/",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cookies := names(jar.Cookies(mustParse(tt.url)))
+			if tt.expect == nil {
+				assert.Empty(t, cookies)
+			} else {
+				assert.ElementsMatch(t, tt.expect, cookies)
+			}
+		})
+	}
+
+	t.Run("save and load", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "cookies", "jenkins.json")
+		assert.Nil(t, jar.Save(path))
+
+		loaded, err := LoadSessionJar("https://jenkins.example.com/jenkins", path)
+		assert.Nil(t, err)
+		assert.ElementsMatch(t, names(jar.Cookies(mustParse(tests[1].url))), names(loaded.Cookies(mustParse(tests[1].url))))
+
+		loaded, err = LoadSessionJar("https://jenkins.example.com/jenkins", filepath.Join(t.TempDir(), "missing"))
+		assert.Nil(t, err)
+		assert.Empty(t, loaded.Cookies(mustParse(tests[1].url)))
+	})
+
+	jar.Clear()
+	assert.Empty(t, jar.Cookies(mustParse(tests[0].url)))
+}
+
+func TestJenkinsCoreCookies(t *testing.T) {
+	var (
+		mutex    sync.Mutex
+		sessions []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		session := ""
+		if cookie, err := r.Cookie("JSESSIONID"); err == nil {
+			session = cookie.Value
+		} else {
+			http.SetCookie(w, &http.Cookie{Name: "JSESSIONID", Value: "session", Path: "/"})
+		}
+		mutex.Lock()
+		sessions = append(sessions, session)
+		mutex.Unlock()
+		if r.URL.Path == "/other" {
+			http.SetCookie(w, &http.Cookie{Name: "other", Value: "1", Path: "/other"})
+		}
+	}))
+	defer server.Close()
+
+	jenkinsCore := &JenkinsCore{URL: server.URL}
+	_, _, err := jenkinsCore.Request(http.MethodGet, "/api/json", nil, nil)
+	assert.Nil(t, err)
+	_, err = jenkinsCore.RequestWithResponse(http.MethodGet, "/other", nil, nil)
+	assert.Nil(t, err)
+	_, _, err = jenkinsCore.Request(http.MethodGet, "/api/json", nil, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"", "session", "session"}, sessions, "the session is kept by the other cookies")
+	assert.Equal(t, "JSESSIONID=session", jenkinsCore.getSession())
+
+	jenkinsCore.ClearCookies()
+	assert.Equal(t, "", jenkinsCore.getSession())
+	_, _, err = jenkinsCore.Request(http.MethodGet, "/api/json", nil, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "", sessions[len(sessions)-1])
+
+	t.Run("the cookies of the client", func(t *testing.T) {
+		cookieCore := &JenkinsCore{URL: server.URL, Cookies: []*http.Cookie{{Name: "JSESSIONID", Value: "given"}}}
+		response, err := cookieCore.RequestWithResponse(http.MethodGet, "/api/json", nil, nil)
+		if assert.Nil(t, err) {
+			_ = response.Body.Close()
+		}
+		assert.Equal(t, "given", sessions[len(sessions)-1])
+	})
+}
@@ -1,6 +1,7 @@
 package core
 
 import (
+	"context"
 	"encoding/json"
 	"net/url"
 
@@ -31,22 +32,37 @@ type Client struct {
 
 // Restart will send the restart request
 func (q *Client) Restart() (err error) {
+	return q.RestartWithContext(context.Background())
+}
+
+// RestartWithContext will send the restart request with a context
+func (q *Client) RestartWithContext(ctx context.Context) (err error) {
 	request := NewRequest("/safeRestart", &q.JenkinsCore)
 	request.WithPostMethod().AcceptStatusCode(503)
-	err = request.Do()
+	err = request.DoWithContext(ctx)
 	return
 }
 
 // RestartDirectly restart Jenkins directly
 func (q *Client) RestartDirectly() (err error) {
+	return q.RestartDirectlyWithContext(context.Background())
+}
+
+// RestartDirectlyWithContext restart Jenkins directly with a context
+func (q *Client) RestartDirectlyWithContext(ctx context.Context) (err error) {
 	request := NewRequest("/restart", &q.JenkinsCore)
 	request.WithPostMethod().AcceptStatusCode(503)
-	err = request.Do()
+	err = request.DoWithContext(ctx)
 	return
 }
 
 // Shutdown puts Jenkins into the quiet mode, wait for existing builds to be completed, and then shut down Jenkins
 func (q *Client) Shutdown(safe bool) (err error) {
+	return q.ShutdownWithContext(context.Background(), safe)
+}
+
+// ShutdownWithContext is the context-aware version of Shutdown
+func (q *Client) ShutdownWithContext(ctx context.Context, safe bool) (err error) {
 	var request *RequestBuilder
 	if safe {
 		request = NewRequest("/safeExit", &q.JenkinsCore)
@@ -54,7 +70,7 @@ func (q *Client) Shutdown(safe bool) (err error) {
 		request = NewRequest("/exit", &q.JenkinsCore)
 	}
 	request.WithPostMethod()
-	err = request.Do()
+	err = request.DoWithContext(ctx)
 	return
 }
 
@@ -97,13 +113,18 @@ func (r JSONResult) GetStatus() string {
 // ToJSON turns a Jenkinsfile to JSON format
 // Read details from https://github.com/jenkinsci/pipeline-model-definition-plugin/blob/master/EXTENDING.md
 func (q *Client) ToJSON(jenkinsfile string) (result GenericResult, err error) {
+	return q.ToJSONWithContext(context.Background(), jenkinsfile)
+}
+
+// ToJSONWithContext turns a Jenkinsfile to JSON format with a context
+func (q *Client) ToJSONWithContext(ctx context.Context, jenkinsfile string) (result GenericResult, err error) {
 	genericResult := &Result{
 		Data: &JSONResult{},
 	}
 
 	request := NewRequest("/pipeline-model-converter/toJson", &q.JenkinsCore)
-	request.WithPostMethod().AsFormRequest().WithValues(url.Values{"jenkinsfile": {jenkinsfile}})
-	if err = request.Do(); err == nil {
+	request.WithPostMethod().AsReadOnly().AsFormRequest().WithValues(url.Values{"jenkinsfile": {jenkinsfile}})
+	if err = request.DoWithContext(ctx); err == nil {
 		if err = request.GetObject(genericResult); err == nil {
 			result = genericResult.Data
 		}
@@ -136,13 +157,18 @@ func (r JenkinsfileResult) GetStatus() string {
 // ToJenkinsfile converts a JSON format data to Jenkinsfile
 // Read details from https://github.com/jenkinsci/pipeline-model-definition-plugin/blob/master/EXTENDING.md
 func (q *Client) ToJenkinsfile(data string) (result GenericResult, err error) {
+	return q.ToJenkinsfileWithContext(context.Background(), data)
+}
+
+// ToJenkinsfileWithContext converts a JSON format data to Jenkinsfile with a context
+func (q *Client) ToJenkinsfileWithContext(ctx context.Context, data string) (result GenericResult, err error) {
 	genericResult := &Result{
 		Data: &JenkinsfileResult{},
 	}
 
 	request := NewRequest("/pipeline-model-converter/toJenkinsfile", &q.JenkinsCore)
-	request.WithPostMethod().AsFormRequest().WithValues(url.Values{"json": {data}})
-	if err = request.Do(); err == nil {
+	request.WithPostMethod().AsReadOnly().AsFormRequest().WithValues(url.Values{"json": {data}})
+	if err = request.DoWithContext(ctx); err == nil {
 		if err = request.GetObject(genericResult); err == nil {
 			result = genericResult.Data
 		}
@@ -183,9 +209,14 @@ type AgentLabel struct {
 // GetLabels returns the labels of all the Jenkins agents
 // Read details from https://github.com/jenkinsci/label-linked-jobs-plugin
 func (q *Client) GetLabels() (labelsRes *LabelsResponse, err error) {
+	return q.GetLabelsWithContext(context.Background())
+}
+
+// GetLabelsWithContext returns the labels of all the Jenkins agents with a context
+func (q *Client) GetLabelsWithContext(ctx context.Context) (labelsRes *LabelsResponse, err error) {
 	labelsRes = &LabelsResponse{}
 	request := NewRequest("/labelsdashboard/labelsData", &q.JenkinsCore)
-	if err = request.Do(); err == nil {
+	if err = request.DoWithContext(ctx); err == nil {
 		err = request.GetObject(labelsRes)
 	}
 	return
@@ -193,6 +224,11 @@ func (q *Client) GetLabels() (labelsRes *LabelsResponse, err error) {
 
 // PrepareShutdown Put Jenkins in a Quiet mode, in preparation for a restart. In that mode Jenkins don’t start any build
 func (q *Client) PrepareShutdown(cancel bool) (err error) {
+	return q.PrepareShutdownWithContext(context.Background(), cancel)
+}
+
+// PrepareShutdownWithContext is the context-aware version of PrepareShutdown
+func (q *Client) PrepareShutdownWithContext(ctx context.Context, cancel bool) (err error) {
 	var api string
 	if cancel {
 		api = "/cancelQuietDown"
@@ -201,7 +237,7 @@ func (q *Client) PrepareShutdown(cancel bool) (err error) {
 	}
 	request := NewRequest(api, &q.JenkinsCore)
 	request.WithPostMethod()
-	err = request.Do()
+	err = request.DoWithContext(ctx)
 	return
 }
 
@@ -214,8 +250,13 @@ type JenkinsIdentity struct {
 
 // GetIdentity returns the identity of a Jenkins
 func (q *Client) GetIdentity() (identity JenkinsIdentity, err error) {
+	return q.GetIdentityWithContext(context.Background())
+}
+
+// GetIdentityWithContext returns the identity of a Jenkins with a context
+func (q *Client) GetIdentityWithContext(ctx context.Context) (identity JenkinsIdentity, err error) {
 	request := NewRequest("/instance", &q.JenkinsCore)
-	if err = request.Do(); err == nil {
+	if err = request.DoWithContext(ctx); err == nil {
 		err = request.GetObject(&identity)
 	}
 	return
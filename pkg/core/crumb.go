@@ -0,0 +1,129 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// crumbCache holds the crumb for a web session
+type crumbCache struct {
+	mutex   sync.Mutex
+	fetched bool
+	session string
+	crumb   *JenkinsCrumb
+}
+
+// crumbCacheMutex protects the lazy initialization of the crumb cache
+var crumbCacheMutex sync.Mutex
+
+func (j *JenkinsCore) getCrumbCache() *crumbCache {
+	crumbCacheMutex.Lock()
+	defer crumbCacheMutex.Unlock()
+	if j.crumbs == nil {
+		j.crumbs = &crumbCache{}
+	}
+	return j.crumbs
+}
+
+// getSession returns the session cookie which the crumb is tied to
+func (j *JenkinsCore) getSession() string {
+	cookies := append([]*http.Cookie{}, j.Cookies...)
+	if jar := j.GetJar(); jar != nil {
+		if target, err := url.Parse(j.URL); err == nil {
+			target.Path = strings.TrimSuffix(target.Path, "/") + "/"
+			cookies = append(cookies, jar.Cookies(target)...)
+		}
+	}
+	for _, c := range cookies {
+		if strings.HasPrefix(c.Name, "JSESSIONID") {
+			return c.Name + "=" + c.Value
+		}
+	}
+	return ""
+}
+
+// getCachedCrumb returns the cached crumb, the second return value is false if there is no cache for the current session
+func (j *JenkinsCore) getCachedCrumb() (crumb *JenkinsCrumb, ok bool) {
+	cache := j.getCrumbCache()
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	if cache.fetched && cache.session == j.getSession() {
+		crumb, ok = cache.crumb, true
+	}
+	return
+}
+
+func (j *JenkinsCore) setCachedCrumb(crumb *JenkinsCrumb) {
+	cache := j.getCrumbCache()
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	cache.fetched = true
+	cache.session = j.getSession()
+	cache.crumb = crumb
+}
+
+// InvalidateCrumb clears the cached crumb, a new one will be fetched for the next POST request
+func (j *JenkinsCore) InvalidateCrumb() {
+	cache := j.getCrumbCache()
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	cache.fetched = false
+	cache.crumb = nil
+}
+
+// skipCrumb returns true if the crumb is not necessary for the requests
+func (j *JenkinsCore) skipCrumb() bool {
+	// Jenkins does not require the crumb for the requests which are authenticated by the API token
+	if !j.SkipCrumbWithAPIToken {
+		return false
+	}
+	auth, ok := j.getAuthenticator().(BasicAuth)
+	return ok && auth.UserName != "" && auth.Token != ""
+}
+
+// refreshCrumbOnInvalid sends the request again with a fresh crumb once Jenkins rejects the cached one
+func (j *JenkinsCore) refreshCrumbOnInvalid(ctx context.Context, method string, send func(io.Reader) (*http.Response, error)) func(io.Reader) (*http.Response, error) {
+	if !j.CacheCrumb || method != http.MethodPost || j.skipCrumb() {
+		return send
+	}
+
+	return func(payload io.Reader) (response *http.Response, err error) {
+		var data []byte
+		if payload != nil {
+			if data, err = ioutil.ReadAll(payload); err != nil {
+				return
+			}
+		}
+		if response, err = send(replayPayload(payload, data)); err != nil || response.StatusCode != http.StatusForbidden {
+			return
+		}
+
+		var body []byte
+		if body, err = ioutil.ReadAll(response.Body); err != nil {
+			return
+		}
+		_ = response.Body.Close()
+		if !bytes.Contains(body, []byte("No valid crumb")) {
+			response.Body = ioutil.NopCloser(bytes.NewReader(body))
+			return
+		}
+
+		j.loggerOf(ctx).Debug("the crumb is invalid, fetch a new one")
+		j.InvalidateCrumb()
+		return send(replayPayload(payload, data))
+	}
+}
+
+// replayPayload returns a new reader of the data, or nil if there is no payload
+func replayPayload(payload io.Reader, data []byte) io.Reader {
+	if payload == nil {
+		return nil
+	}
+	return bytes.NewReader(data)
+}
@@ -0,0 +1,116 @@
+package core
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeCrumbServer struct {
+	*httptest.Server
+	crumbCount int32
+	postCount  int32
+	bodies     []string
+	// expiredCrumbs are the crumbs which will be rejected
+	expiredCrumbs map[string]bool
+}
+
+func newFakeCrumbServer() (server *fakeCrumbServer) {
+	server = &fakeCrumbServer{expiredCrumbs: map[string]bool{}}
+	server.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/crumbIssuer/api/json" {
+			count := atomic.AddInt32(&server.crumbCount, 1)
+			_, _ = fmt.Fprintf(w, `{"crumbRequestField":"Jenkins-Crumb","crumb":"crumb-%d"}`, count)
+			return
+		}
+
+		atomic.AddInt32(&server.postCount, 1)
+		data, _ := ioutil.ReadAll(r.Body)
+		server.bodies = append(server.bodies, string(data))
+		crumb := r.Header.Get("Jenkins-Crumb")
+		if crumb == "" || server.expiredCrumbs[crumb] {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte("<h2>HTTP ERROR 403 No valid crumb was included in the request</h2>"))
+		}
+	}))
+	return
+}
+
+func TestCrumbCache(t *testing.T) {
+	t.Run("fetch the crumb for every request without cache", func(t *testing.T) {
+		server := newFakeCrumbServer()
+		defer server.Close()
+
+		jenkinsCore := &JenkinsCore{URL: server.URL}
+		for i := 0; i < 3; i++ {
+			assert.Nil(t, NewRequest("/fake", jenkinsCore).WithPostMethod().Do())
+		}
+		assert.Equal(t, int32(3), server.crumbCount)
+	})
+
+	t.Run("reuse the cached crumb", func(t *testing.T) {
+		server := newFakeCrumbServer()
+		defer server.Close()
+
+		jenkinsCore := &JenkinsCore{URL: server.URL, CacheCrumb: true}
+		for i := 0; i < 3; i++ {
+			assert.Nil(t, NewRequest("/fake", jenkinsCore).WithPostMethod().Do())
+		}
+		assert.Equal(t, int32(1), server.crumbCount)
+		assert.Equal(t, int32(3), server.postCount)
+	})
+
+	t.Run("fetch a new crumb once the session changed", func(t *testing.T) {
+		server := newFakeCrumbServer()
+		defer server.Close()
+
+		jenkinsCore := &JenkinsCore{URL: server.URL, CacheCrumb: true}
+		assert.Nil(t, NewRequest("/fake", jenkinsCore).WithPostMethod().Do())
+		jenkinsCore.Cookies = []*http.Cookie{{Name: "JSESSIONID.abc", Value: "new"}}
+		assert.Nil(t, NewRequest("/fake", jenkinsCore).WithPostMethod().Do())
+		assert.Equal(t, int32(2), server.crumbCount)
+	})
+
+	t.Run("refresh the invalid crumb and retry once", func(t *testing.T) {
+		server := newFakeCrumbServer()
+		defer server.Close()
+
+		jenkinsCore := &JenkinsCore{URL: server.URL, CacheCrumb: true}
+		assert.Nil(t, NewRequest("/fake", jenkinsCore).WithPostMethod().Do())
+
+		server.expiredCrumbs["crumb-1"] = true
+		err := NewRequest("/fake", jenkinsCore).WithPostMethod().WithPayload(strings.NewReader("payload")).Do()
+		assert.Nil(t, err)
+		assert.Equal(t, int32(2), server.crumbCount)
+		assert.Equal(t, int32(3), server.postCount)
+		assert.Equal(t, []string{"", "payload", "payload"}, server.bodies)
+	})
+
+	t.Run("return the error if the new crumb is invalid as well", func(t *testing.T) {
+		server := newFakeCrumbServer()
+		defer server.Close()
+		server.expiredCrumbs["crumb-1"] = true
+		server.expiredCrumbs["crumb-2"] = true
+
+		jenkinsCore := &JenkinsCore{URL: server.URL, CacheCrumb: true}
+		err := NewRequest("/fake", jenkinsCore).WithPostMethod().Do()
+		assert.True(t, IsCrumbInvalid(err))
+		assert.Equal(t, int32(2), server.postCount)
+	})
+
+	t.Run("skip the crumb with an API token", func(t *testing.T) {
+		server := newFakeCrumbServer()
+		defer server.Close()
+
+		jenkinsCore := &JenkinsCore{URL: server.URL, UserName: "admin", Token: "token", SkipCrumbWithAPIToken: true}
+		err := NewRequest("/fake", jenkinsCore).WithPostMethod().Do()
+		assert.True(t, IsCrumbInvalid(err))
+		assert.Equal(t, int32(0), server.crumbCount)
+	})
+}
@@ -0,0 +1,178 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	// ErrBadRequest indicates that Jenkins rejected the request as invalid, HTTP code 400
+	ErrBadRequest = errors.New("bad request")
+	// ErrUnauthorized indicates that the credential is missing or invalid, HTTP code 401
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrForbidden indicates that the current user has no permission, HTTP code 403
+	ErrForbidden = errors.New("forbidden")
+	// ErrCrumbInvalid indicates that the request was rejected due to a missing or an expired crumb
+	ErrCrumbInvalid = errors.New("no valid crumb was included in the request")
+	// ErrNotFound indicates that the resources does not exist, HTTP code 404
+	ErrNotFound = errors.New("not found resources")
+	// ErrConflict indicates that the resources conflicts with an existing one, HTTP code 409
+	ErrConflict = errors.New("conflict")
+)
+
+// maxErrorBodySize is the max length of the response body which is kept in HTTPError
+const maxErrorBodySize = 1024
+
+// HTTPError represents an unexpected HTTP response from Jenkins
+type HTTPError struct {
+	StatusCode int
+	Method     string
+	URL        string
+	Header     http.Header
+	// Body is an excerpt of the response body
+	Body string
+	// Message is the error message which is parsed from the Jenkins error page or JSON response
+	Message string
+	// StackTrace is the Java stack trace which comes from the Jenkins error page
+	StackTrace string
+}
+
+// NewHTTPError creates a HTTPError from the response and its body
+func NewHTTPError(response *http.Response, data []byte) (err *HTTPError) {
+	err = &HTTPError{}
+	if response != nil {
+		err.StatusCode = response.StatusCode
+		err.Header = response.Header
+		if response.Request != nil {
+			err.Method = response.Request.Method
+			if response.Request.URL != nil {
+				err.URL = response.Request.URL.String()
+			}
+		}
+	}
+	err.parseBody(data)
+	return
+}
+
+// Error returns the text of the error, it keeps the same format with the previous plain errors
+func (e *HTTPError) Error() (msg string) {
+	switch {
+	case e.StatusCode == http.StatusBadRequest:
+		msg = fmt.Sprintf("bad request, code %d", e.StatusCode)
+	case e.StatusCode == http.StatusNotFound:
+		msg = "not found resources"
+	case e.StatusCode >= 400 && e.StatusCode < 500:
+		msg = fmt.Sprintf("the current user has not permission, code %d", e.StatusCode)
+	default:
+		msg = fmt.Sprintf("unexpected status code: %d", e.StatusCode)
+	}
+
+	if e.Message != "" {
+		msg = fmt.Sprintf("%s, %s", msg, e.Message)
+	}
+	return
+}
+
+// Is reports whether the error matches one of the sentinel errors
+func (e *HTTPError) Is(target error) bool {
+	switch target {
+	case ErrBadRequest:
+		return e.StatusCode == http.StatusBadRequest
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrCrumbInvalid:
+		return e.StatusCode == http.StatusForbidden && e.isCrumbIssue()
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	}
+	return false
+}
+
+func (e *HTTPError) isCrumbIssue() bool {
+	return strings.Contains(e.Body, "No valid crumb") || strings.Contains(e.Message, "No valid crumb")
+}
+
+// jettyMessagePattern matches the message of Jetty error page, e.g. <h2>HTTP ERROR 404 Not Found</h2>
+var jettyMessagePattern = regexp.MustCompile(`(?s)<h2>(?:HTTP ERROR \d+\s*)?(.*?)</h2>`)
+
+// titlePattern matches the title of a HTML page
+var titlePattern = regexp.MustCompile(`(?s)<title>(.*?)</title>`)
+
+// tagPattern matches the HTML tags
+var tagPattern = regexp.MustCompile(`<[^>]*>`)
+
+// stackTracePattern matches a Java stack trace, e.g. java.lang.IllegalStateException: message\n\tat ...
+var stackTracePattern = regexp.MustCompile(`(?m)^[\w$.]+(?:Exception|Error)(?::[^\n]*)?\n(?:\s+(?:at |Caused by|\.\.\.)[^\n]*\n?)+`)
+
+// truncateText cuts the text to at most max bytes, it does not split a multi-byte character
+func truncateText(text string, max int) string {
+	if len(text) <= max {
+		return text
+	}
+	end := max
+	for end > 0 && !utf8.RuneStart(text[end]) {
+		end--
+	}
+	return text[:end]
+}
+
+func (e *HTTPError) parseBody(data []byte) {
+	body := string(data)
+	e.Body = truncateText(body, maxErrorBodySize)
+
+	if strings.TrimSpace(body) == "" {
+		return
+	}
+
+	// some APIs, such as BlueOcean, response the error as JSON
+	jsonErr := struct {
+		Message string `json:"message"`
+	}{}
+	if json.Unmarshal(data, &jsonErr) == nil && jsonErr.Message != "" {
+		e.Message = jsonErr.Message
+		return
+	}
+
+	text := html.UnescapeString(tagPattern.ReplaceAllString(body, "\n"))
+	if trace := stackTracePattern.FindString(text); trace != "" {
+		e.StackTrace = strings.TrimSpace(trace)
+		e.Message = strings.TrimSpace(strings.SplitN(e.StackTrace, "\n", 2)[0])
+		return
+	}
+
+	if matches := jettyMessagePattern.FindStringSubmatch(body); len(matches) > 1 {
+		e.Message = strings.TrimSpace(html.UnescapeString(matches[1]))
+	} else if matches = titlePattern.FindStringSubmatch(body); len(matches) > 1 {
+		e.Message = strings.TrimSpace(html.UnescapeString(matches[1]))
+	}
+}
+
+// IsNotFound returns true if the error indicates that the resources does not exist
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
+
+// IsForbidden returns true if the error indicates that the current user has no permission
+func IsForbidden(err error) bool {
+	return errors.Is(err, ErrForbidden)
+}
+
+// IsCrumbInvalid returns true if the error is caused by a missing or an expired crumb
+func IsCrumbInvalid(err error) bool {
+	return errors.Is(err, ErrCrumbInvalid)
+}
+
+// IsConflict returns true if the error indicates that the resources conflicts with an existing one
+func IsConflict(err error) bool {
+	return errors.Is(err, ErrConflict)
+}
@@ -0,0 +1,134 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jenkins-zh/jenkins-client/pkg/mock/mhttp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPError(t *testing.T) {
+	request, _ := http.NewRequest(http.MethodPost, "http://localhost/job/fake/build", nil)
+	tests := []struct {
+		name           string
+		statusCode     int
+		body           string
+		expectErr      string
+		expectMessage  string
+		expectTrace    bool
+		notFound       bool
+		forbidden      bool
+		crumbInvalid   bool
+		conflict       bool
+		expectedMethod string
+	}{{
+		name:       "not found without body",
+		statusCode: http.StatusNotFound,
+		expectErr:  "not found resources",
+		notFound:   true,
+	}, {
+		name:       "bad request",
+		statusCode: http.StatusBadRequest,
+		expectErr:  "bad request, code 400",
+	}, {
+		name:       "forbidden",
+		statusCode: http.StatusForbidden,
+		expectErr:  "the current user has not permission, code 403",
+		forbidden:  true,
+	}, {
+		name:          "invalid crumb",
+		statusCode:    http.StatusForbidden,
+		body:          `<html><body><h2>HTTP ERROR 403 No valid crumb was included in the request</h2></body></html>`,
+		expectErr:     "the current user has not permission, code 403, No valid crumb was included in the request",
+		expectMessage: "No valid crumb was included in the request",
+		forbidden:     true,
+		crumbInvalid:  true,
+	}, {
+		name:          "conflict with JSON message",
+		statusCode:    http.StatusConflict,
+		body:          `{"message":"the item already exists","code":409}`,
+		expectErr:     "the current user has not permission, code 409, the item already exists",
+		expectMessage: "the item already exists",
+		conflict:      true,
+	}, {
+		name:       "internal error with stack trace",
+		statusCode: http.StatusInternalServerError,
+		body: `<html><body><pre>java.lang.IllegalStateException: job &quot;fake&quot; is broken
+	at hudson.model.Job.doBuild(Job.java:10)
+	at java.base/java.lang.Thread.run(Thread.java:829)
+</pre></body></html>`,
+		expectErr:     `unexpected status code: 500, java.lang.IllegalStateException: job "fake" is broken`,
+		expectMessage: `java.lang.IllegalStateException: job "fake" is broken`,
+		expectTrace:   true,
+	}, {
+		name:          "bad gateway with a title",
+		statusCode:    http.StatusBadGateway,
+		body:          `<html><head><title>502 Bad Gateway</title></head></html>`,
+		expectErr:     "unexpected status code: 502, 502 Bad Gateway",
+		expectMessage: "502 Bad Gateway",
+	}}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := &http.Response{StatusCode: tt.statusCode, Request: request}
+			err := NewHTTPError(response, []byte(tt.body))
+			assert.Equal(t, tt.expectErr, err.Error(), "failed in case [%s]-[%d]", tt.name, i)
+			assert.Equal(t, tt.expectMessage, err.Message)
+			assert.Equal(t, tt.expectTrace, err.StackTrace != "")
+			assert.Equal(t, http.MethodPost, err.Method)
+			assert.Equal(t, "http://localhost/job/fake/build", err.URL)
+
+			wrapped := fmt.Errorf("failed to build: %w", err)
+			assert.Equal(t, tt.notFound, IsNotFound(wrapped))
+			assert.Equal(t, tt.forbidden, IsForbidden(wrapped))
+			assert.Equal(t, tt.crumbInvalid, IsCrumbInvalid(wrapped))
+			assert.Equal(t, tt.conflict, IsConflict(wrapped))
+
+			var httpErr *HTTPError
+			assert.True(t, errors.As(wrapped, &httpErr))
+			assert.Equal(t, tt.statusCode, httpErr.StatusCode)
+		})
+	}
+}
+
+func TestHTTPErrorBodyExcerpt(t *testing.T) {
+	err := NewHTTPError(&http.Response{StatusCode: http.StatusInternalServerError},
+		bytes.Repeat([]byte("a"), maxErrorBodySize*2))
+	assert.Equal(t, maxErrorBodySize, len(err.Body))
+
+	// the excerpt ends before the character which is across the limit
+	err = NewHTTPError(&http.Response{StatusCode: http.StatusInternalServerError},
+		append(bytes.Repeat([]byte("a"), maxErrorBodySize-1), []byte("中文")...))
+	assert.Equal(t, maxErrorBodySize-1, len(err.Body))
+	assert.True(t, utf8.ValidString(err.Body))
+}
+
+func TestRequestWithDataError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	roundTripper := mhttp.NewMockRoundTripper(ctrl)
+	jenkinsCore := &JenkinsCore{URL: "http://localhost", RoundTripper: roundTripper}
+
+	request, _ := http.NewRequest(http.MethodGet, "http://localhost/job/fake/api/json", nil)
+	roundTripper.EXPECT().RoundTrip(NewRequestMatcher(request)).Return(&http.Response{
+		StatusCode: http.StatusNotFound,
+		Header:     http.Header{"X-Jenkins": []string{"2.332"}},
+		Body:       ioutil.NopCloser(bytes.NewBufferString("")),
+	}, nil)
+
+	err := jenkinsCore.RequestWithData(http.MethodGet, "/job/fake/api/json", nil, nil, 200, nil)
+	assert.True(t, IsNotFound(err))
+
+	var httpErr *HTTPError
+	if assert.True(t, errors.As(err, &httpErr)) {
+		assert.Equal(t, http.MethodGet, httpErr.Method)
+		assert.Equal(t, "http://localhost/job/fake/api/json", httpErr.URL)
+		assert.Equal(t, "2.332", httpErr.Header.Get("X-Jenkins"))
+	}
+}
@@ -0,0 +1,103 @@
+package core
+
+import (
+	"context"
+	"iter"
+)
+
+// DefaultPageSize is the page size of the iterator if it's not specified
+const DefaultPageSize = 100
+
+// PageFetcher fetches the items from start, the count of the items should not be greater than limit.
+// The page which has less items than limit is considered as the last one.
+type PageFetcher[T any] func(ctx context.Context, start, limit int) ([]T, error)
+
+// Iterator walks through all the items of a paginated API lazily, a new page is fetched only when it's needed.
+//
+//	it := core.NewIterator(ctx, 50, fetch)
+//	for it.Next() {
+//		item := it.Item()
+//	}
+//	err := it.Err()
+type Iterator[T any] struct {
+	ctx      context.Context
+	fetch    PageFetcher[T]
+	pageSize int
+
+	start    int
+	page     []T
+	index    int
+	lastPage bool
+	item     T
+	err      error
+}
+
+// NewIterator creates an iterator, the DefaultPageSize is used if the pageSize is not positive
+func NewIterator[T any](ctx context.Context, pageSize int, fetch PageFetcher[T]) *Iterator[T] {
+	if pageSize <= 0 {
+		pageSize = DefaultPageSize
+	}
+	return &Iterator[T]{ctx: ctx, fetch: fetch, pageSize: pageSize}
+}
+
+// Next moves to the next item, it returns false if there are no more items or an error happens
+func (it *Iterator[T]) Next() bool {
+	if it.err != nil {
+		return false
+	}
+
+	if it.index >= len(it.page) {
+		if it.lastPage {
+			return false
+		}
+		if it.err = it.ctx.Err(); it.err != nil {
+			return false
+		}
+
+		var page []T
+		if page, it.err = it.fetch(it.ctx, it.start, it.pageSize); it.err != nil {
+			return false
+		}
+		it.page, it.index = page, 0
+		it.start += len(page)
+		it.lastPage = len(page) < it.pageSize
+		if len(page) == 0 {
+			return false
+		}
+	}
+
+	it.item = it.page[it.index]
+	it.index++
+	return true
+}
+
+// Item returns the current item
+func (it *Iterator[T]) Item() T {
+	return it.item
+}
+
+// Err returns the error which stopped the iteration
+func (it *Iterator[T]) Err() error {
+	return it.err
+}
+
+// All returns the items as a sequence for the range-over-func loop.
+// Breaking the loop stops fetching the following pages, check Err once the loop is over.
+func (it *Iterator[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for it.Next() {
+			if !yield(it.Item()) {
+				return
+			}
+		}
+	}
+}
+
+// Collect fetches all the remaining items
+func (it *Iterator[T]) Collect() (items []T, err error) {
+	for it.Next() {
+		items = append(items, it.Item())
+	}
+	err = it.Err()
+	return
+}
@@ -0,0 +1,98 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newFakeFetcher returns a fetcher over the given count of items, and counts the fetched pages
+func newFakeFetcher(total int, pages *int) PageFetcher[int] {
+	return func(ctx context.Context, start, limit int) (items []int, err error) {
+		*pages++
+		for i := start; i < total && i < start+limit; i++ {
+			items = append(items, i)
+		}
+		return
+	}
+}
+
+func TestIterator(t *testing.T) {
+	tests := []struct {
+		name      string
+		total     int
+		pageSize  int
+		wantPages int
+	}{{
+		name:      "empty result",
+		total:     0,
+		pageSize:  10,
+		wantPages: 1,
+	}, {
+		name:      "the last page is not full",
+		total:     25,
+		pageSize:  10,
+		wantPages: 3,
+	}, {
+		name:      "the last page is full",
+		total:     20,
+		pageSize:  10,
+		wantPages: 3,
+	}, {
+		name:      "the default page size",
+		total:     120,
+		pageSize:  0,
+		wantPages: 2,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pages := 0
+			items, err := NewIterator(context.Background(), tt.pageSize, newFakeFetcher(tt.total, &pages)).Collect()
+			assert.Nil(t, err)
+			assert.Equal(t, tt.total, len(items))
+			for i, item := range items {
+				assert.Equal(t, i, item)
+			}
+			assert.Equal(t, tt.wantPages, pages)
+		})
+	}
+}
+
+func TestIteratorStopEarly(t *testing.T) {
+	pages := 0
+	it := NewIterator(context.Background(), 10, newFakeFetcher(100, &pages))
+	var items []int
+	for item := range it.All() {
+		if item == 12 {
+			break
+		}
+		items = append(items, item)
+	}
+	assert.Nil(t, it.Err())
+	assert.Equal(t, 12, len(items))
+	assert.Equal(t, 2, pages)
+}
+
+func TestIteratorError(t *testing.T) {
+	fakeErr := errors.New("fake")
+	it := NewIterator(context.Background(), 2, func(ctx context.Context, start, limit int) ([]int, error) {
+		if start > 0 {
+			return nil, fakeErr
+		}
+		return []int{1, 2}, nil
+	})
+	items, err := it.Collect()
+	assert.Equal(t, []int{1, 2}, items)
+	assert.Equal(t, fakeErr, err)
+	assert.False(t, it.Next())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	pages := 0
+	it = NewIterator(ctx, 2, newFakeFetcher(10, &pages))
+	assert.False(t, it.Next())
+	assert.True(t, errors.Is(it.Err(), context.Canceled))
+	assert.Equal(t, 0, pages)
+}
@@ -0,0 +1,77 @@
+package core
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+)
+
+// RequestIDHeader carries the request ID, then the log lines of the client could be matched with the Jenkins side
+const RequestIDHeader = "X-Request-ID"
+
+type requestIDKey struct{}
+
+// WithRequestID sets the request ID of the requests with this context.
+// All the attempts (retries and crumb refreshes) of a request share the same ID.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the request ID of a context, it's empty if there is no request ID
+func RequestIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// ensureRequestID returns a context with a request ID, a new one is generated if there is no request ID
+func ensureRequestID(ctx context.Context) context.Context {
+	if RequestIDFromContext(ctx) != "" {
+		return ctx
+	}
+	return WithRequestID(ctx, newRequestID())
+}
+
+var requestIDSequence uint64
+
+// newRequestID returns a random request ID, it falls back to a sequence if there is no random source
+func newRequestID() string {
+	data := make([]byte, 8)
+	if _, err := rand.Read(data); err != nil {
+		return fmt.Sprintf("%016x", atomic.AddUint64(&requestIDSequence, 1))
+	}
+	return hex.EncodeToString(data)
+}
+
+// GetLogger returns the logger of this client, the global Logger is used if it's nil.
+// The log lines carry the URL of Jenkins, then the logs of different clients could be told apart.
+func (j *JenkinsCore) GetLogger() *zap.Logger {
+	logger := j.Logger
+	if logger == nil {
+		logger = Logger
+	}
+	return logger.With(zap.String("jenkins", j.URL))
+}
+
+// loggerOf returns the logger of this client with the request ID of the context
+func (j *JenkinsCore) loggerOf(ctx context.Context) *zap.Logger {
+	logger := j.GetLogger()
+	if id := RequestIDFromContext(ctx); id != "" {
+		logger = logger.With(zap.String("requestID", id))
+	}
+	return logger
+}
+
+// GetLanguage returns the Accept-Language of this client, the global one is used if it's empty
+func (j *JenkinsCore) GetLanguage() string {
+	if j.Language != "" {
+		return j.Language
+	}
+	return language
+}
@@ -0,0 +1,92 @@
+package core
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestPerClientLogger(t *testing.T) {
+	var (
+		mutex      sync.Mutex
+		requestIDs []string
+		languages  []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		requestIDs = append(requestIDs, r.Header.Get(RequestIDHeader))
+		languages = append(languages, r.Header.Get("Accept-Language"))
+		count := len(requestIDs)
+		mutex.Unlock()
+		if count == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	core, logs := observer.New(zapcore.DebugLevel)
+	jenkinsCore := newRetryCore(server.URL)
+	jenkinsCore.Logger = zap.New(core)
+	jenkinsCore.Language = "zh-CN"
+	jenkinsCore.RetryPolicy.InitialInterval = time.Millisecond
+
+	ctx := WithRequestID(context.Background(), "fake-id")
+	_, _, err := jenkinsCore.RequestWithContext(ctx, http.MethodGet, "/api/json", nil, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"fake-id", "fake-id"}, requestIDs, "the retries share the request ID")
+	assert.Equal(t, []string{"zh-CN", "zh-CN"}, languages)
+
+	retries := logs.FilterMessage("retry HTTP request").All()
+	if assert.Equal(t, 1, len(retries)) {
+		fields := retries[0].ContextMap()
+		assert.Equal(t, server.URL, fields["jenkins"])
+		assert.Equal(t, "fake-id", fields["requestID"])
+	}
+
+	t.Run("generated request ID", func(t *testing.T) {
+		_, _, err := jenkinsCore.Request(http.MethodGet, "/api/json", nil, nil)
+		assert.Nil(t, err)
+		id := requestIDs[len(requestIDs)-1]
+		assert.Equal(t, 16, len(id))
+
+		sends := logs.FilterMessage("send HTTP request").All()
+		assert.Equal(t, id, sends[len(sends)-1].ContextMap()["requestID"])
+	})
+
+	t.Run("with a custom RoundTripper", func(t *testing.T) {
+		customCore := &JenkinsCore{URL: server.URL, RoundTripper: http.DefaultTransport}
+		response, err := customCore.RequestWithResponseWithContext(ctx, http.MethodGet, "/api/json", nil, nil)
+		if assert.Nil(t, err) {
+			_ = response.Body.Close()
+		}
+		assert.Equal(t, "fake-id", requestIDs[len(requestIDs)-1])
+	})
+
+	t.Run("language of RequestWithResponse", func(t *testing.T) {
+		response, err := jenkinsCore.RequestWithResponse(http.MethodGet, "/api/json", nil, nil)
+		if assert.Nil(t, err) {
+			_ = response.Body.Close()
+		}
+		assert.Equal(t, "zh-CN", languages[len(languages)-1])
+	})
+
+	t.Run("fallback to the globals", func(t *testing.T) {
+		SetLanguage("en")
+		defer SetLanguage("")
+
+		globalCore := &JenkinsCore{URL: server.URL}
+		assert.Equal(t, "en", globalCore.GetLanguage())
+		assert.NotNil(t, globalCore.GetLogger())
+		_, _, err := globalCore.Request(http.MethodGet, "/api/json", nil, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, "en", languages[len(languages)-1])
+	})
+}
@@ -0,0 +1,177 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// DefaultBuckets are the upper bounds (in seconds) of the request duration histogram
+var DefaultBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// metricLabels are the labels of the request metrics
+type metricLabels struct {
+	method string
+	path   string
+	code   string
+}
+
+// histogram is a Prometheus-style histogram
+type histogram struct {
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+// Metrics is an Observer which collects Prometheus-style metrics of the requests.
+// It could be exposed via the Prometheus text format by WriteTo or as a http.Handler.
+type Metrics struct {
+	// Namespace is the prefix of the metric names
+	Namespace string
+	// Buckets are the upper bounds of the duration histogram, they must be sorted
+	Buckets []float64
+
+	mutex     sync.Mutex
+	requests  map[metricLabels]uint64
+	bytes     map[metricLabels]uint64
+	durations map[metricLabels]*histogram
+	cache     map[metricLabels]uint64
+}
+
+// NewMetrics creates a Metrics observer with the default namespace and buckets
+func NewMetrics() *Metrics {
+	return &Metrics{
+		Namespace: "jenkins_client",
+		Buckets:   DefaultBuckets,
+	}
+}
+
+// BeforeRequest does nothing
+func (m *Metrics) BeforeRequest(ctx context.Context, _ *RequestEvent) context.Context {
+	return ctx
+}
+
+// AfterRequest records the request
+func (m *Metrics) AfterRequest(_ context.Context, event *RequestEvent) {
+	labels := metricLabels{method: event.Method, path: event.Path, code: "error"}
+	if event.StatusCode > 0 {
+		labels.code = strconv.Itoa(event.StatusCode)
+	}
+	durationLabels := metricLabels{method: event.Method, path: event.Path}
+	seconds := event.Duration.Seconds()
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.requests == nil {
+		m.requests = map[metricLabels]uint64{}
+		m.bytes = map[metricLabels]uint64{}
+		m.durations = map[metricLabels]*histogram{}
+		m.cache = map[metricLabels]uint64{}
+	}
+
+	m.requests[labels]++
+	m.bytes[durationLabels] += uint64(event.BytesRead)
+
+	h, ok := m.durations[durationLabels]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(m.Buckets))}
+		m.durations[durationLabels] = h
+	}
+	for i, bound := range m.Buckets {
+		if seconds <= bound {
+			h.counts[i]++
+		}
+	}
+	h.sum += seconds
+	h.count++
+
+	if event.CacheStatus != "" {
+		m.cache[metricLabels{method: event.Method, path: event.Path, code: event.CacheStatus}]++
+	}
+}
+
+// RequestCount returns the count of the requests with the labels, the code is "error" for the failed requests
+func (m *Metrics) RequestCount(method, path, code string) uint64 {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.requests[metricLabels{method: method, path: path, code: code}]
+}
+
+// CacheCount returns the count of the requests with the cache status, such as HIT
+func (m *Metrics) CacheCount(method, path, status string) uint64 {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.cache[metricLabels{method: method, path: path, code: status}]
+}
+
+// WriteTo writes the metrics in the Prometheus text format
+func (m *Metrics) WriteTo(w io.Writer) (n int64, err error) {
+	buf := &bytes.Buffer{}
+
+	m.mutex.Lock()
+	name := m.Namespace + "_requests_total"
+	fmt.Fprintf(buf, "# HELP %s The count of the HTTP requests to Jenkins.\n# TYPE %s counter\n", name, name)
+	for _, labels := range sortLabels(m.requests) {
+		fmt.Fprintf(buf, "%s{method=%q,path=%q,code=%q} %d\n", name, labels.method, labels.path, labels.code,
+			m.requests[labels])
+	}
+
+	name = m.Namespace + "_response_bytes_total"
+	fmt.Fprintf(buf, "# HELP %s The bytes of the HTTP responses from Jenkins.\n# TYPE %s counter\n", name, name)
+	for _, labels := range sortLabels(m.bytes) {
+		fmt.Fprintf(buf, "%s{method=%q,path=%q} %d\n", name, labels.method, labels.path, m.bytes[labels])
+	}
+
+	name = m.Namespace + "_request_duration_seconds"
+	fmt.Fprintf(buf, "# HELP %s The duration of the HTTP requests to Jenkins.\n# TYPE %s histogram\n", name, name)
+	for _, labels := range sortLabels(m.durations) {
+		h := m.durations[labels]
+		for i, bound := range m.Buckets {
+			fmt.Fprintf(buf, "%s_bucket{method=%q,path=%q,le=%q} %d\n", name, labels.method, labels.path,
+				strconv.FormatFloat(bound, 'g', -1, 64), h.counts[i])
+		}
+		fmt.Fprintf(buf, "%s_bucket{method=%q,path=%q,le=\"+Inf\"} %d\n", name, labels.method, labels.path, h.count)
+		fmt.Fprintf(buf, "%s_sum{method=%q,path=%q} %g\n", name, labels.method, labels.path, h.sum)
+		fmt.Fprintf(buf, "%s_count{method=%q,path=%q} %d\n", name, labels.method, labels.path, h.count)
+	}
+
+	if len(m.cache) > 0 {
+		name = m.Namespace + "_cache_requests_total"
+		fmt.Fprintf(buf, "# HELP %s The count of the requests which go through the response cache.\n# TYPE %s counter\n", name, name)
+		for _, labels := range sortLabels(m.cache) {
+			fmt.Fprintf(buf, "%s{method=%q,path=%q,status=%q} %d\n", name, labels.method, labels.path, labels.code,
+				m.cache[labels])
+		}
+	}
+	m.mutex.Unlock()
+
+	return buf.WriteTo(w)
+}
+
+// ServeHTTP exposes the metrics for Prometheus
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	_, _ = m.WriteTo(w)
+}
+
+// sortLabels returns the sorted keys of the map, then the output is stable
+func sortLabels[V any](values map[metricLabels]V) (labels []metricLabels) {
+	for key := range values {
+		labels = append(labels, key)
+	}
+	sort.Slice(labels, func(i, j int) bool {
+		if labels[i].path != labels[j].path {
+			return labels[i].path < labels[j].path
+		}
+		if labels[i].method != labels[j].method {
+			return labels[i].method < labels[j].method
+		}
+		return labels[i].code < labels[j].code
+	})
+	return
+}
@@ -0,0 +1,177 @@
+package core
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RequestEvent describes a HTTP request which is sent to Jenkins
+type RequestEvent struct {
+	Method string
+	// URL is redacted by SensitiveKeys without the user info, then it's safe to be exported
+	URL string
+	// Path is the API path template, such as /job/{name}/{number}/api/json
+	Path  string
+	Start time.Time
+
+	// the following fields are available after the request is done
+	StatusCode int
+	// Duration is the time from sending the request to reading the whole response body
+	Duration  time.Duration
+	BytesRead int64
+	Err       error
+	// CacheStatus is one of HIT, REVALIDATED, and MISS if the response cache is enabled
+	CacheStatus string
+	// RequestID is shared by all the attempts of a request, it's sent as the X-Request-ID header
+	RequestID string
+}
+
+// Observer is notified before and after each HTTP request, it's useful for the metrics and tracing
+type Observer interface {
+	// BeforeRequest is called before sending the request, the returned context is used for the request
+	BeforeRequest(ctx context.Context, event *RequestEvent) context.Context
+	// AfterRequest is called once the response body is read completely or closed, or the request failed
+	AfterRequest(ctx context.Context, event *RequestEvent)
+}
+
+type pathTemplateKey struct{}
+
+// WithPathTemplate sets the API path template of the requests with this context for the observers
+func WithPathTemplate(ctx context.Context, template string) context.Context {
+	return context.WithValue(ctx, pathTemplateKey{}, template)
+}
+
+// namedSegments are the path segments which are followed by a name or an ID
+var namedSegments = map[string]string{
+	"job":           "{name}",
+	"view":          "{name}",
+	"computer":      "{name}",
+	"user":          "{name}",
+	"plugin":        "{name}",
+	"organizations": "{name}",
+	"pipelines":     "{name}",
+	"branches":      "{name}",
+	"runs":          "{id}",
+	"nodes":         "{id}",
+	"steps":         "{id}",
+	"store":         "{name}",
+	"domain":        "{name}",
+	"credential":    "{id}",
+}
+
+var numberPattern = regexp.MustCompile(`^\d+$`)
+
+// PathTemplate turns an API path into a template by replacing the names and numbers,
+// then the metrics could be grouped by the endpoint
+func PathTemplate(api string) string {
+	if index := strings.IndexAny(api, "?#"); index >= 0 {
+		api = api[:index]
+	}
+
+	segments := strings.Split(api, "/")
+	for i := 1; i < len(segments); i++ {
+		if segments[i] == "" {
+			continue
+		}
+		if placeholder, ok := namedSegments[segments[i-1]]; ok {
+			segments[i] = placeholder
+		} else if numberPattern.MatchString(segments[i]) {
+			segments[i] = "{number}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// observedRoundTripper notifies the observers for each request
+type observedRoundTripper struct {
+	next      http.RoundTripper
+	observers []Observer
+	// baseURL is trimmed from the request URL to get the API path
+	baseURL string
+}
+
+func (j *JenkinsCore) observe(roundTripper http.RoundTripper) http.RoundTripper {
+	if len(j.Observers) == 0 {
+		return roundTripper
+	}
+	return &observedRoundTripper{next: roundTripper, observers: j.Observers, baseURL: j.URL}
+}
+
+// RoundTrip sends the request, then notifies the observers
+func (o *observedRoundTripper) RoundTrip(request *http.Request) (response *http.Response, err error) {
+	event := &RequestEvent{
+		Method: request.Method,
+		URL:    RedactURL(request.URL, SensitiveKeys),
+		Start:  time.Now(),
+
+		RequestID: RequestIDFromContext(request.Context()),
+	}
+	if template, ok := request.Context().Value(pathTemplateKey{}).(string); ok {
+		event.Path = template
+	} else {
+		rawURL := request.URL.String()
+		api := strings.TrimPrefix(rawURL, strings.TrimSuffix(o.baseURL, "/"))
+		if api == rawURL {
+			api = request.URL.Path
+		}
+		event.Path = PathTemplate(api)
+	}
+
+	ctx := request.Context()
+	for _, observer := range o.observers {
+		ctx = observer.BeforeRequest(ctx, event)
+	}
+	if ctx != request.Context() {
+		request = request.WithContext(ctx)
+	}
+
+	done := func() {
+		event.Duration = time.Since(event.Start)
+		for _, observer := range o.observers {
+			observer.AfterRequest(ctx, event)
+		}
+	}
+
+	if response, err = o.next.RoundTrip(request); err != nil {
+		event.Err = err
+		done()
+		return
+	}
+	event.StatusCode = response.StatusCode
+	event.CacheStatus = response.Header.Get(CacheStatusHeader)
+	response.Body = &observedBody{ReadCloser: response.Body, event: event, done: done}
+	return
+}
+
+// observedBody counts the bytes of the response body, then notifies the observers once it's done
+type observedBody struct {
+	io.ReadCloser
+	event *RequestEvent
+	done  func()
+	once  sync.Once
+}
+
+// Read reads the body and counts the bytes
+func (b *observedBody) Read(p []byte) (n int, err error) {
+	n, err = b.ReadCloser.Read(p)
+	b.event.BytesRead += int64(n)
+	if err == io.EOF {
+		b.once.Do(b.done)
+	} else if err != nil {
+		b.event.Err = err
+		b.once.Do(b.done)
+	}
+	return
+}
+
+// Close closes the body, then notifies the observers if the body is not read completely
+func (b *observedBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.done)
+	return err
+}
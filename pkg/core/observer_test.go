@@ -0,0 +1,188 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPathTemplate(t *testing.T) {
+	tests := []struct {
+		api    string
+		expect string
+	}{{
+		api:    "/api/json",
+		expect: "/api/json",
+	}, {
+		api:    "/job/folder/job/fake/12/api/json?tree=name",
+		expect: "/job/{name}/job/{name}/{number}/api/json",
+	}, {
+		api:    "/computer/agent/launchSlaveAgent",
+		expect: "/computer/{name}/launchSlaveAgent",
+	}, {
+		api:    "/blue/rest/organizations/jenkins/pipelines/fake/runs/1/",
+		expect: "/blue/rest/organizations/{name}/pipelines/{name}/runs/{id}/",
+	}, {
+		api:    "/queue/cancelItem?id=3",
+		expect: "/queue/cancelItem",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.api, func(t *testing.T) {
+			assert.Equal(t, tt.expect, PathTemplate(tt.api))
+		})
+	}
+}
+
+type fakeObserver struct {
+	before []RequestEvent
+	after  []RequestEvent
+}
+
+func (o *fakeObserver) BeforeRequest(ctx context.Context, event *RequestEvent) context.Context {
+	o.before = append(o.before, *event)
+	return ctx
+}
+
+func (o *fakeObserver) AfterRequest(_ context.Context, event *RequestEvent) {
+	o.after = append(o.after, *event)
+}
+
+func newObservedServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/missing") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte("fake body"))
+	}))
+}
+
+func TestObserver(t *testing.T) {
+	server := newObservedServer()
+	defer server.Close()
+
+	observer := &fakeObserver{}
+	jenkinsCore := &JenkinsCore{URL: server.URL, Observers: []Observer{observer}}
+
+	_, _, err := jenkinsCore.Request(http.MethodGet, "/job/fake/1/api/json", nil, nil)
+	assert.Nil(t, err)
+	_, _, err = jenkinsCore.RequestWithContext(WithPathTemplate(context.Background(), "/missing"),
+		http.MethodGet, "/missing/fake", nil, nil)
+	assert.Nil(t, err)
+
+	if assert.Equal(t, 2, len(observer.after)) {
+		assert.Equal(t, "/job/{name}/{number}/api/json", observer.before[0].Path)
+		assert.Equal(t, http.StatusOK, observer.after[0].StatusCode)
+		assert.Equal(t, int64(len("fake body")), observer.after[0].BytesRead)
+		assert.True(t, observer.after[0].Duration > 0)
+
+		assert.Equal(t, "/missing", observer.after[1].Path)
+		assert.Equal(t, http.StatusNotFound, observer.after[1].StatusCode)
+	}
+
+	// the network error
+	server.Close()
+	_, _, err = jenkinsCore.Request(http.MethodGet, "/api/json", nil, nil)
+	assert.NotNil(t, err)
+	if assert.Equal(t, 3, len(observer.after)) {
+		assert.NotNil(t, observer.after[2].Err)
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	server := newObservedServer()
+	defer server.Close()
+
+	metrics := NewMetrics()
+	jenkinsCore := &JenkinsCore{URL: server.URL, Observers: []Observer{metrics}}
+	for i := 0; i < 2; i++ {
+		_, _, err := jenkinsCore.Request(http.MethodGet, "/job/fake/api/json", nil, nil)
+		assert.Nil(t, err)
+	}
+	_, _, err := jenkinsCore.Request(http.MethodGet, "/missing", nil, nil)
+	assert.Nil(t, err)
+
+	assert.Equal(t, uint64(2), metrics.RequestCount(http.MethodGet, "/job/{name}/api/json", "200"))
+	assert.Equal(t, uint64(1), metrics.RequestCount(http.MethodGet, "/missing", "404"))
+
+	buf := &bytes.Buffer{}
+	_, err = metrics.WriteTo(buf)
+	assert.Nil(t, err)
+	output := buf.String()
+	assert.Contains(t, output, "# TYPE jenkins_client_requests_total counter")
+	assert.Contains(t, output, `jenkins_client_requests_total{method="GET",path="/job/{name}/api/json",code="200"} 2`)
+	assert.Contains(t, output, `jenkins_client_response_bytes_total{method="GET",path="/job/{name}/api/json"} 18`)
+	assert.Contains(t, output, `jenkins_client_request_duration_seconds_bucket{method="GET",path="/missing",le="+Inf"} 1`)
+	assert.Contains(t, output, `jenkins_client_request_duration_seconds_count{method="GET",path="/job/{name}/api/json"} 2`)
+
+	recorder := httptest.NewRecorder()
+	metrics.ServeHTTP(recorder, nil)
+	assert.Equal(t, output, recorder.Body.String())
+}
+
+type fakeSpan struct {
+	name       string
+	attributes map[string]interface{}
+	err        error
+	ended      bool
+}
+
+func (s *fakeSpan) SetAttribute(key string, value interface{}) {
+	s.attributes[key] = value
+}
+
+func (s *fakeSpan) RecordError(err error) {
+	s.err = err
+}
+
+func (s *fakeSpan) End() {
+	s.ended = true
+}
+
+type fakeTracer struct {
+	spans []*fakeSpan
+}
+
+func (t *fakeTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	span := &fakeSpan{name: name, attributes: map[string]interface{}{}}
+	t.spans = append(t.spans, span)
+	return ctx, span
+}
+
+func TestTracingObserver(t *testing.T) {
+	server := newObservedServer()
+	defer server.Close()
+
+	tracer := &fakeTracer{}
+	jenkinsCore := &JenkinsCore{URL: server.URL, Observers: []Observer{NewTracingObserver(tracer)}}
+	_, _, err := jenkinsCore.Request(http.MethodGet, "/computer/agent/api/json", nil, nil)
+	assert.Nil(t, err)
+
+	if assert.Equal(t, 1, len(tracer.spans)) {
+		span := tracer.spans[0]
+		assert.Equal(t, "GET /computer/{name}/api/json", span.name)
+		assert.Equal(t, http.StatusOK, span.attributes["http.status_code"])
+		assert.Equal(t, "/computer/{name}/api/json", span.attributes["http.route"])
+		assert.Nil(t, span.err)
+		assert.True(t, span.ended)
+	}
+
+	// the credentials are not exported to the spans
+	target, err := url.Parse(server.URL)
+	assert.Nil(t, err)
+	target.User = url.UserPassword("admin", "password")
+	jenkinsCore.URL = target.String()
+	_, _, err = jenkinsCore.Request(http.MethodGet, "/job/app/build?token=secret&delay=0", nil, nil)
+	assert.Nil(t, err)
+	if assert.Equal(t, 2, len(tracer.spans)) {
+		span := tracer.spans[1]
+		assert.Equal(t, server.URL+"/job/app/build?delay=0&token=%5BREDACTED%5D", span.attributes["http.url"])
+		assert.Equal(t, "/job/{name}/build", span.attributes["http.route"])
+	}
+}
@@ -0,0 +1,73 @@
+package core
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Redacted replaces the secrets, such as the ones in the audit entries
+const Redacted = "[REDACTED]"
+
+// SensitiveKeys are the keywords of the query parameters, form fields, and JSON fields which are redacted
+var SensitiveKeys = []string{"token", "password", "secret", "passphrase", "privateKey"}
+
+// IsSensitiveKey returns true if the name of a field contains one of the keywords
+func IsSensitiveKey(key string, keywords []string) bool {
+	key = strings.ToLower(key)
+	for _, keyword := range keywords {
+		if strings.Contains(key, strings.ToLower(keyword)) {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactValues replaces the values of the sensitive fields, and the sensitive JSON fields in the other values
+func RedactValues(values url.Values, keywords []string) {
+	for key := range values {
+		for i := range values[key] {
+			if IsSensitiveKey(key, keywords) {
+				values[key][i] = Redacted
+			} else {
+				values[key][i] = RedactJSON(values[key][i], keywords)
+			}
+		}
+	}
+}
+
+// RedactURL returns the URL without the user info, and redacts the sensitive query parameters
+func RedactURL(target *url.URL, keywords []string) string {
+	redacted := *target
+	redacted.User = nil
+	if redacted.RawQuery != "" {
+		query := redacted.Query()
+		RedactValues(query, keywords)
+		redacted.RawQuery = query.Encode()
+	}
+	return redacted.String()
+}
+
+// RedactBody redacts the sensitive fields of a form or the sensitive JSON fields of the other content
+func RedactBody(body, contentType string, keywords []string) string {
+	if strings.HasPrefix(contentType, "application/x-www-form-urlencoded") {
+		if values, err := url.ParseQuery(body); err == nil {
+			RedactValues(values, keywords)
+			return values.Encode()
+		}
+	}
+	return RedactJSON(body, keywords)
+}
+
+var jsonFieldPattern = regexp.MustCompile(`"([^"]+)"(\s*:\s*)"(?:[^"\\]|\\.)*"`)
+
+// RedactJSON replaces the values of the sensitive JSON string fields
+func RedactJSON(text string, keywords []string) string {
+	return jsonFieldPattern.ReplaceAllStringFunc(text, func(field string) string {
+		groups := jsonFieldPattern.FindStringSubmatch(field)
+		if !IsSensitiveKey(groups[1], keywords) {
+			return field
+		}
+		return `"` + groups[1] + `"` + groups[2] + `"` + Redacted + `"`
+	})
+}
@@ -18,8 +18,9 @@ type RequestMatcher struct {
 }
 
 type matchOptions struct {
-	withQuery bool
-	withBody  bool
+	withQuery     bool
+	withBody      bool
+	withoutHeader bool
 }
 
 // NewRequestMatcher create a request matcher will match request method and request path
@@ -44,6 +45,12 @@ func (matcher *RequestMatcher) WithBody() *RequestMatcher {
 	return matcher
 }
 
+// WithoutHeader returns a matcher which ignores the headers
+func (matcher *RequestMatcher) WithoutHeader() *RequestMatcher {
+	matcher.matchOptions.withoutHeader = true
+	return matcher
+}
+
 // Matches returns a matcher with given function
 func (matcher *RequestMatcher) Matches(x interface{}) bool {
 	target := x.(*http.Request)
@@ -54,7 +61,7 @@ func (matcher *RequestMatcher) Matches(x interface{}) bool {
 		request.URL.Path == target.URL.Path &&
 		request.URL.Opaque == target.URL.Opaque
 
-	if match {
+	if !matcher.matchOptions.withoutHeader && match {
 		match = matchHeader(request.Header, target.Header)
 	}
 
@@ -71,7 +78,12 @@ func (matcher *RequestMatcher) Matches(x interface{}) bool {
 	return match
 }
 
+// matchHeader compares the headers, the generated request ID is ignored unless it's expected
 func matchHeader(left, right http.Header) bool {
+	if left.Get(RequestIDHeader) == "" && right.Get(RequestIDHeader) != "" {
+		right = right.Clone()
+		right.Del(RequestIDHeader)
+	}
 	if len(left) != len(right) {
 		return false
 	}
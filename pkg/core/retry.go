@@ -0,0 +1,153 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// RetryPolicy represents how to retry the failed requests.
+// Only the GET, HEAD requests, and the ones marked as idempotent will be retried.
+type RetryPolicy struct {
+	// MaxAttempts is the max times of sending a request, including the first one
+	MaxAttempts int
+	// InitialInterval is the waiting duration before the first retry
+	InitialInterval time.Duration
+	// MaxInterval is the upper limit of the waiting duration
+	MaxInterval time.Duration
+	// Multiplier is the factor for increasing the waiting duration after each retry
+	Multiplier float64
+	// Jitter is the randomization factor of the waiting duration, the range is [0, 1]
+	Jitter float64
+	// RetryableStatusCodes are the HTTP status codes which should be retried
+	RetryableStatusCodes []int
+	// RetryOnNetworkError indicates if retry the request when a network error happens
+	RetryOnNetworkError bool
+}
+
+// DefaultRetryPolicy returns a retry policy which fits most of the cases, such as Jenkins is restarting
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:          3,
+		InitialInterval:      500 * time.Millisecond,
+		MaxInterval:          10 * time.Second,
+		Multiplier:           2,
+		Jitter:               0.2,
+		RetryableStatusCodes: []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout},
+		RetryOnNetworkError:  true,
+	}
+}
+
+type idempotentKey struct{}
+
+// WithIdempotent marks all the requests with this context as idempotent, so they could be retried
+func WithIdempotent(ctx context.Context) context.Context {
+	return context.WithValue(ctx, idempotentKey{}, true)
+}
+
+func isIdempotent(ctx context.Context, method string) bool {
+	if method == http.MethodGet || method == http.MethodHead {
+		return true
+	}
+	idempotent, _ := ctx.Value(idempotentKey{}).(bool)
+	return idempotent
+}
+
+// shouldRetry checks if the request should be sent again according to the response or error
+func (p *RetryPolicy) shouldRetry(ctx context.Context, response *http.Response, err error) bool {
+	if err != nil {
+		return p.RetryOnNetworkError && ctx.Err() == nil
+	}
+	for _, code := range p.RetryableStatusCodes {
+		if response.StatusCode == code {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff returns the waiting duration before the next attempt
+func (p *RetryPolicy) backoff(attempt int, response *http.Response) (interval time.Duration) {
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+	interval = time.Duration(float64(p.InitialInterval) * math.Pow(multiplier, float64(attempt-1)))
+	if p.Jitter > 0 {
+		delta := p.Jitter * float64(interval)
+		interval = time.Duration(float64(interval) - delta + rand.Float64()*2*delta)
+	}
+
+	// respect the waiting duration which is asked by the server
+	if response != nil {
+		if seconds, err := strconv.Atoi(response.Header.Get("Retry-After")); err == nil {
+			if retryAfter := time.Duration(seconds) * time.Second; retryAfter > interval {
+				interval = retryAfter
+			}
+		}
+	}
+
+	if p.MaxInterval > 0 && interval > p.MaxInterval {
+		interval = p.MaxInterval
+	}
+	return
+}
+
+// retry sends the request via the send function until it succeeds or reaches the max attempts
+func (j *JenkinsCore) retry(ctx context.Context, method string, payload io.Reader,
+	send func(payload io.Reader) (*http.Response, error)) (response *http.Response, err error) {
+	policy := j.RetryPolicy
+	if policy == nil || policy.MaxAttempts <= 1 || !isIdempotent(ctx, method) {
+		return send(payload)
+	}
+
+	// keep the payload in memory, then it could be replayed
+	var data []byte
+	if payload != nil {
+		if data, err = ioutil.ReadAll(payload); err != nil {
+			return
+		}
+	}
+
+	for attempt := 1; ; attempt++ {
+		var body io.Reader
+		if payload != nil {
+			body = bytes.NewReader(data)
+		}
+
+		response, err = send(body)
+		if attempt >= policy.MaxAttempts || !policy.shouldRetry(ctx, response, err) {
+			return
+		}
+
+		interval := policy.backoff(attempt, response)
+		fields := []zap.Field{zap.String("method", method), zap.Int("attempt", attempt),
+			zap.Duration("interval", interval)}
+		if err != nil {
+			fields = append(fields, zap.Error(err))
+		} else {
+			fields = append(fields, zap.Int("code", response.StatusCode))
+			// discard the body of the failed response, then the connection could be reused
+			_, _ = io.Copy(ioutil.Discard, response.Body)
+			_ = response.Body.Close()
+		}
+		j.loggerOf(ctx).Warn("retry HTTP request", fields...)
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			response, err = nil, ctx.Err()
+			return
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newFlakyServer(failures int32, bodies *[]string) (server *httptest.Server, count *int32) {
+	count = new(int32)
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/crumbIssuer/api/json" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if bodies != nil {
+			data, _ := ioutil.ReadAll(r.Body)
+			*bodies = append(*bodies, string(data))
+		}
+		if atomic.AddInt32(count, 1) <= failures {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	return
+}
+
+func newRetryCore(url string) *JenkinsCore {
+	policy := DefaultRetryPolicy()
+	policy.InitialInterval = time.Millisecond
+	policy.MaxInterval = 5 * time.Millisecond
+	return &JenkinsCore{URL: url, RetryPolicy: policy}
+}
+
+func TestRetryGetRequest(t *testing.T) {
+	server, count := newFlakyServer(2, nil)
+	defer server.Close()
+
+	jenkinsCore := newRetryCore(server.URL)
+	statusCode, data, err := jenkinsCore.Request(http.MethodGet, "/api/json", nil, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, statusCode)
+	assert.Equal(t, "ok", string(data))
+	assert.Equal(t, int32(3), atomic.LoadInt32(count))
+}
+
+func TestRetryReachMaxAttempts(t *testing.T) {
+	server, count := newFlakyServer(5, nil)
+	defer server.Close()
+
+	jenkinsCore := newRetryCore(server.URL)
+	statusCode, _, err := jenkinsCore.Request(http.MethodGet, "/api/json", nil, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, statusCode)
+	assert.Equal(t, int32(3), atomic.LoadInt32(count))
+}
+
+func TestRetryWithoutPolicy(t *testing.T) {
+	server, count := newFlakyServer(2, nil)
+	defer server.Close()
+
+	jenkinsCore := &JenkinsCore{URL: server.URL}
+	statusCode, _, err := jenkinsCore.Request(http.MethodGet, "/api/json", nil, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, statusCode)
+	assert.Equal(t, int32(1), atomic.LoadInt32(count))
+}
+
+func TestRetryPostRequest(t *testing.T) {
+	t.Run("not retry a POST request by default", func(t *testing.T) {
+		server, count := newFlakyServer(2, nil)
+		defer server.Close()
+
+		err := NewRequest("/fake", newRetryCore(server.URL)).WithPostMethod().Do()
+		var httpErr *HTTPError
+		if assert.True(t, errors.As(err, &httpErr)) {
+			assert.Equal(t, http.StatusServiceUnavailable, httpErr.StatusCode)
+		}
+		assert.Equal(t, int32(1), atomic.LoadInt32(count))
+	})
+
+	t.Run("retry an idempotent POST request with the same payload", func(t *testing.T) {
+		var bodies []string
+		server, count := newFlakyServer(2, &bodies)
+		defer server.Close()
+
+		err := NewRequest("/fake", newRetryCore(server.URL)).WithPostMethod().AsIdempotent().
+			WithPayload(strings.NewReader("payload")).Do()
+		assert.Nil(t, err)
+		assert.Equal(t, int32(3), atomic.LoadInt32(count))
+		assert.Equal(t, []string{"payload", "payload", "payload"}, bodies)
+	})
+}
+
+func TestRetryCanceledByContext(t *testing.T) {
+	server, count := newFlakyServer(5, nil)
+	defer server.Close()
+
+	jenkinsCore := newRetryCore(server.URL)
+	jenkinsCore.RetryPolicy.InitialInterval = time.Minute
+	jenkinsCore.RetryPolicy.MaxInterval = time.Minute
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, _, err := jenkinsCore.RequestWithContext(ctx, http.MethodGet, "/api/json", nil, nil)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Equal(t, int32(1), atomic.LoadInt32(count))
+}
+
+func TestRetryBackoff(t *testing.T) {
+	policy := &RetryPolicy{
+		InitialInterval: time.Second,
+		MaxInterval:     5 * time.Second,
+		Multiplier:      2,
+	}
+	assert.Equal(t, time.Second, policy.backoff(1, nil))
+	assert.Equal(t, 2*time.Second, policy.backoff(2, nil))
+	assert.Equal(t, 4*time.Second, policy.backoff(3, nil))
+	assert.Equal(t, 5*time.Second, policy.backoff(4, nil))
+
+	response := &http.Response{Header: http.Header{"Retry-After": []string{"3"}}}
+	assert.Equal(t, 3*time.Second, policy.backoff(1, response))
+
+	policy.Jitter = 0.5
+	for i := 0; i < 10; i++ {
+		interval := policy.backoff(2, nil)
+		assert.True(t, interval >= time.Second && interval <= 3*time.Second, interval)
+	}
+}
@@ -0,0 +1,136 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+)
+
+// ErrResponseTooLarge indicates that the response body exceeds the MaxResponseSize of the client
+var ErrResponseTooLarge = errors.New("the response body is too large")
+
+// ResponseTooLargeError represents a response body which exceeds the size limit
+type ResponseTooLargeError struct {
+	// Limit is the max bytes of the response body
+	Limit int64
+	URL   string
+}
+
+// Error returns the description of the error
+func (e *ResponseTooLargeError) Error() string {
+	if e.URL == "" {
+		return fmt.Sprintf("the response body exceeds the limit of %d bytes", e.Limit)
+	}
+	return fmt.Sprintf("the response body of %s exceeds the limit of %d bytes", e.URL, e.Limit)
+}
+
+// Is makes errors.Is(err, ErrResponseTooLarge) work
+func (e *ResponseTooLargeError) Is(target error) bool {
+	return target == ErrResponseTooLarge
+}
+
+// IsResponseTooLarge returns true if the response body exceeds the size limit
+func IsResponseTooLarge(err error) bool {
+	return errors.Is(err, ErrResponseTooLarge)
+}
+
+// limitedReadCloser fails with ResponseTooLargeError once more than the limit bytes are read
+type limitedReadCloser struct {
+	io.ReadCloser
+	limit     int64
+	remaining int64
+	url       string
+}
+
+// Read reads the data until reaching the limit
+func (l *limitedReadCloser) Read(p []byte) (n int, err error) {
+	if l.remaining <= 0 {
+		// probe one more byte to know if there is more data than the limit
+		var probe [1]byte
+		if n, err = l.ReadCloser.Read(probe[:]); n > 0 {
+			return 0, &ResponseTooLargeError{Limit: l.limit, URL: l.url}
+		}
+		return
+	}
+
+	if int64(len(p)) > l.remaining {
+		p = p[:l.remaining]
+	}
+	n, err = l.ReadCloser.Read(p)
+	l.remaining -= int64(n)
+	return
+}
+
+// LimitBody returns the body of the response which is limited by MaxResponseSize
+func (j *JenkinsCore) LimitBody(response *http.Response) io.ReadCloser {
+	if j.MaxResponseSize <= 0 {
+		return response.Body
+	}
+
+	reader := &limitedReadCloser{
+		ReadCloser: response.Body,
+		limit:      j.MaxResponseSize,
+		remaining:  j.MaxResponseSize,
+	}
+	if response.Request != nil && response.Request.URL != nil {
+		reader.url = response.Request.URL.String()
+	}
+	return reader
+}
+
+// ReadBody reads the whole body of the response, it fails if the body exceeds MaxResponseSize
+func (j *JenkinsCore) ReadBody(response *http.Response) ([]byte, error) {
+	return ioutil.ReadAll(j.LimitBody(response))
+}
+
+// RequestWithReader requests the api, then returns the response body as a stream.
+// The caller is responsible for closing the reader. The error has the response body if the status code is not 2xx.
+func (j *JenkinsCore) RequestWithReader(method, api string, headers map[string]string,
+	payload io.Reader) (response *http.Response, reader io.ReadCloser, err error) {
+	return j.RequestWithReaderWithContext(context.Background(), method, api, headers, payload)
+}
+
+// RequestWithReaderWithContext requests the api with a context, then returns the response body as a stream
+func (j *JenkinsCore) RequestWithReaderWithContext(ctx context.Context, method, api string, headers map[string]string,
+	payload io.Reader) (response *http.Response, reader io.ReadCloser, err error) {
+	if response, err = j.do(ctx, method, api, headers, payload); err != nil {
+		return
+	}
+
+	if response.StatusCode < http.StatusOK || response.StatusCode >= http.StatusMultipleChoices {
+		defer func() {
+			_ = response.Body.Close()
+		}()
+
+		var data []byte
+		if data, err = j.ReadBody(response); err == nil {
+			err = j.responseError(response, data)
+		}
+		return
+	}
+	reader = j.LimitBody(response)
+	return
+}
+
+// RequestWithDecoder requests the api, then decodes the JSON response body into obj without buffering it
+func (j *JenkinsCore) RequestWithDecoder(method, api string, headers map[string]string,
+	payload io.Reader, obj interface{}) (err error) {
+	return j.RequestWithDecoderWithContext(context.Background(), method, api, headers, payload, obj)
+}
+
+// RequestWithDecoderWithContext requests the api with a context, then decodes the JSON response body into obj
+func (j *JenkinsCore) RequestWithDecoderWithContext(ctx context.Context, method, api string, headers map[string]string,
+	payload io.Reader, obj interface{}) (err error) {
+	var reader io.ReadCloser
+	if _, reader, err = j.RequestWithReaderWithContext(ctx, method, api, headers, payload); err == nil {
+		defer func() {
+			_ = reader.Close()
+		}()
+		err = json.NewDecoder(reader).Decode(obj)
+	}
+	return
+}
@@ -0,0 +1,89 @@
+package core
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newStreamServer(body string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+}
+
+func TestRequestWithReader(t *testing.T) {
+	server := newStreamServer("fake log")
+	defer server.Close()
+
+	jenkinsCore := &JenkinsCore{URL: server.URL}
+	_, reader, err := jenkinsCore.RequestWithReader(http.MethodGet, "/log", nil, nil)
+	if assert.Nil(t, err) {
+		data, err := ioutil.ReadAll(reader)
+		assert.Nil(t, err)
+		assert.Equal(t, "fake log", string(data))
+		assert.Nil(t, reader.Close())
+	}
+
+	_, reader, err = jenkinsCore.RequestWithReader(http.MethodGet, "/missing", nil, nil)
+	assert.Nil(t, reader)
+	assert.True(t, IsNotFound(err))
+}
+
+func TestRequestWithDecoder(t *testing.T) {
+	server := newStreamServer(`{"name":"fake"}`)
+	defer server.Close()
+
+	obj := struct {
+		Name string `json:"name"`
+	}{}
+	jenkinsCore := &JenkinsCore{URL: server.URL}
+	assert.Nil(t, jenkinsCore.RequestWithDecoder(http.MethodGet, "/api/json", nil, nil, &obj))
+	assert.Equal(t, "fake", obj.Name)
+}
+
+func TestMaxResponseSize(t *testing.T) {
+	body := strings.Repeat("a", 16)
+	server := newStreamServer(body)
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		limit   int64
+		wantErr bool
+	}{{
+		name:  "no limit",
+		limit: 0,
+	}, {
+		name:  "equal to the limit",
+		limit: 16,
+	}, {
+		name:    "exceed the limit",
+		limit:   15,
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jenkinsCore := &JenkinsCore{URL: server.URL, MaxResponseSize: tt.limit}
+			_, data, err := jenkinsCore.Request(http.MethodGet, "/log", nil, nil)
+			if tt.wantErr {
+				var tooLarge *ResponseTooLargeError
+				assert.True(t, errors.As(err, &tooLarge))
+				assert.True(t, IsResponseTooLarge(err))
+				assert.Equal(t, tt.limit, tooLarge.Limit)
+				assert.Equal(t, server.URL+"/log", tooLarge.URL)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, body, string(data))
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package core
+
+import "context"
+
+// Span is a piece of the trace, it's easy to adapt to a tracing library such as OpenTelemetry
+type Span interface {
+	SetAttribute(key string, value interface{})
+	RecordError(err error)
+	End()
+}
+
+// Tracer starts the spans
+type Tracer interface {
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// TracingObserver is an Observer which produces a span for each request
+type TracingObserver struct {
+	Tracer Tracer
+}
+
+type spanKey struct{}
+
+// NewTracingObserver creates a TracingObserver
+func NewTracingObserver(tracer Tracer) *TracingObserver {
+	return &TracingObserver{Tracer: tracer}
+}
+
+// BeforeRequest starts a span which is named by the method and API path template
+func (o *TracingObserver) BeforeRequest(ctx context.Context, event *RequestEvent) context.Context {
+	ctx, span := o.Tracer.Start(ctx, event.Method+" "+event.Path)
+	span.SetAttribute("http.method", event.Method)
+	span.SetAttribute("http.url", event.URL)
+	span.SetAttribute("http.route", event.Path)
+	return context.WithValue(ctx, spanKey{}, span)
+}
+
+// AfterRequest ends the span of the request
+func (o *TracingObserver) AfterRequest(ctx context.Context, event *RequestEvent) {
+	span, ok := ctx.Value(spanKey{}).(Span)
+	if !ok {
+		return
+	}
+	if event.StatusCode > 0 {
+		span.SetAttribute("http.status_code", event.StatusCode)
+	}
+	span.SetAttribute("http.response_content_length", event.BytesRead)
+	if event.Err != nil {
+		span.RecordError(event.Err)
+	}
+	span.End()
+}
@@ -0,0 +1,168 @@
+package core
+
+import (
+	"crypto/tls"
+	"net/http"
+	"reflect"
+	"sync"
+	"time"
+
+	httpdownloader "github.com/linuxsuren/http-downloader/pkg/net"
+)
+
+// transportKey identifies the transports which could be shared with each other
+type transportKey struct {
+	insecureSkipVerify  bool
+	proxy               string
+	proxyAuth           string
+	maxIdleConnsPerHost int
+	idleConnTimeout     time.Duration
+	enableHTTP2         bool
+	// tlsIdentity identifies the TLS material of the authenticator, see TLSIdentity
+	tlsIdentity string
+}
+
+// transports are the shared transports by their settings. They are kept for the lifetime of the process,
+// because the clients might hold them at any time. The count is bounded by the distinct settings and TLS material
+// which are used in the process, the clients which rotate the TLS material frequently should use a TLSAuthenticator
+// without TLSIdentity, then the transport is held by the client instead.
+var (
+	transports      = map[transportKey]*http.Transport{}
+	transportsMutex sync.Mutex
+)
+
+// ownedTransportMutex protects the lazy initialization of the owned transport
+var ownedTransportMutex sync.Mutex
+
+// ownedTransport is the transport of a client whose TLS authenticator has no TLSIdentity,
+// it's not shared with the other clients, and it's replaced once the settings are changed
+type ownedTransport struct {
+	mutex         sync.Mutex
+	key           transportKey
+	authenticator TLSAuthenticator
+	transport     *http.Transport
+}
+
+func (j *JenkinsCore) getTransportKey() (key transportKey, tlsAuthenticator TLSAuthenticator) {
+	tlsAuthenticator, _ = j.Authenticator.(TLSAuthenticator)
+	key = transportKey{
+		insecureSkipVerify:  j.InsecureSkipVerify,
+		proxy:               j.Proxy,
+		proxyAuth:           j.ProxyAuth,
+		maxIdleConnsPerHost: j.MaxIdleConnsPerHost,
+		idleConnTimeout:     j.IdleConnTimeout,
+		enableHTTP2:         j.EnableHTTP2,
+	}
+	if identity, ok := tlsAuthenticator.(TLSIdentity); ok {
+		key.tlsIdentity = identity.TLSIdentity()
+	}
+	return
+}
+
+// shared returns true if the transport could be shared with the other clients
+func (k transportKey) shared(tlsAuthenticator TLSAuthenticator) bool {
+	return tlsAuthenticator == nil || k.tlsIdentity != ""
+}
+
+// GetTransport returns the transport of this client. The transport is created once,
+// then shared by all the clients which have the same TLS, proxy, and connection pool settings.
+func (j *JenkinsCore) GetTransport() (tr *http.Transport, err error) {
+	key, tlsAuthenticator := j.getTransportKey()
+	if !key.shared(tlsAuthenticator) {
+		return j.getOwnedTransport(key, tlsAuthenticator)
+	}
+
+	transportsMutex.Lock()
+	defer transportsMutex.Unlock()
+	if tr = transports[key]; tr == nil {
+		if tr, err = j.newTransport(tlsAuthenticator); err == nil {
+			transports[key] = tr
+		}
+	}
+	return
+}
+
+func (j *JenkinsCore) getOwnedTransportHolder() *ownedTransport {
+	ownedTransportMutex.Lock()
+	defer ownedTransportMutex.Unlock()
+	if j.transport == nil {
+		j.transport = &ownedTransport{}
+	}
+	return j.transport
+}
+
+// getOwnedTransport returns the transport which is held by this client,
+// the idle connections of the previous one are closed if it's replaced
+func (j *JenkinsCore) getOwnedTransport(key transportKey, tlsAuthenticator TLSAuthenticator) (
+	tr *http.Transport, err error) {
+	owned := j.getOwnedTransportHolder()
+	owned.mutex.Lock()
+	defer owned.mutex.Unlock()
+	if owned.transport != nil && owned.key == key && reflect.DeepEqual(owned.authenticator, tlsAuthenticator) {
+		tr = owned.transport
+		return
+	}
+
+	if tr, err = j.newTransport(tlsAuthenticator); err == nil {
+		if owned.transport != nil {
+			owned.transport.CloseIdleConnections()
+		}
+		owned.key, owned.authenticator, owned.transport = key, tlsAuthenticator, tr
+	}
+	return
+}
+
+func (j *JenkinsCore) newTransport(tlsAuthenticator TLSAuthenticator) (tr *http.Transport, err error) {
+	tr = http.DefaultTransport.(*http.Transport).Clone()
+	// only take the proxy from the setting instead of the environment
+	tr.Proxy = nil
+	tr.TLSClientConfig = &tls.Config{InsecureSkipVerify: j.InsecureSkipVerify}
+	tr.ForceAttemptHTTP2 = j.EnableHTTP2
+	if j.MaxIdleConnsPerHost > 0 {
+		tr.MaxIdleConnsPerHost = j.MaxIdleConnsPerHost
+		if tr.MaxIdleConns < j.MaxIdleConnsPerHost {
+			tr.MaxIdleConns = j.MaxIdleConnsPerHost
+		}
+	}
+	if j.IdleConnTimeout > 0 {
+		tr.IdleConnTimeout = j.IdleConnTimeout
+	}
+	if tlsAuthenticator != nil {
+		if err = tlsAuthenticator.ConfigureTLS(tr.TLSClientConfig); err != nil {
+			tr = nil
+			return
+		}
+	}
+	if err = httpdownloader.SetProxy(j.Proxy, j.ProxyAuth, tr); err != nil {
+		tr = nil
+	}
+	return
+}
+
+// CloseIdleConnections closes the idle connections of the transport of this client
+func (j *JenkinsCore) CloseIdleConnections() {
+	var tr *http.Transport
+	if key, tlsAuthenticator := j.getTransportKey(); key.shared(tlsAuthenticator) {
+		transportsMutex.Lock()
+		tr = transports[key]
+		transportsMutex.Unlock()
+	} else {
+		owned := j.getOwnedTransportHolder()
+		owned.mutex.Lock()
+		tr = owned.transport
+		owned.mutex.Unlock()
+	}
+	if tr != nil {
+		tr.CloseIdleConnections()
+	}
+}
+
+// errorRoundTripper fails all the requests with the same error
+type errorRoundTripper struct {
+	err error
+}
+
+// RoundTrip returns the error directly
+func (e *errorRoundTripper) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, e.err
+}
@@ -0,0 +1,123 @@
+package core
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetTransport(t *testing.T) {
+	t.Run("share the transport across the clients", func(t *testing.T) {
+		jenkinsCore := JenkinsCore{URL: "http://localhost", MaxIdleConnsPerHost: 7}
+		copied := jenkinsCore
+
+		tr, err := jenkinsCore.GetTransport()
+		assert.Nil(t, err)
+		other, err := copied.GetTransport()
+		assert.Nil(t, err)
+		assert.Same(t, tr, other)
+		assert.Same(t, tr, jenkinsCore.GetClient().Transport)
+	})
+
+	t.Run("apply the connection pool settings", func(t *testing.T) {
+		jenkinsCore := &JenkinsCore{
+			MaxIdleConnsPerHost: 200,
+			IdleConnTimeout:     time.Minute,
+			EnableHTTP2:         true,
+			InsecureSkipVerify:  true,
+		}
+		tr, err := jenkinsCore.GetTransport()
+		assert.Nil(t, err)
+		assert.Equal(t, 200, tr.MaxIdleConnsPerHost)
+		assert.Equal(t, 200, tr.MaxIdleConns)
+		assert.Equal(t, time.Minute, tr.IdleConnTimeout)
+		assert.True(t, tr.ForceAttemptHTTP2)
+		assert.True(t, tr.TLSClientConfig.InsecureSkipVerify)
+
+		other, err := (&JenkinsCore{MaxIdleConnsPerHost: 100}).GetTransport()
+		assert.Nil(t, err)
+		assert.NotSame(t, tr, other)
+	})
+
+	t.Run("share the transport by the TLS material", func(t *testing.T) {
+		certFile, keyFile := writeClientCertificate(t, t.TempDir())
+		tr, err := (&JenkinsCore{Authenticator: NewClientCertificate(certFile, keyFile, "")}).GetTransport()
+		assert.Nil(t, err)
+		other, err := (&JenkinsCore{Authenticator: NewClientCertificate(certFile, keyFile, "")}).GetTransport()
+		assert.Nil(t, err)
+		assert.Same(t, tr, other)
+		assert.Len(t, tr.TLSClientConfig.Certificates, 1)
+	})
+
+	t.Run("hold the transport of a TLS authenticator without identity", func(t *testing.T) {
+		transportsMutex.Lock()
+		count := len(transports)
+		transportsMutex.Unlock()
+
+		jenkinsCore := &JenkinsCore{Authenticator: &fakeTLSAuthenticator{serverName: "jenkins"}}
+		tr, err := jenkinsCore.GetTransport()
+		assert.Nil(t, err)
+		assert.Equal(t, "jenkins", tr.TLSClientConfig.ServerName)
+		other, err := jenkinsCore.GetTransport()
+		assert.Nil(t, err)
+		assert.Same(t, tr, other)
+
+		jenkinsCore.Authenticator = &fakeTLSAuthenticator{serverName: "another"}
+		other, err = jenkinsCore.GetTransport()
+		assert.Nil(t, err)
+		assert.NotSame(t, tr, other)
+		assert.Equal(t, "another", other.TLSClientConfig.ServerName)
+
+		transportsMutex.Lock()
+		defer transportsMutex.Unlock()
+		assert.Equal(t, count, len(transports))
+	})
+
+	t.Run("create the held transport once in the concurrent requests", func(t *testing.T) {
+		jenkinsCore := &JenkinsCore{Authenticator: &fakeTLSAuthenticator{serverName: "jenkins"}}
+		results := make([]*http.Transport, 8)
+		wg := sync.WaitGroup{}
+		for i := range results {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				results[i], _ = jenkinsCore.GetTransport()
+			}(i)
+		}
+		wg.Wait()
+		for _, tr := range results {
+			assert.Same(t, results[0], tr)
+		}
+	})
+
+	t.Run("return the error of an invalid proxy", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		defer server.Close()
+
+		jenkinsCore := &JenkinsCore{URL: server.URL, Proxy: "://invalid"}
+		_, err := jenkinsCore.GetTransport()
+		assert.NotNil(t, err)
+
+		_, _, err = jenkinsCore.Request(http.MethodGet, "/api/json", nil, nil)
+		assert.NotNil(t, err)
+	})
+}
+
+// fakeTLSAuthenticator is a TLSAuthenticator without TLSIdentity
+type fakeTLSAuthenticator struct {
+	serverName string
+}
+
+func (a *fakeTLSAuthenticator) Authenticate(*http.Request) error {
+	return nil
+}
+
+func (a *fakeTLSAuthenticator) ConfigureTLS(config *tls.Config) error {
+	config.ServerName = a.serverName
+	return nil
+}
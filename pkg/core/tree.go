@@ -0,0 +1,134 @@
+package core
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// TreeField is a field of the tree expression of the Jenkins remote API,
+// such as builds[number,result]{0,10}
+type TreeField struct {
+	Name     string
+	Children []TreeField
+
+	// rangeExpr is the range of the array field, such as {0,10}
+	rangeExpr string
+}
+
+// Field creates a tree field with the nested fields
+func Field(name string, children ...TreeField) TreeField {
+	return TreeField{Name: name, Children: children}
+}
+
+// Fields creates the tree fields which have no nested fields
+func Fields(names ...string) (fields []TreeField) {
+	fields = make([]TreeField, len(names))
+	for i, name := range names {
+		fields[i] = Field(name)
+	}
+	return
+}
+
+// Range selects the items of an array field from start (inclusive) to end (exclusive).
+// A negative start or end means there is no limit on that side.
+func (f TreeField) Range(start, end int) TreeField {
+	var from, to string
+	if start >= 0 {
+		from = strconv.Itoa(start)
+	}
+	if end >= 0 {
+		to = strconv.Itoa(end)
+	}
+	f.rangeExpr = fmt.Sprintf("{%s,%s}", from, to)
+	return f
+}
+
+// Index selects a single item of an array field
+func (f TreeField) Index(index int) TreeField {
+	f.rangeExpr = fmt.Sprintf("{%d}", index)
+	return f
+}
+
+// String returns the expression of the field
+func (f TreeField) String() string {
+	expr := f.Name
+	if len(f.Children) > 0 {
+		expr += "[" + Tree(f.Children).String() + "]"
+	}
+	return expr + f.rangeExpr
+}
+
+// Tree is the tree expression of the Jenkins remote API, it limits the fields of the response
+type Tree []TreeField
+
+// NewTree creates a tree expression
+func NewTree(fields ...TreeField) Tree {
+	return fields
+}
+
+// String returns the expression of the tree, such as jobs[name,color]
+func (t Tree) String() string {
+	exprs := make([]string, len(t))
+	for i, field := range t {
+		exprs[i] = field.String()
+	}
+	return strings.Join(exprs, ",")
+}
+
+// Query holds the parameters which limit the payload of the Jenkins remote API
+type Query struct {
+	Tree Tree
+	// Depth is the depth of the nested objects, it's ignored if the tree is not empty
+	Depth int
+}
+
+// QueryOption is the option of the remote API query
+type QueryOption func(*Query)
+
+// WithTree only fetches the given fields
+func WithTree(fields ...TreeField) QueryOption {
+	return func(query *Query) {
+		query.Tree = append(query.Tree, fields...)
+	}
+}
+
+// WithDepth fetches the nested objects until the depth
+func WithDepth(depth int) QueryOption {
+	return func(query *Query) {
+		query.Depth = depth
+	}
+}
+
+// NewQuery creates a query with the options
+func NewQuery(options ...QueryOption) (query *Query) {
+	query = &Query{}
+	for _, option := range options {
+		option(query)
+	}
+	return
+}
+
+// Values returns the query parameters
+func (q *Query) Values() (values url.Values) {
+	values = url.Values{}
+	if len(q.Tree) > 0 {
+		values.Set("tree", q.Tree.String())
+	} else if q.Depth > 0 {
+		values.Set("depth", strconv.Itoa(q.Depth))
+	}
+	return
+}
+
+// WithQuery appends the query parameters to the api
+func WithQuery(api string, options ...QueryOption) string {
+	query := NewQuery(options...).Values().Encode()
+	if query == "" {
+		return api
+	}
+	if strings.Contains(api, "?") {
+		return api + "&" + query
+	}
+	return api + "?" + query
+}
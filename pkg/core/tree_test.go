@@ -0,0 +1,70 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTree(t *testing.T) {
+	tests := []struct {
+		name   string
+		tree   Tree
+		expect string
+	}{{
+		name:   "plain fields",
+		tree:   NewTree(Fields("name", "color")...),
+		expect: "name,color",
+	}, {
+		name: "nested fields with a range",
+		tree: NewTree(Field("jobs", Field("name"), Field("color"),
+			Field("builds", Fields("number", "result")...).Range(0, 10))),
+		expect: "jobs[name,color,builds[number,result]{0,10}]",
+	}, {
+		name:   "open ranges",
+		tree:   NewTree(Field("builds", Field("number")).Range(5, -1), Field("jobs", Field("name")).Range(-1, 3)),
+		expect: "builds[number]{5,},jobs[name]{,3}",
+	}, {
+		name:   "a single item",
+		tree:   NewTree(Field("builds", Field("number")).Index(2)),
+		expect: "builds[number]{2}",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expect, tt.tree.String())
+		})
+	}
+}
+
+func TestWithQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		api     string
+		options []QueryOption
+		expect  string
+	}{{
+		name:   "without options",
+		api:    "/api/json",
+		expect: "/api/json",
+	}, {
+		name:    "with depth",
+		api:     "/api/json",
+		options: []QueryOption{WithDepth(2)},
+		expect:  "/api/json?depth=2",
+	}, {
+		name:    "with tree",
+		api:     "/api/json?pretty=true",
+		options: []QueryOption{WithTree(Fields("name")...), WithTree(Field("jobs", Field("name")))},
+		expect:  "/api/json?pretty=true&tree=name%2Cjobs%5Bname%5D",
+	}, {
+		name:    "the tree takes precedence over the depth",
+		api:     "/api/json",
+		options: []QueryOption{WithDepth(2), WithTree(Fields("name")...)},
+		expect:  "/api/json?tree=name",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expect, WithQuery(tt.api, tt.options...))
+		})
+	}
+}
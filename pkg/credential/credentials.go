@@ -1,6 +1,7 @@
 package credential
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -19,9 +20,14 @@ type CredentialsManager struct {
 
 // GetList returns the credential list
 func (c *CredentialsManager) GetList(store string) (credentialList List, err error) {
+	return c.GetListWithContext(context.Background(), store)
+}
+
+// GetListWithContext returns the credential list with a context
+func (c *CredentialsManager) GetListWithContext(ctx context.Context, store string) (credentialList List, err error) {
 	api := fmt.Sprintf("/credentials/store/%s/domain/_/api/json?pretty=true&depth=1", store)
 	request := core.NewRequest(api, &c.JenkinsCore)
-	if err = request.Do(); err == nil {
+	if err = request.DoWithContext(ctx); err == nil {
 		err = request.GetObject(&credentialList)
 	}
 	return
@@ -29,36 +35,56 @@ func (c *CredentialsManager) GetList(store string) (credentialList List, err err
 
 // Delete deletes a credential by id from a store
 func (c *CredentialsManager) Delete(store, id string) (err error) {
+	return c.DeleteWithContext(context.Background(), store, id)
+}
+
+// DeleteWithContext deletes a credential by id from a store with a context
+func (c *CredentialsManager) DeleteWithContext(ctx context.Context, store, id string) (err error) {
 	api := fmt.Sprintf("/credentials/store/%s/domain/_/credential/%s/doDelete", store, id)
 	request := core.NewRequest(api, &c.JenkinsCore)
-	err = request.WithPostMethod().Do()
+	err = request.WithPostMethod().DoWithContext(ctx)
 	return
 }
 
 // DeleteInFolder deletes a credential by id from a folder
 func (c *CredentialsManager) DeleteInFolder(folder, id string) (err error) {
+	return c.DeleteInFolderWithContext(context.Background(), folder, id)
+}
+
+// DeleteInFolderWithContext deletes a credential by id from a folder with a context
+func (c *CredentialsManager) DeleteInFolderWithContext(ctx context.Context, folder, id string) (err error) {
 	api := fmt.Sprintf("/job/%s/credentials/store/folder/domain/_/credential/%s/doDelete", folder, id)
 	request := core.NewRequest(api, &c.JenkinsCore)
-	err = request.WithPostMethod().Do()
+	err = request.WithPostMethod().DoWithContext(ctx)
 	return
 }
 
 // Create create a credential in Jenkins
 func (c *CredentialsManager) Create(store, credential string) (err error) {
+	return c.CreateWithContext(context.Background(), store, credential)
+}
+
+// CreateWithContext create a credential in Jenkins with a context
+func (c *CredentialsManager) CreateWithContext(ctx context.Context, store, credential string) (err error) {
 	api := fmt.Sprintf("/credentials/store/%s/domain/_/createCredentials", store)
-	core.Logger.Debug("create credential", zap.String("api", api), zap.String("payload", credential))
+	c.GetLogger().Debug("create credential", zap.String("api", api), zap.String("payload", credential))
 
 	formData := url.Values{}
 	formData.Add("json", fmt.Sprintf(`{"credentials": %s}`, credential))
 
 	request := core.NewRequest(api, &c.JenkinsCore)
 	request.AsPostFormRequest().WithValues(formData)
-	err = request.Do()
+	err = request.DoWithContext(ctx)
 	return
 }
 
 // CreateInFolder creates a credential in a folder
 func (c *CredentialsManager) CreateInFolder(folder string, cre interface{}) (err error) {
+	return c.CreateInFolderWithContext(context.Background(), folder, cre)
+}
+
+// CreateInFolderWithContext creates a credential in a folder with a context
+func (c *CredentialsManager) CreateInFolderWithContext(ctx context.Context, folder string, cre interface{}) (err error) {
 	api := fmt.Sprintf("/job/%s/credentials/store/folder/domain/_/createCredentials", folder)
 
 	formData := url.Values{}
@@ -66,12 +92,17 @@ func (c *CredentialsManager) CreateInFolder(folder string, cre interface{}) (err
 
 	request := core.NewRequest(api, &c.JenkinsCore)
 	request.AsPostFormRequest().WithValues(formData)
-	err = request.Do()
+	err = request.DoWithContext(ctx)
 	return
 }
 
 // UpdateInFolder updates a credential in a folder
 func (c *CredentialsManager) UpdateInFolder(folder, id string, cre interface{}) (err error) {
+	return c.UpdateInFolderWithContext(context.Background(), folder, id, cre)
+}
+
+// UpdateInFolderWithContext updates a credential in a folder with a context
+func (c *CredentialsManager) UpdateInFolderWithContext(ctx context.Context, folder, id string, cre interface{}) (err error) {
 	api := fmt.Sprintf("/job/%s/credentials/store/folder/domain/_/credential/%s/updateSubmit", folder, id)
 
 	formData := url.Values{}
@@ -79,16 +110,21 @@ func (c *CredentialsManager) UpdateInFolder(folder, id string, cre interface{})
 
 	request := core.NewRequest(api, &c.JenkinsCore)
 	request.AsPostFormRequest().WithValues(formData).AcceptStatusCode(http.StatusNotFound)
-	err = request.Do()
+	err = request.DoWithContext(ctx)
 	return
 }
 
 // GetInFolder gets a credential in a folder
 func (c *CredentialsManager) GetInFolder(folder, id string) (cre Credential, err error) {
+	return c.GetInFolderWithContext(context.Background(), folder, id)
+}
+
+// GetInFolderWithContext gets a credential in a folder with a context
+func (c *CredentialsManager) GetInFolderWithContext(ctx context.Context, folder, id string) (cre Credential, err error) {
 	api := fmt.Sprintf("/job/%s/credentials/store/folder/domain/_/credential/%s", folder, id)
 
 	request := core.NewRequest(api, &c.JenkinsCore)
-	if err = request.WithValues(url.Values{"depth": {"2"}}).Do(); err == nil {
+	if err = request.WithValues(url.Values{"depth": {"2"}}).DoWithContext(ctx); err == nil {
 		err = request.GetObject(&cre)
 	}
 	return
@@ -96,20 +132,30 @@ func (c *CredentialsManager) GetInFolder(folder, id string) (cre Credential, err
 
 // CreateUsernamePassword create username and password credential in Jenkins
 func (c *CredentialsManager) CreateUsernamePassword(store string, cred UsernamePasswordCredential) (err error) {
+	return c.CreateUsernamePasswordWithContext(context.Background(), store, cred)
+}
+
+// CreateUsernamePasswordWithContext create username and password credential in Jenkins with a context
+func (c *CredentialsManager) CreateUsernamePasswordWithContext(ctx context.Context, store string, cred UsernamePasswordCredential) (err error) {
 	var payload []byte
 	cred.Class = "com.cloudbees.plugins.credentials.impl.UsernamePasswordCredentialsImpl"
 	if payload, err = json.Marshal(cred); err == nil {
-		err = c.Create(store, string(payload))
+		err = c.CreateWithContext(ctx, store, string(payload))
 	}
 	return
 }
 
 // CreateSecret create token credential in Jenkins
 func (c *CredentialsManager) CreateSecret(store string, cred StringCredentials) (err error) {
+	return c.CreateSecretWithContext(context.Background(), store, cred)
+}
+
+// CreateSecretWithContext create token credential in Jenkins with a context
+func (c *CredentialsManager) CreateSecretWithContext(ctx context.Context, store string, cred StringCredentials) (err error) {
 	var payload []byte
 	cred.Class = "org.jenkinsci.plugins.plaincredentials.impl.StringCredentialsImpl"
 	if payload, err = json.Marshal(cred); err == nil {
-		err = c.Create(store, string(payload))
+		err = c.CreateWithContext(ctx, store, string(payload))
 	}
 	return
 }
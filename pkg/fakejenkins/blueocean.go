@@ -0,0 +1,224 @@
+package fakejenkins
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// blueTimeLayout is the time layout of the BlueOcean REST API
+const blueTimeLayout = "2006-01-02T15:04:05.000-0700"
+
+// handleBlueOcean handles the requests of the BlueOcean REST API, the segments are the path after /blue
+func (s *Server) handleBlueOcean(w http.ResponseWriter, r *http.Request, segments []string) {
+	if len(segments) < 2 || segments[0] != "rest" {
+		notFound(w)
+		return
+	}
+	if segments[1] == "search" {
+		s.handleBlueSearch(w, r)
+		return
+	}
+	if len(segments) < 3 || segments[1] != "organizations" {
+		notFound(w)
+		return
+	}
+
+	organization := segments[2]
+	segments = segments[3:]
+	var names []string
+	for len(segments) >= 2 && segments[0] == "pipelines" {
+		names = append(names, segments[1])
+		segments = segments[2:]
+	}
+	name := strings.Join(names, "/")
+
+	if len(segments) == 1 && segments[0] == "pipelines" {
+		pipelines := make([]map[string]interface{}, 0)
+		for _, job := range s.children(name) {
+			pipelines = append(pipelines, s.pipelineJSON(organization, job))
+		}
+		writeJSON(w, pipelines)
+		return
+	}
+
+	job, err := s.getJob(name)
+	if name == "" || err != nil {
+		notFound(w)
+		return
+	}
+
+	switch {
+	case len(segments) == 0:
+		writeJSON(w, s.pipelineJSON(organization, job))
+	case len(segments) == 1 && segments[0] == "runs":
+		if r.Method == http.MethodPost {
+			item := s.enqueue(job, "Started by user")
+			writeJSON(w, s.queuedRunJSON(organization, item))
+			return
+		}
+		s.handleBlueRuns(w, r, organization, job)
+	case len(segments) >= 2 && segments[0] == "runs":
+		build := job.getBuild(segments[1])
+		if build == nil {
+			notFound(w)
+			return
+		}
+		switch strings.Join(segments[2:], "/") {
+		case "":
+			writeJSON(w, s.runJSON(organization, build))
+		case "log":
+			writeText(w, build.log(s.now()))
+		case "stop":
+			if now := s.now(); build.building(now) {
+				build.abortedAt = now
+			}
+			writeJSON(w, s.runJSON(organization, build))
+		default:
+			notFound(w)
+		}
+	default:
+		notFound(w)
+	}
+}
+
+func (s *Server) handleBlueRuns(w http.ResponseWriter, r *http.Request, organization string, job *fakeJob) {
+	query := r.URL.Query()
+	start, _ := strconv.Atoi(query.Get("start"))
+	limit, _ := strconv.Atoi(query.Get("limit"))
+
+	runs := make([]map[string]interface{}, 0, len(job.builds))
+	for i := len(job.builds) - 1; i >= 0; i-- {
+		runs = append(runs, s.runJSON(organization, job.builds[i]))
+	}
+	writeJSON(w, paginate(runs, start, limit))
+}
+
+// handleBlueSearch handles the search API, the query looks like pipeline:*name*;excludedFromFlattening:...
+func (s *Server) handleBlueSearch(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	start, _ := strconv.Atoi(query.Get("start"))
+	limit, _ := strconv.Atoi(query.Get("limit"))
+
+	keyword := ""
+	for _, condition := range strings.Split(query.Get("q"), ";") {
+		if strings.HasPrefix(condition, "pipeline:") {
+			keyword = strings.ToLower(strings.Trim(strings.TrimPrefix(condition, "pipeline:"), "*"))
+		}
+	}
+
+	noFolders := query.Get("filter") == "no-folders"
+	names := make([]string, 0, len(s.jobs))
+	for name, job := range s.jobs {
+		if strings.Contains(strings.ToLower(name), keyword) && !(noFolders && job.isFolder()) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	items := make([]map[string]interface{}, 0, len(names))
+	for _, name := range names {
+		items = append(items, s.pipelineJSON("jenkins", s.jobs[name]))
+	}
+	writeJSON(w, paginate(items, start, limit))
+}
+
+func paginate(items []map[string]interface{}, start, limit int) []map[string]interface{} {
+	if start > len(items) {
+		start = len(items)
+	}
+	items = items[start:]
+	if limit > 0 && limit < len(items) {
+		items = items[:limit]
+	}
+	return items
+}
+
+func (s *Server) pipelineJSON(organization string, job *fakeJob) map[string]interface{} {
+	result := map[string]interface{}{
+		"_class":          "io.jenkins.blueocean.rest.impl.pipeline.PipelineImpl",
+		"organization":    organization,
+		"name":            job.shortName(),
+		"displayName":     job.shortName(),
+		"fullName":        job.name,
+		"fullDisplayName": job.name,
+		"disabled":        job.disabled,
+		"weatherScore":    100,
+	}
+	if job.isFolder() {
+		result["_class"] = "io.jenkins.blueocean.service.embedded.rest.PipelineFolderImpl"
+		folders, pipelines := 0, 0
+		var folderNames []string
+		for _, child := range s.children(job.name) {
+			if child.isFolder() {
+				folders++
+				folderNames = append(folderNames, child.shortName())
+			} else {
+				pipelines++
+			}
+		}
+		result["numberOfFolders"] = folders
+		result["numberOfPipelines"] = pipelines
+		result["pipelineFolderNames"] = folderNames
+	} else if len(job.builds) > 0 {
+		result["latestRun"] = s.runJSON(organization, job.builds[len(job.builds)-1])
+	}
+	return result
+}
+
+func blueTime(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+	return t.Format(blueTimeLayout)
+}
+
+func (s *Server) runJSON(organization string, build *fakeBuild) map[string]interface{} {
+	now := s.now()
+	state, result := "RUNNING", "UNKNOWN"
+	var endTime time.Time
+	duration := now.Sub(build.start).Milliseconds()
+	if value := build.result(now); value != "" {
+		state, result, endTime = "FINISHED", value, build.end()
+		duration = endTime.Sub(build.start).Milliseconds()
+	}
+	estimated := build.scenario.Duration.Milliseconds()
+
+	return map[string]interface{}{
+		"_class":                    "io.jenkins.blueocean.rest.impl.pipeline.PipelineRunImpl",
+		"id":                        strconv.Itoa(build.number),
+		"organization":              organization,
+		"pipeline":                  build.job.shortName(),
+		"state":                     state,
+		"result":                    result,
+		"enQueueTime":               blueTime(build.start),
+		"startTime":                 blueTime(build.start),
+		"endTime":                   blueTime(endTime),
+		"durationInMillis":          duration,
+		"estimatedDurationInMillis": estimated,
+		"queueId":                   strconv.Itoa(build.queueID),
+		"type":                      "WorkflowRun",
+		"causes":                    []map[string]string{{"shortDescription": build.cause}},
+	}
+}
+
+// queuedRunJSON returns the run of a queue item, it's the run if the build is started already
+func (s *Server) queuedRunJSON(organization string, item *queueItem) map[string]interface{} {
+	if item.build != nil {
+		return s.runJSON(organization, item.build)
+	}
+	return map[string]interface{}{
+		"_class":          "io.jenkins.blueocean.service.embedded.rest.QueueItemImpl",
+		"id":              strconv.Itoa(item.job.nextBuild),
+		"organization":    organization,
+		"pipeline":        item.job.shortName(),
+		"state":           "QUEUED",
+		"result":          "UNKNOWN",
+		"enQueueTime":     blueTime(item.since),
+		"queueId":         strconv.Itoa(item.id),
+		"causeOfBlockage": item.blocked,
+		"causes":          []map[string]string{{"shortDescription": item.cause}},
+	}
+}
@@ -0,0 +1,323 @@
+package fakejenkins_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jenkins-zh/jenkins-client/pkg/artifact"
+	"github.com/jenkins-zh/jenkins-client/pkg/casc"
+	"github.com/jenkins-zh/jenkins-client/pkg/computer"
+	"github.com/jenkins-zh/jenkins-client/pkg/core"
+	"github.com/jenkins-zh/jenkins-client/pkg/credential"
+	"github.com/jenkins-zh/jenkins-client/pkg/fakejenkins"
+	"github.com/jenkins-zh/jenkins-client/pkg/job"
+	"github.com/jenkins-zh/jenkins-client/pkg/plugin"
+	"github.com/jenkins-zh/jenkins-client/pkg/queue"
+	"github.com/stretchr/testify/assert"
+)
+
+// newFakeClock creates a clock which starts from a fixed time
+func newFakeClock() *fakejenkins.Clock {
+	return fakejenkins.NewClock(time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC))
+}
+
+func TestBuildLifecycle(t *testing.T) {
+	clock := newFakeClock()
+	server := fakejenkins.NewServer(fakejenkins.WithUser("admin", "token"), fakejenkins.WithClock(clock.Now))
+	defer server.Close()
+
+	assert.Nil(t, server.CreateFolder("team"))
+	assert.Nil(t, server.CreateJob("team/app", job.WorkflowJobClass))
+	assert.Nil(t, server.SetScenario("team/app", fakejenkins.Scenario{
+		Duration:  10 * time.Second,
+		Log:       []string{"checkout", "compile", "test", "archive"},
+		Artifacts: map[string]string{"target/app.jar": "fake binary"},
+	}))
+	assert.NotNil(t, server.CreateJob("missing/app", job.WorkflowJobClass))
+
+	jobClient := &job.Client{JenkinsCore: server.JenkinsCore()}
+	assert.Nil(t, jobClient.Build("team app"))
+
+	build, err := jobClient.GetBuild("team app", -1)
+	if assert.Nil(t, err) {
+		assert.Equal(t, 1, build.Number)
+		assert.True(t, build.Building)
+		assert.Equal(t, "", build.Result)
+	}
+
+	clock.Advance(5 * time.Second)
+	log, err := jobClient.Log("team app", 1, 0)
+	assert.Nil(t, err)
+	assert.True(t, log.HasMore)
+	assert.Equal(t, "Started by remote host\ncheckout\ncompile\n", log.Text)
+
+	clock.Advance(5 * time.Second)
+	log, err = jobClient.Log("team app", -1, log.NextStart)
+	assert.Nil(t, err)
+	assert.False(t, log.HasMore)
+	assert.Equal(t, "test\narchive\nFinished: SUCCESS\n", log.Text)
+
+	build, err = jobClient.GetBuild("team app", 1)
+	if assert.Nil(t, err) {
+		assert.False(t, build.Building)
+		assert.Equal(t, fakejenkins.ResultSuccess, build.Result)
+		assert.Equal(t, int64(10000), build.Duration)
+	}
+
+	artifactClient := &artifact.Client{JenkinsCore: server.JenkinsCore()}
+	artifacts, err := artifactClient.List("team app", 1)
+	if assert.Nil(t, err) && assert.Equal(t, 1, len(artifacts)) {
+		assert.Equal(t, "app.jar", artifacts[0].Name)
+		assert.Equal(t, int64(len("fake binary")), artifacts[0].Size)
+	}
+	reader, err := artifactClient.GetArtifact("team", "app", 1, "target/app.jar")
+	if assert.Nil(t, err) {
+		data, _ := ioutil.ReadAll(reader)
+		_ = reader.Close()
+		assert.Equal(t, "fake binary", string(data))
+	}
+
+	jobInfo, err := jobClient.GetJob("team app")
+	if assert.Nil(t, err) {
+		assert.Equal(t, "blue", jobInfo.Color)
+		assert.Equal(t, 2, jobInfo.NextBuildNumber)
+		assert.Equal(t, 1, len(jobInfo.Builds))
+	}
+}
+
+func TestScenarios(t *testing.T) {
+	clock := newFakeClock()
+	server := fakejenkins.NewServer(fakejenkins.WithClock(clock.Now))
+	defer server.Close()
+
+	jobClient := &job.Client{JenkinsCore: server.JenkinsCore()}
+	queueClient := &queue.Client{JenkinsCore: server.JenkinsCore()}
+	assert.Nil(t, jobClient.CreateJobInFolder(job.CreateJobPayload{Name: "fake", Mode: job.WorkflowJobClass}, ""))
+
+	t.Run("build fails after N seconds", func(t *testing.T) {
+		assert.Nil(t, server.SetScenario("fake", fakejenkins.BuildFailsAfter(3*time.Second, "error: exit code 1")))
+		assert.Nil(t, jobClient.Build("fake"))
+		clock.Advance(3 * time.Second)
+
+		build, err := jobClient.GetBuild("fake", -1)
+		if assert.Nil(t, err) {
+			assert.Equal(t, fakejenkins.ResultFailure, build.Result)
+		}
+		jobInfo, err := jobClient.GetJob("fake")
+		if assert.Nil(t, err) {
+			assert.Equal(t, "red", jobInfo.Color)
+		}
+	})
+
+	t.Run("queue item blocked", func(t *testing.T) {
+		assert.Nil(t, server.SetScenario("fake", fakejenkins.QueueBlocked("Waiting for next available executor")))
+		assert.Nil(t, jobClient.Build("fake"))
+		assert.Nil(t, jobClient.Build("fake"))
+
+		jobQueue, err := queueClient.Get()
+		if assert.Nil(t, err) && assert.Equal(t, 2, len(jobQueue.Items)) {
+			assert.True(t, jobQueue.Items[0].Blocked)
+			assert.Equal(t, "Waiting for next available executor", jobQueue.Items[0].Why)
+			assert.Nil(t, queueClient.Cancel(jobQueue.Items[0].ID))
+		}
+
+		server.Unblock("fake")
+		jobQueue, err = queueClient.Get()
+		if assert.Nil(t, err) {
+			assert.Empty(t, jobQueue.Items)
+		}
+		build, err := jobClient.GetBuild("fake", -1)
+		if assert.Nil(t, err) {
+			assert.Equal(t, 2, build.Number)
+			assert.Equal(t, fakejenkins.ResultSuccess, build.Result)
+		}
+	})
+
+	t.Run("stop a build", func(t *testing.T) {
+		assert.Nil(t, server.SetScenario("fake", fakejenkins.Scenario{Duration: time.Minute}))
+		assert.Nil(t, jobClient.Build("fake"))
+		assert.Nil(t, jobClient.StopJob("fake", -1))
+
+		build, err := jobClient.GetBuild("fake", -1)
+		if assert.Nil(t, err) {
+			assert.Equal(t, fakejenkins.ResultAborted, build.Result)
+		}
+	})
+
+	t.Run("the crumb is required", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodPost, server.URL+"/job/fake/build", nil)
+		response, err := http.DefaultClient.Do(request)
+		if assert.Nil(t, err) {
+			_ = response.Body.Close()
+			assert.Equal(t, http.StatusForbidden, response.StatusCode)
+		}
+	})
+
+	assert.Nil(t, jobClient.Delete("fake"))
+	assert.Empty(t, server.JobNames())
+}
+
+func TestSystem(t *testing.T) {
+	server := fakejenkins.NewServer(fakejenkins.WithUser("admin", "token"))
+	defer server.Close()
+
+	computerClient := &computer.Client{JenkinsCore: server.JenkinsCore()}
+	assert.Nil(t, computerClient.Create("agent"))
+	assert.NotNil(t, computerClient.Create("agent"))
+	assert.Nil(t, computerClient.Launch("agent"))
+	secret, err := computerClient.GetSecret("agent")
+	assert.Nil(t, err)
+	assert.Equal(t, "secret-of-agent", secret)
+	computers, err := computerClient.List()
+	if assert.Nil(t, err) {
+		assert.Equal(t, 2, len(computers.Computer))
+	}
+	assert.Nil(t, computerClient.Delete("agent"))
+
+	credentialManager := &credential.CredentialsManager{JenkinsCore: server.JenkinsCore()}
+	assert.Nil(t, credentialManager.CreateUsernamePassword("system", credential.UsernamePasswordCredential{
+		Credential: credential.Credential{ID: "git", StaplerClass: credential.UsernamePassswordCredentialStaplerClass},
+		Username:   "admin",
+		Password:   "password",
+	}))
+	list, err := credentialManager.GetList("system")
+	if assert.Nil(t, err) && assert.Equal(t, 1, len(list.Credentials)) {
+		assert.Equal(t, "git", list.Credentials[0].ID)
+		assert.Equal(t, "admin/******", list.Credentials[0].DisplayName)
+	}
+	assert.Nil(t, credentialManager.Delete("system", "git"))
+	assert.Empty(t, server.Credentials("system"))
+
+	server.AddAvailablePlugin("git", "4.0.0")
+	pluginManager := &plugin.Manager{JenkinsCore: server.JenkinsCore()}
+	assert.Nil(t, pluginManager.InstallPlugin([]string{"git"}))
+	assert.NotNil(t, pluginManager.InstallPlugin([]string{"missing"}))
+	installed, err := pluginManager.FindInstalledPlugin("git")
+	if assert.Nil(t, err) && assert.NotNil(t, installed) {
+		assert.Equal(t, "4.0.0", installed.Version)
+	}
+	assert.Nil(t, pluginManager.UninstallPlugin("git"))
+
+	cascManager := &casc.Manager{JenkinsCore: server.JenkinsCore()}
+	server.SetCasC("jenkins:\n  numExecutors: 3\n")
+	config, err := cascManager.Export()
+	assert.Nil(t, err)
+	assert.Equal(t, "jenkins:\n  numExecutors: 3\n", config)
+	assert.Nil(t, cascManager.Apply())
+	assert.Nil(t, cascManager.Reload())
+	assert.Equal(t, 2, server.CasCApplied())
+}
+
+func TestBlueOcean(t *testing.T) {
+	server := fakejenkins.NewServer()
+	defer server.Close()
+
+	assert.Nil(t, server.CreateFolder("team"))
+	assert.Nil(t, server.CreateJob("team/app", job.WorkflowJobClass))
+
+	client := &job.BlueOceanClient{JenkinsCore: server.JenkinsCore(), Organization: "jenkins"}
+	run, err := client.Build(job.BuildOption{Pipelines: []string{"team", "app"}})
+	if assert.Nil(t, err) {
+		assert.Equal(t, "1", run.ID)
+		assert.Equal(t, "FINISHED", run.State)
+		assert.Equal(t, fakejenkins.ResultSuccess, run.Result)
+	}
+
+	run, err = client.GetBuild(job.GetBuildOption{Pipelines: []string{"team", "app"}, RunID: "1"})
+	if assert.Nil(t, err) {
+		assert.Equal(t, "app", run.Pipeline)
+	}
+
+	runs, err := client.GetPipelineRuns("app", "team")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(runs))
+
+	pipeline, err := client.GetPipeline("app", "team")
+	if assert.Nil(t, err) {
+		assert.Equal(t, "team/app", pipeline.FullName)
+	}
+
+	items, err := client.Search("ap", 0, 10)
+	if assert.Nil(t, err) && assert.Equal(t, 1, len(items)) {
+		assert.True(t, strings.HasSuffix(items[0].FullName, "app"))
+	}
+}
+
+func TestCapabilities(t *testing.T) {
+	server := fakejenkins.NewServer(fakejenkins.WithVersion("2.400"))
+	defer server.Close()
+	assert.Nil(t, server.CreateJob("app", job.WorkflowJobClass))
+
+	jenkinsCore := server.JenkinsCore()
+	jenkinsCore.CheckCapabilities = true
+	client := &job.BlueOceanClient{JenkinsCore: jenkinsCore, Organization: "jenkins"}
+	_, err := client.GetPipeline("app")
+	assert.True(t, core.IsCapabilityMissing(err))
+	assert.Equal(t, "capability missing: plugin blueocean-rest >= 1.0", err.Error())
+
+	server.AddPlugin("blueocean-rest", "1.25.2")
+	capabilities, err := client.RefreshCapabilities(context.Background())
+	if assert.Nil(t, err) {
+		assert.Equal(t, "2.400", capabilities.Version)
+	}
+	_, err = client.GetPipeline("app")
+	assert.Nil(t, err)
+}
+
+func TestConfigXML(t *testing.T) {
+	server := fakejenkins.NewServer()
+	defer server.Close()
+	assert.Nil(t, server.CreateFolder("team"))
+	assert.Nil(t, server.CreateJob("team/app", job.WorkflowJobClass))
+
+	client := &job.Client{JenkinsCore: server.JenkinsCore()}
+	config, err := client.GetConfig("team app")
+	if !assert.Nil(t, err) {
+		return
+	}
+	pipeline, ok := config.(*job.WorkflowJob)
+	if !assert.True(t, ok) {
+		return
+	}
+	pipeline.Description = "built by the fake server"
+	pipeline.Properties.BuildDiscarder = job.NewBuildDiscarder(-1, 10)
+	pipeline.Definition = job.NewScriptDefinition("node { echo 'hello' }", true)
+	assert.Nil(t, client.UpdateConfig("team app", pipeline))
+
+	config, err = client.GetConfig("team app")
+	if assert.Nil(t, err) {
+		assert.Equal(t, pipeline, config)
+	}
+
+	folder, err := client.GetConfig("team")
+	if assert.Nil(t, err) {
+		assert.Equal(t, job.FolderClass, folder.Class())
+	}
+}
+
+func TestUsersAndClock(t *testing.T) {
+	clock := newFakeClock()
+	server := fakejenkins.NewServer(fakejenkins.WithUser("bob", "bob-token"), fakejenkins.WithUser("alice", "alice-token"),
+		fakejenkins.WithClock(clock.Now))
+	defer server.Close()
+
+	jenkinsCore := server.JenkinsCore()
+	assert.Equal(t, "alice", jenkinsCore.UserName)
+	assert.Equal(t, "alice-token", jenkinsCore.Token)
+	jenkinsCore = server.JenkinsCoreAs("bob")
+	assert.Equal(t, "bob-token", jenkinsCore.Token)
+
+	// the log is empty instead of a panic if the clock goes backwards
+	assert.Nil(t, server.CreateJob("app", job.WorkflowJobClass))
+	assert.Nil(t, server.SetScenario("app", fakejenkins.Scenario{Duration: time.Minute, Log: []string{"checkout"}}))
+	client := &job.Client{JenkinsCore: jenkinsCore}
+	assert.Nil(t, client.Build("app"))
+	clock.Advance(-time.Second)
+	log, err := client.Log("app", 1, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, "Started by remote host\n", log.Text)
+}
@@ -0,0 +1,723 @@
+package fakejenkins
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jenkins-zh/jenkins-client/pkg/job"
+)
+
+// serveHTTP authenticates the request, then dispatches it by the path
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	w.Header().Set("X-Jenkins", s.version)
+	authenticated := false
+	if userName, token, ok := r.BasicAuth(); ok {
+		if expected, found := s.users[userName]; !found || expected != token {
+			writeError(w, http.StatusUnauthorized, "Invalid password/token for user: "+userName)
+			return
+		}
+		authenticated = true
+	} else if len(s.users) > 0 {
+		writeError(w, http.StatusUnauthorized, "Authentication required")
+		return
+	}
+
+	// Jenkins does not require the crumb for the requests which are authenticated by the API token
+	if r.Method == http.MethodPost && s.crumb != "" && !authenticated &&
+		r.Header.Get(DefaultCrumbField) != s.crumb {
+		writeError(w, http.StatusForbidden, "No valid crumb was included in the request")
+		return
+	}
+
+	s.tick()
+	segments := splitPath(r.URL.Path)
+	if len(segments) == 0 {
+		// the dashboard, Jenkins redirects to it after deleting a view
+		s.handleRoot(w, r)
+		return
+	}
+
+	switch segments[0] {
+	case "api":
+		s.handleRoot(w, r)
+	case "crumbIssuer":
+		s.handleCrumb(w)
+	case "queue":
+		s.handleQueue(w, r, segments[1:])
+	case "computer":
+		s.handleComputer(w, r, segments[1:])
+	case "credentials":
+		s.handleCredentials(w, r, segments[1:], "")
+	case "pluginManager":
+		s.handlePluginManager(w, r, segments[1:])
+	case "configuration-as-code":
+		s.handleCasC(w, r, segments[1:])
+	case "blue":
+		s.handleBlueOcean(w, r, segments[1:])
+	case "items":
+		s.handleItems(w, r)
+	case "view":
+		s.handleViews(w, r, "", segments[1:])
+	case "createView":
+		s.handleCreateView(w, r, "", nil)
+	default:
+		s.handleJob(w, r, segments)
+	}
+}
+
+func splitPath(path string) (segments []string) {
+	for _, segment := range strings.Split(path, "/") {
+		if segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+	return
+}
+
+func writeJSON(w http.ResponseWriter, obj interface{}) {
+	w.Header().Set("Content-Type", "application/json;charset=utf-8")
+	_ = json.NewEncoder(w).Encode(obj)
+}
+
+func writeText(w http.ResponseWriter, text string) {
+	w.Header().Set("Content-Type", "text/plain;charset=utf-8")
+	_, _ = w.Write([]byte(text))
+}
+
+func writeError(w http.ResponseWriter, code int, message string) {
+	w.Header().Set("X-Error", message)
+	w.WriteHeader(code)
+	_, _ = w.Write([]byte(message))
+}
+
+func notFound(w http.ResponseWriter) {
+	writeError(w, http.StatusNotFound, "Not Found")
+}
+
+func methodNotAllowed(w http.ResponseWriter) {
+	writeError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+}
+
+func millis(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano() / int64(time.Millisecond)
+}
+
+func (s *Server) handleRoot(w http.ResponseWriter, r *http.Request) {
+	jobs := make([]map[string]interface{}, 0)
+	for _, job := range s.children("") {
+		jobs = append(jobs, s.jobSummary(job))
+	}
+	writeJSON(w, map[string]interface{}{
+		"_class":          "hudson.model.Hudson",
+		"mode":            "NORMAL",
+		"nodeDescription": "the built-in Jenkins node",
+		"nodeName":        "",
+		"numExecutors":    s.computers["(built-in)"].numExecutors,
+		"jobs":            jobs,
+		"views":           s.viewsJSON(""),
+		"url":             s.URL + "/",
+		"useCrumbs":       s.crumb != "",
+		"useSecurity":     len(s.users) > 0,
+	})
+}
+
+func (s *Server) handleCrumb(w http.ResponseWriter) {
+	if s.crumb == "" {
+		notFound(w)
+		return
+	}
+	writeJSON(w, map[string]string{
+		"_class":            "hudson.security.csrf.DefaultCrumbIssuer",
+		"crumbRequestField": DefaultCrumbField,
+		"crumb":             s.crumb,
+	})
+}
+
+// handleJob handles the requests of the items, such as /job/folder/job/name/build
+func (s *Server) handleJob(w http.ResponseWriter, r *http.Request, segments []string) {
+	var names []string
+	for len(segments) >= 2 && segments[0] == "job" {
+		names = append(names, segments[1])
+		segments = segments[2:]
+	}
+	name := strings.Join(names, "/")
+
+	if len(segments) == 1 && segments[0] == "createItem" {
+		s.handleCreateItem(w, r, name)
+		return
+	}
+
+	job, err := s.getJob(name)
+	if name == "" || err != nil {
+		notFound(w)
+		return
+	}
+
+	if len(segments) > 0 && segments[0] == "credentials" && job.isFolder() {
+		s.handleCredentials(w, r, segments[1:], name)
+		return
+	}
+	if len(segments) > 0 && segments[0] == "view" && job.isFolder() {
+		s.handleViews(w, r, name, segments[1:])
+		return
+	}
+	if len(segments) == 1 && segments[0] == "createView" && job.isFolder() {
+		s.handleCreateView(w, r, name, nil)
+		return
+	}
+
+	action := strings.Join(segments, "/")
+	switch {
+	case action == "" || action == "api/json":
+		writeJSON(w, s.jobJSON(job, r.URL.Query().Get("tree")))
+	case action == "build" || action == "buildWithParameters":
+		if r.Method != http.MethodPost {
+			methodNotAllowed(w)
+			return
+		}
+		item := s.enqueue(job, "Started by remote host")
+		w.Header().Set("Location", fmt.Sprintf("%s/queue/item/%d/", s.URL, item.id))
+		w.WriteHeader(http.StatusCreated)
+	case action == "restFul/build":
+		s.handleIdentityBuild(w, r, job)
+	case action == "disable" || action == "enable":
+		job.disabled = action == "disable"
+	case action == "doDelete":
+		s.deleteJob(job.name)
+	case action == "config.xml":
+		s.handleConfigXML(w, r, job)
+	case action == "confirmRename" || action == "move/move":
+		s.handleRelocate(w, r, job, action)
+	case action == "description":
+		if r.Method == http.MethodPost {
+			_ = r.ParseForm()
+			job.description = r.Form.Get("description")
+		} else {
+			writeText(w, job.description)
+		}
+	default:
+		build := job.getBuild(segments[0])
+		if build == nil {
+			notFound(w)
+			return
+		}
+		s.handleBuild(w, r, build, strings.Join(segments[1:], "/"))
+	}
+}
+
+// handleCreateItem creates a job from a form or a config.xml
+func (s *Server) handleCreateItem(w http.ResponseWriter, r *http.Request, parent string) {
+	if r.Method != http.MethodPost {
+		methodNotAllowed(w)
+		return
+	}
+	if parent != "" {
+		if folder, err := s.getJob(parent); err != nil || !folder.isFolder() {
+			notFound(w)
+			return
+		}
+	}
+
+	var (
+		name, class, config string
+		source              *fakeJob
+	)
+	if strings.Contains(r.Header.Get("Content-Type"), "xml") {
+		data, _ := ioutil.ReadAll(r.Body)
+		name, config = r.URL.Query().Get("name"), string(data)
+		class = classOfConfig(config)
+	} else {
+		_ = r.ParseForm()
+		name, class = r.Form.Get("name"), r.Form.Get("mode")
+		if class == "copy" {
+			from := strings.Trim(r.Form.Get("from"), "/")
+			var err error
+			source, err = s.getJob(from)
+			if err != nil && parent != "" {
+				source, err = s.getJob(parent + "/" + from)
+			}
+			if err != nil {
+				writeError(w, http.StatusBadRequest, "No such job: "+from)
+				return
+			}
+			class, config = source.class, source.config
+		}
+	}
+
+	if parent != "" {
+		name = parent + "/" + name
+	}
+	job, err := s.createJob(name, class, config)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if source != nil {
+		// Jenkins does not build the copy until its config is saved
+		job.description, job.displayName, job.disabled = source.description, source.displayName, source.disabled
+		job.holdOff = !job.isFolder()
+	}
+}
+
+// classOfConfig returns the job class by the root element of the config.xml
+func classOfConfig(config string) (class string) {
+	decoder := xml.NewDecoder(strings.NewReader(stripDeclaration(config)))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return
+		}
+		if element, ok := token.(xml.StartElement); ok {
+			switch element.Name.Local {
+			case "flow-definition":
+				class = job.WorkflowJobClass
+			case "project":
+				class = job.FreeStyleProjectClass
+			default:
+				class = element.Name.Local
+			}
+			return
+		}
+	}
+}
+
+// stripDeclaration removes the XML declaration, because encoding/xml does not support
+// the version 1.1 which Jenkins writes
+func stripDeclaration(config string) string {
+	if strings.HasPrefix(config, "<?xml") {
+		if end := strings.Index(config, "?>"); end > 0 {
+			return config[end+2:]
+		}
+	}
+	return config
+}
+
+// rootOfClass returns the root element of the config.xml by the job class
+func rootOfClass(class string) string {
+	switch class {
+	case job.WorkflowJobClass:
+		return "flow-definition"
+	case job.FreeStyleProjectClass:
+		return "project"
+	default:
+		return class
+	}
+}
+
+func (s *Server) handleConfigXML(w http.ResponseWriter, r *http.Request, job *fakeJob) {
+	switch r.Method {
+	case http.MethodGet:
+		w.Header().Set("Content-Type", "application/xml")
+		_, _ = w.Write([]byte(job.config))
+	case http.MethodPost:
+		data, _ := ioutil.ReadAll(r.Body)
+		job.config = string(data)
+		job.holdOff = false
+		var fields struct {
+			DisplayName string `xml:"displayName"`
+			Disabled    *bool  `xml:"disabled"`
+		}
+		if xml.Unmarshal([]byte(stripDeclaration(job.config)), &fields) == nil {
+			job.displayName = fields.DisplayName
+			if fields.Disabled != nil {
+				job.disabled = *fields.Disabled
+			}
+		}
+	default:
+		methodNotAllowed(w)
+	}
+}
+
+// handleRelocate renames a job by /confirmRename, or moves it into another folder by /move/move
+func (s *Server) handleRelocate(w http.ResponseWriter, r *http.Request, job *fakeJob, action string) {
+	if r.Method != http.MethodPost {
+		methodNotAllowed(w)
+		return
+	}
+	_ = r.ParseForm()
+
+	var newName string
+	if action == "confirmRename" {
+		newName = r.Form.Get("newName")
+		if newName == "" || strings.Contains(newName, "/") {
+			writeError(w, http.StatusBadRequest, "Invalid name: "+newName)
+			return
+		}
+		if index := strings.LastIndex(job.name, "/"); index > 0 {
+			newName = job.name[:index] + "/" + newName
+		}
+	} else {
+		destination := strings.Trim(r.Form.Get("destination"), "/")
+		if destination != "" {
+			folder, err := s.getJob(destination)
+			if err != nil || !folder.isFolder() || destination == job.name ||
+				strings.HasPrefix(destination, job.name+"/") {
+				writeError(w, http.StatusBadRequest, "Invalid destination: "+r.Form.Get("destination"))
+				return
+			}
+			newName = destination + "/"
+		}
+		newName += job.shortName()
+	}
+
+	if err := s.relocateJob(job, newName); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	http.Redirect(w, r, job.path(), http.StatusFound)
+}
+
+// handleIdentityBuild handles the API of the jcli plugin which triggers a build and returns it
+func (s *Server) handleIdentityBuild(w http.ResponseWriter, r *http.Request, job *fakeJob) {
+	if r.Method != http.MethodPost {
+		methodNotAllowed(w)
+		return
+	}
+	cause := r.URL.Query().Get("identifyCause")
+	item := s.enqueue(job, "Started by remote host with cause: "+cause)
+	if item.build == nil {
+		writeError(w, http.StatusRequestTimeout, fmt.Sprintf("queue item %d does not start in time", item.id))
+		return
+	}
+	writeJSON(w, map[string]interface{}{
+		"build": s.buildJSON(item.build),
+		"cause": map[string]string{
+			"uuid":             cause,
+			"shortDescription": item.cause,
+			"message":          cause,
+		},
+	})
+}
+
+var allBuildsRange = regexp.MustCompile(`allBuilds\[[^\]]*\]\{(\d*),(\d*)\}`)
+
+func (s *Server) jobSummary(job *fakeJob) map[string]interface{} {
+	summary := map[string]interface{}{
+		"_class":      job.class,
+		"name":        job.shortName(),
+		"url":         s.URL + job.path(),
+		"description": job.description,
+	}
+	if !job.isFolder() {
+		now := s.now()
+		summary["color"] = job.color(now)
+		summary["disabled"] = job.disabled
+		if len(job.builds) > 0 {
+			last := job.builds[len(job.builds)-1]
+			lastBuild := map[string]interface{}{"number": last.number, "url": s.buildURL(last), "result": nil}
+			if result := last.result(now); result != "" {
+				lastBuild["result"] = result
+			}
+			summary["lastBuild"] = lastBuild
+		}
+	}
+	return summary
+}
+
+func (s *Server) jobJSON(job *fakeJob, tree string) map[string]interface{} {
+	result := s.jobSummary(job)
+	result["fullName"] = job.name
+	result["displayName"] = job.shortName()
+	if job.displayName != "" {
+		result["displayName"] = job.displayName
+	}
+	result["description"] = job.description
+
+	if job.isFolder() {
+		jobs := make([]map[string]interface{}, 0)
+		for _, child := range s.children(job.name) {
+			jobs = append(jobs, s.jobSummary(child))
+		}
+		result["jobs"] = jobs
+		result["views"] = s.viewsJSON(job.name)
+		return result
+	}
+
+	builds := make([]map[string]interface{}, 0, len(job.builds))
+	for i := len(job.builds) - 1; i >= 0; i-- {
+		build := job.builds[i]
+		builds = append(builds, map[string]interface{}{"number": build.number, "url": s.buildURL(build)})
+	}
+	allBuilds := builds
+	if groups := allBuildsRange.FindStringSubmatch(tree); groups != nil {
+		start, end := 0, len(builds)
+		if groups[1] != "" {
+			start, _ = strconv.Atoi(groups[1])
+		}
+		if groups[2] != "" {
+			end, _ = strconv.Atoi(groups[2])
+		}
+		if end > len(builds) {
+			end = len(builds)
+		}
+		if start > end {
+			start = end
+		}
+		allBuilds = builds[start:end]
+	}
+
+	inQueue := false
+	for _, item := range s.pending() {
+		inQueue = inQueue || item.job == job
+	}
+	result["buildable"] = !job.disabled && !job.holdOff
+	result["builds"] = builds
+	result["allBuilds"] = allBuilds
+	result["inQueue"] = inQueue
+	result["nextBuildNumber"] = job.nextBuild
+	result["concurrentBuild"] = false
+	result["property"] = []interface{}{}
+	if len(builds) > 0 {
+		result["lastBuild"] = builds[0]
+	}
+	return result
+}
+
+func (s *Server) buildURL(build *fakeBuild) string {
+	return fmt.Sprintf("%s%s%d/", s.URL, build.job.path(), build.number)
+}
+
+func (s *Server) buildJSON(build *fakeBuild) map[string]interface{} {
+	now := s.now()
+	result := map[string]interface{}{
+		"_class":            "org.jenkinsci.plugins.workflow.job.WorkflowRun",
+		"number":            build.number,
+		"id":                strconv.Itoa(build.number),
+		"url":               s.buildURL(build),
+		"displayName":       fmt.Sprintf("#%d", build.number),
+		"fullDisplayName":   fmt.Sprintf("%s #%d", strings.ReplaceAll(build.job.name, "/", " » "), build.number),
+		"building":          build.building(now),
+		"result":            nil,
+		"duration":          0,
+		"estimatedDuration": build.scenario.Duration.Milliseconds(),
+		"timestamp":         millis(build.start),
+		"queueId":           build.queueID,
+		"keepLog":           false,
+		"actions": []map[string]interface{}{{
+			"_class": "hudson.model.CauseAction",
+			"causes": []map[string]string{{"shortDescription": build.cause}},
+		}},
+	}
+	if value := build.result(now); value != "" {
+		result["result"] = value
+		result["duration"] = build.end().Sub(build.start).Milliseconds()
+	}
+	return result
+}
+
+// handleBuild handles the requests of a build, the action is the path after the build number
+func (s *Server) handleBuild(w http.ResponseWriter, r *http.Request, build *fakeBuild, action string) {
+	now := s.now()
+	switch {
+	case action == "" || action == "api/json":
+		writeJSON(w, s.buildJSON(build))
+	case action == "stop":
+		if build.building(now) {
+			build.abortedAt = now
+		}
+	case action == "doDelete":
+		job := build.job
+		for i, item := range job.builds {
+			if item == build {
+				job.builds = append(job.builds[:i], job.builds[i+1:]...)
+				break
+			}
+		}
+	case action == "consoleText":
+		writeText(w, build.log(now))
+	case action == "logText/progressiveText" || action == "logText/progressiveHtml":
+		log := build.log(now)
+		start, _ := strconv.Atoi(r.URL.Query().Get("start"))
+		if start < 0 || start > len(log) {
+			start = len(log)
+		}
+		w.Header().Set("X-Text-Size", strconv.Itoa(len(log)))
+		if build.building(now) {
+			w.Header().Set("X-More-Data", "true")
+		}
+		if action == "logText/progressiveText" {
+			writeText(w, log[start:])
+			return
+		}
+		// the annotators wrap the lines with the tags, the state of them is passed by X-ConsoleAnnotator
+		w.Header().Set("X-ConsoleAnnotator", "fake-annotator")
+		w.Header().Set("Content-Type", "text/html;charset=utf-8")
+		for _, line := range strings.SplitAfter(log[start:], "\n") {
+			if line != "" {
+				_, _ = fmt.Fprintf(w, `<span class="pipeline-node-1">%s</span>`, html.EscapeString(line))
+			}
+		}
+	case action == "wfapi/pendingInputActions":
+		writeJSON(w, []interface{}{})
+	case action == "wfapi/artifacts":
+		paths := make([]string, 0, len(build.scenario.Artifacts))
+		for path := range build.scenario.Artifacts {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+
+		artifacts := make([]map[string]interface{}, 0, len(paths))
+		for i, path := range paths {
+			artifacts = append(artifacts, map[string]interface{}{
+				"id":   fmt.Sprintf("n%d", i),
+				"name": path[strings.LastIndex(path, "/")+1:],
+				"path": path,
+				"url":  fmt.Sprintf("%s%d/artifact/%s", build.job.path(), build.number, path),
+				"size": len(build.scenario.Artifacts[path]),
+			})
+		}
+		writeJSON(w, artifacts)
+	case strings.HasPrefix(action, "artifact/"):
+		content, ok := build.scenario.Artifacts[strings.TrimPrefix(action, "artifact/")]
+		if !ok {
+			notFound(w)
+			return
+		}
+		w.Header().Set("Content-Type", "application/octet-stream")
+		_, _ = w.Write([]byte(content))
+	default:
+		notFound(w)
+	}
+}
+
+// handleItems handles the search API of the jcli plugin
+func (s *Server) handleItems(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	name, kind := strings.ToLower(query.Get("name")), query.Get("type")
+	parent := strings.Trim(query.Get("parent"), "/")
+	start, _ := strconv.Atoi(query.Get("start"))
+	limit, _ := strconv.Atoi(query.Get("limit"))
+
+	names := make([]string, 0, len(s.jobs))
+	for jobName := range s.jobs {
+		names = append(names, jobName)
+	}
+	sort.Strings(names)
+
+	items := make([]map[string]interface{}, 0)
+	for _, jobName := range names {
+		job := s.jobs[jobName]
+		if !strings.Contains(strings.ToLower(job.shortName()), name) ||
+			(kind != "" && job.class != kind) ||
+			(parent != "" && !strings.HasPrefix(jobName, parent+"/")) {
+			continue
+		}
+		items = append(items, map[string]interface{}{
+			"name":        job.shortName(),
+			"displayName": job.shortName(),
+			"fullName":    job.name,
+			"url":         job.path(),
+			"description": job.description,
+			"type":        job.class,
+			"buildable":   !job.isFolder() && !job.disabled,
+			"disabled":    job.disabled,
+		})
+	}
+
+	if start > len(items) {
+		start = len(items)
+	}
+	items = items[start:]
+	if limit > 0 && limit < len(items) {
+		items = items[:limit]
+	}
+	writeJSON(w, items)
+}
+
+// handleQueue handles the requests of the build queue
+func (s *Server) handleQueue(w http.ResponseWriter, r *http.Request, segments []string) {
+	action := strings.Join(segments, "/")
+	switch {
+	case action == "api/json":
+		items := make([]map[string]interface{}, 0)
+		for _, item := range s.pending() {
+			items = append(items, s.queueItemJSON(item))
+		}
+		writeJSON(w, map[string]interface{}{
+			"_class": "hudson.model.Queue",
+			"items":  items,
+		})
+	case action == "cancelItem":
+		if r.Method != http.MethodPost {
+			methodNotAllowed(w)
+			return
+		}
+		id, _ := strconv.Atoi(r.URL.Query().Get("id"))
+		for _, item := range s.pending() {
+			if item.id == id {
+				item.cancelled = true
+			}
+		}
+	case len(segments) >= 2 && segments[0] == "item":
+		id, _ := strconv.Atoi(segments[1])
+		for _, item := range s.queue {
+			if item.id == id {
+				writeJSON(w, s.queueItemJSON(item))
+				return
+			}
+		}
+		notFound(w)
+	default:
+		notFound(w)
+	}
+}
+
+func (s *Server) queueItemJSON(item *queueItem) map[string]interface{} {
+	class, why := "hudson.model.Queue$WaitingItem", ""
+	switch {
+	case item.cancelled:
+		class = "hudson.model.Queue$LeftItem"
+	case item.build != nil:
+		class = "hudson.model.Queue$LeftItem"
+	case item.blocked != "":
+		class, why = "hudson.model.Queue$BlockedItem", item.blocked
+	default:
+		expires := item.since.Add(item.scenario.QueueDelay).Sub(s.now())
+		why = fmt.Sprintf("In the quiet period. Expires in %s", expires.Round(time.Millisecond))
+	}
+
+	result := map[string]interface{}{
+		"_class":       class,
+		"id":           item.id,
+		"blocked":      item.blocked != "" && item.build == nil && !item.cancelled,
+		"buildable":    item.blocked == "" && item.build == nil && !item.cancelled,
+		"stuck":        false,
+		"why":          why,
+		"url":          fmt.Sprintf("queue/item/%d/", item.id),
+		"inQueueSince": millis(item.since),
+		"params":       "",
+		"task": map[string]string{
+			"name": item.job.shortName(),
+			"url":  s.URL + item.job.path(),
+		},
+		"actions": []map[string]interface{}{{
+			"_class": "hudson.model.CauseAction",
+			"causes": []map[string]string{{"shortDescription": item.cause}},
+		}},
+	}
+	if item.cancelled {
+		result["cancelled"] = true
+	}
+	if item.build != nil {
+		result["executable"] = map[string]interface{}{
+			"number": item.build.number,
+			"url":    s.buildURL(item.build),
+		}
+	}
+	return result
+}
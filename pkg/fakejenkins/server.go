@@ -0,0 +1,580 @@
+// Package fakejenkins provides an in-memory Jenkins server for the tests.
+// It implements the endpoints which are used by this client, such as the jobs, builds, queue,
+// views, computers, credentials, plugins, configuration-as-code, and BlueOcean pipelines.
+package fakejenkins
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jenkins-zh/jenkins-client/pkg/core"
+	"github.com/jenkins-zh/jenkins-client/pkg/job"
+)
+
+const (
+	// DefaultVersion is the Jenkins version of the fake server
+	DefaultVersion = "2.387.1"
+	// DefaultCrumbField is the name of the crumb header
+	DefaultCrumbField = "Jenkins-Crumb"
+
+	// ResultSuccess is the result of a successful build
+	ResultSuccess = "SUCCESS"
+	// ResultFailure is the result of a failed build
+	ResultFailure = "FAILURE"
+	// ResultUnstable is the result of an unstable build
+	ResultUnstable = "UNSTABLE"
+	// ResultAborted is the result of an aborted build
+	ResultAborted = "ABORTED"
+)
+
+// Scenario describes how the builds of a job behave
+type Scenario struct {
+	// Result is the final result of the builds, it's SUCCESS if it's empty
+	Result string
+	// Duration is how long the builds take
+	Duration time.Duration
+	// Log is the console output, the lines show up gradually during the build
+	Log []string
+	// Artifacts are the archived files of the builds, the key is the relative path
+	Artifacts map[string]string
+	// QueueDelay is how long the queue items wait before the builds start
+	QueueDelay time.Duration
+	// Blocked is the reason why the queue items are blocked, they stay in the queue until Unblock is called
+	Blocked string
+}
+
+// BuildFailsAfter returns a scenario in which the builds fail after the duration
+func BuildFailsAfter(duration time.Duration, log ...string) Scenario {
+	return Scenario{Result: ResultFailure, Duration: duration, Log: log}
+}
+
+// QueueBlocked returns a scenario in which the queue items are blocked
+func QueueBlocked(why string) Scenario {
+	return Scenario{Blocked: why}
+}
+
+// Option is the option of the fake server
+type Option func(*Server)
+
+// WithUser requires the requests to be authenticated by the user name and API token
+func WithUser(userName, token string) Option {
+	return func(s *Server) {
+		s.users[userName] = token
+	}
+}
+
+// WithoutCrumb disables the crumb issuer, just like the CSRF protection is turned off
+func WithoutCrumb() Option {
+	return func(s *Server) {
+		s.crumb = ""
+	}
+}
+
+// WithVersion sets the Jenkins version which is returned by the X-Jenkins header
+func WithVersion(version string) Option {
+	return func(s *Server) {
+		s.version = version
+	}
+}
+
+// WithClock sets the clock of the server, it's useful to control the progress of the builds
+func WithClock(now func() time.Time) Option {
+	return func(s *Server) {
+		s.now = now
+	}
+}
+
+// Clock is a clock which only moves forward when it's told to, its Now could be passed to WithClock
+type Clock struct {
+	mutex sync.Mutex
+	now   time.Time
+}
+
+// NewClock creates a Clock which starts from the time
+func NewClock(now time.Time) *Clock {
+	return &Clock{now: now}
+}
+
+// Now returns the current time of the clock
+func (c *Clock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.now
+}
+
+// Advance moves the clock by the duration
+func (c *Clock) Advance(duration time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.now = c.now.Add(duration)
+}
+
+// Server is a fake Jenkins server which keeps all the state in memory
+type Server struct {
+	*httptest.Server
+
+	mutex   sync.Mutex
+	now     func() time.Time
+	version string
+	crumb   string
+	users   map[string]string
+
+	jobs        map[string]*fakeJob
+	views       map[string][]*fakeView
+	queue       []*queueItem
+	nextQueueID int
+	computers   map[string]*fakeComputer
+	credentials map[string]map[string]map[string]interface{}
+	plugins     map[string]*fakePlugin
+	available   map[string]string
+	casc        string
+	cascApplied int
+}
+
+type fakeJob struct {
+	name        string
+	class       string
+	description string
+	displayName string
+	disabled    bool
+	// holdOff is true if the job is copied, it cannot be built until its config is saved
+	holdOff   bool
+	config    string
+	scenario  Scenario
+	builds    []*fakeBuild
+	nextBuild int
+}
+
+type fakeBuild struct {
+	job       *fakeJob
+	number    int
+	queueID   int
+	cause     string
+	start     time.Time
+	scenario  Scenario
+	abortedAt time.Time
+}
+
+type queueItem struct {
+	id        int
+	job       *fakeJob
+	cause     string
+	since     time.Time
+	scenario  Scenario
+	blocked   string
+	cancelled bool
+	build     *fakeBuild
+}
+
+type fakeComputer struct {
+	name         string
+	description  string
+	labels       []string
+	numExecutors int
+	offline      bool
+	secret       string
+	log          string
+}
+
+type fakePlugin struct {
+	shortName string
+	version   string
+	enabled   bool
+}
+
+// NewServer starts a fake Jenkins server, the caller should close it
+func NewServer(options ...Option) (s *Server) {
+	s = NewUnstartedServer(options...)
+	s.Start()
+	return
+}
+
+// NewUnstartedServer creates a fake Jenkins server without starting it
+func NewUnstartedServer(options ...Option) (s *Server) {
+	s = &Server{
+		now:         time.Now,
+		version:     DefaultVersion,
+		crumb:       "fake-crumb",
+		users:       map[string]string{},
+		jobs:        map[string]*fakeJob{},
+		views:       map[string][]*fakeView{},
+		nextQueueID: 1,
+		computers: map[string]*fakeComputer{
+			"(built-in)": {name: "(built-in)", numExecutors: 2},
+		},
+		credentials: map[string]map[string]map[string]interface{}{},
+		plugins:     map[string]*fakePlugin{},
+		available:   map[string]string{},
+		casc:        "jenkins:\n  systemMessage: fake Jenkins\n",
+	}
+	for _, option := range options {
+		option(s)
+	}
+	s.Server = httptest.NewUnstartedServer(http.HandlerFunc(s.serveHTTP))
+	return
+}
+
+// JenkinsCore returns a JenkinsCore which connects to the fake server,
+// it's authenticated as the first user in alphabetical order if there are any users
+func (s *Server) JenkinsCore() (jenkinsCore core.JenkinsCore) {
+	jenkinsCore.URL = s.URL
+	userNames := make([]string, 0, len(s.users))
+	for userName := range s.users {
+		userNames = append(userNames, userName)
+	}
+	if len(userNames) > 0 {
+		sort.Strings(userNames)
+		jenkinsCore.UserName, jenkinsCore.Token = userNames[0], s.users[userNames[0]]
+	}
+	return
+}
+
+// JenkinsCoreAs returns a JenkinsCore which is authenticated as the user
+func (s *Server) JenkinsCoreAs(userName string) (jenkinsCore core.JenkinsCore) {
+	jenkinsCore.URL = s.URL
+	jenkinsCore.UserName, jenkinsCore.Token = userName, s.users[userName]
+	return
+}
+
+// CreateJob creates a job with the class, the parent folders should exist
+func (s *Server) CreateJob(name, class string) (err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	_, err = s.createJob(name, class, "")
+	return
+}
+
+// CreateFolder creates a folder
+func (s *Server) CreateFolder(name string) error {
+	return s.CreateJob(name, job.FolderClass)
+}
+
+// SetScenario sets the behavior of the builds of a job, it affects the builds which are not started yet
+func (s *Server) SetScenario(name string, scenario Scenario) (err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var job *fakeJob
+	if job, err = s.getJob(name); err == nil {
+		job.scenario = scenario
+	}
+	return
+}
+
+// Unblock lets the blocked queue items of a job go
+func (s *Server) Unblock(name string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, item := range s.queue {
+		if item.job.name == name {
+			item.blocked = ""
+		}
+	}
+}
+
+// AddComputer adds an agent
+func (s *Server) AddComputer(name string, numExecutors int, labels ...string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.computers[name] = &fakeComputer{
+		name:         name,
+		numExecutors: numExecutors,
+		labels:       labels,
+		offline:      true,
+		secret:       fmt.Sprintf("secret-of-%s", name),
+	}
+}
+
+// AddPlugin adds an installed plugin
+func (s *Server) AddPlugin(name, version string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.plugins[name] = &fakePlugin{shortName: name, version: version, enabled: true}
+}
+
+// AddAvailablePlugin adds a plugin which could be installed from the update center
+func (s *Server) AddAvailablePlugin(name, version string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.available[name] = version
+}
+
+// SetCasC sets the configuration-as-code YAML
+func (s *Server) SetCasC(config string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.casc = config
+}
+
+// CasCApplied returns how many times the configuration-as-code was applied or reloaded
+func (s *Server) CasCApplied() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.cascApplied
+}
+
+// JobNames returns the full names of all the jobs
+func (s *Server) JobNames() (names []string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for name := range s.jobs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return
+}
+
+// Credentials returns the credential IDs of a store, the store of a folder is the full name of the folder
+func (s *Server) Credentials(store string) (ids []string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for id := range s.credentials[store] {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return
+}
+
+func (s *Server) getJob(name string) (job *fakeJob, err error) {
+	var ok bool
+	if job, ok = s.jobs[strings.Trim(name, "/")]; !ok {
+		err = fmt.Errorf("job %s does not exist", name)
+	}
+	return
+}
+
+func (s *Server) createJob(name, class, config string) (item *fakeJob, err error) {
+	name = strings.Trim(name, "/")
+	if name == "" {
+		err = fmt.Errorf("the job name is required")
+		return
+	}
+	if _, ok := s.jobs[name]; ok {
+		err = fmt.Errorf("a job already exists with the name %s", name)
+		return
+	}
+	if index := strings.LastIndex(name, "/"); index > 0 {
+		var parent *fakeJob
+		if parent, err = s.getJob(name[:index]); err != nil {
+			return
+		}
+		if !parent.isFolder() {
+			err = fmt.Errorf("%s is not a folder", parent.name)
+			return
+		}
+	}
+
+	if class == "" {
+		class = job.WorkflowJobClass
+	}
+	if config == "" {
+		config = fmt.Sprintf("<?xml version='1.1' encoding='UTF-8'?>\n<%s/>", rootOfClass(class))
+	}
+	item = &fakeJob{name: name, class: class, config: config, nextBuild: 1}
+	s.jobs[name] = item
+	return
+}
+
+func (s *Server) deleteJob(name string) {
+	for jobName := range s.jobs {
+		if jobName == name || strings.HasPrefix(jobName, name+"/") {
+			delete(s.jobs, jobName)
+		}
+	}
+	s.deleteViews(name)
+}
+
+// relocateJob renames a job and its descendants
+func (s *Server) relocateJob(job *fakeJob, newName string) (err error) {
+	if _, ok := s.jobs[newName]; ok {
+		err = fmt.Errorf("a job already exists with the name %s", newName)
+		return
+	}
+	oldName := job.name
+	var relocated []*fakeJob
+	for name, item := range s.jobs {
+		if name == oldName || strings.HasPrefix(name, oldName+"/") {
+			delete(s.jobs, name)
+			relocated = append(relocated, item)
+		}
+	}
+	for _, item := range relocated {
+		item.name = newName + strings.TrimPrefix(item.name, oldName)
+		s.jobs[item.name] = item
+	}
+	return
+}
+
+// children returns the direct children of a folder, or the top level jobs if the name is empty
+func (s *Server) children(name string) (jobs []*fakeJob) {
+	for jobName, job := range s.jobs {
+		parent := ""
+		if index := strings.LastIndex(jobName, "/"); index > 0 {
+			parent = jobName[:index]
+		}
+		if parent == name {
+			jobs = append(jobs, job)
+		}
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].name < jobs[j].name
+	})
+	return
+}
+
+// enqueue puts a job into the queue
+func (s *Server) enqueue(job *fakeJob, cause string) (item *queueItem) {
+	item = &queueItem{
+		id:       s.nextQueueID,
+		job:      job,
+		cause:    cause,
+		since:    s.now(),
+		scenario: job.scenario,
+		blocked:  job.scenario.Blocked,
+	}
+	s.nextQueueID++
+	s.queue = append(s.queue, item)
+	s.tick()
+	return
+}
+
+// tick starts the builds of the queue items which are ready
+func (s *Server) tick() {
+	now := s.now()
+	for _, item := range s.queue {
+		if item.cancelled || item.build != nil || item.blocked != "" ||
+			now.Before(item.since.Add(item.scenario.QueueDelay)) {
+			continue
+		}
+
+		job := item.job
+		item.build = &fakeBuild{
+			job:      job,
+			number:   job.nextBuild,
+			queueID:  item.id,
+			cause:    item.cause,
+			start:    item.since.Add(item.scenario.QueueDelay),
+			scenario: item.scenario,
+		}
+		job.nextBuild++
+		job.builds = append(job.builds, item.build)
+	}
+}
+
+// pending returns the queue items which are waiting
+func (s *Server) pending() (items []*queueItem) {
+	for _, item := range s.queue {
+		if !item.cancelled && item.build == nil {
+			items = append(items, item)
+		}
+	}
+	return
+}
+
+func (j *fakeJob) isFolder() bool {
+	return j.class == job.FolderClass || j.class == job.WorkflowMultiBranchProjectClass
+}
+
+func (j *fakeJob) shortName() string {
+	return j.name[strings.LastIndex(j.name, "/")+1:]
+}
+
+// path returns the URL path of the job, such as /job/folder/job/name/
+func (j *fakeJob) path() string {
+	return "/job/" + strings.Join(strings.Split(j.name, "/"), "/job/") + "/"
+}
+
+func (j *fakeJob) getBuild(id string) *fakeBuild {
+	if len(j.builds) == 0 {
+		return nil
+	}
+	if id == "lastBuild" {
+		return j.builds[len(j.builds)-1]
+	}
+	for _, build := range j.builds {
+		if fmt.Sprint(build.number) == id {
+			return build
+		}
+	}
+	return nil
+}
+
+// color returns the ball color of the job
+func (j *fakeJob) color(now time.Time) (color string) {
+	if j.disabled {
+		return "disabled"
+	}
+	if len(j.builds) == 0 {
+		return "notbuilt"
+	}
+
+	last := j.builds[len(j.builds)-1]
+	switch last.result(now) {
+	case "", ResultSuccess:
+		color = "blue"
+	case ResultFailure:
+		color = "red"
+	case ResultUnstable:
+		color = "yellow"
+	default:
+		color = "aborted"
+	}
+	if last.building(now) {
+		color += "_anime"
+	}
+	return
+}
+
+func (b *fakeBuild) end() time.Time {
+	if !b.abortedAt.IsZero() {
+		return b.abortedAt
+	}
+	return b.start.Add(b.scenario.Duration)
+}
+
+func (b *fakeBuild) building(now time.Time) bool {
+	return now.Before(b.end())
+}
+
+// result returns the result of the build, it's empty if the build is still running
+func (b *fakeBuild) result(now time.Time) string {
+	switch {
+	case b.building(now):
+		return ""
+	case !b.abortedAt.IsZero():
+		return ResultAborted
+	case b.scenario.Result == "":
+		return ResultSuccess
+	default:
+		return b.scenario.Result
+	}
+}
+
+// log returns the console output which is printed until now
+func (b *fakeBuild) log(now time.Time) string {
+	lines := b.scenario.Log
+	if end := b.end(); now.After(end) {
+		now = end
+	}
+	if elapsed := now.Sub(b.start); elapsed < b.scenario.Duration {
+		// the clock of WithClock might go backwards
+		if elapsed < 0 {
+			elapsed = 0
+		}
+		lines = lines[:int(int64(len(lines))*int64(elapsed)/int64(b.scenario.Duration))]
+	}
+
+	output := &strings.Builder{}
+	output.WriteString(b.cause + "\n")
+	for _, line := range lines {
+		output.WriteString(line + "\n")
+	}
+	if result := b.result(now); result != "" {
+		output.WriteString("Finished: " + result + "\n")
+	}
+	return output.String()
+}
@@ -0,0 +1,383 @@
+package fakejenkins
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// handleComputer handles the requests of the agents
+func (s *Server) handleComputer(w http.ResponseWriter, r *http.Request, segments []string) {
+	action := strings.Join(segments, "/")
+	switch action {
+	case "api/json":
+		s.handleComputerList(w)
+		return
+	case "createItem":
+		_ = r.ParseForm()
+		if _, ok := s.computers[r.Form.Get("name")]; ok {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("Agent called ‘%s’ already exists", r.Form.Get("name")))
+		}
+		return
+	case "doCreateItem":
+		s.handleCreateComputer(w, r)
+		return
+	}
+
+	if len(segments) < 2 {
+		notFound(w)
+		return
+	}
+	computer, ok := s.computers[segments[0]]
+	if !ok {
+		notFound(w)
+		return
+	}
+
+	switch strings.Join(segments[1:], "/") {
+	case "api/json":
+		writeJSON(w, computerJSON(computer))
+	case "launchSlaveAgent":
+		computer.offline = false
+		computer.log += "Agent successfully connected and online\n"
+	case "doDelete":
+		delete(s.computers, computer.name)
+	case "slave-agent.jnlp":
+		w.Header().Set("Content-Type", "application/x-java-jnlp-file")
+		_, _ = fmt.Fprintf(w, `<jnlp codebase="%s/computer/%s/" spec="1.0+"><application-desc main-class="hudson.remoting.jnlp.Main">`+
+			`<argument>%s</argument><argument>%s</argument></application-desc></jnlp>`,
+			s.URL, computer.name, computer.secret, computer.name)
+	case "logText/progressiveText":
+		w.Header().Set("X-Text-Size", strconv.Itoa(len(computer.log)))
+		writeText(w, computer.log)
+	default:
+		notFound(w)
+	}
+}
+
+func (s *Server) handleComputerList(w http.ResponseWriter) {
+	names := make([]string, 0, len(s.computers))
+	for name := range s.computers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	computers := make([]map[string]interface{}, 0, len(names))
+	total := 0
+	for _, name := range names {
+		computer := s.computers[name]
+		computers = append(computers, computerJSON(computer))
+		if !computer.offline {
+			total += computer.numExecutors
+		}
+	}
+
+	busy := 0
+	now := s.now()
+	for _, job := range s.jobs {
+		for _, build := range job.builds {
+			if build.building(now) {
+				busy++
+			}
+		}
+	}
+	writeJSON(w, map[string]interface{}{
+		"_class":         "hudson.model.ComputerSet",
+		"busyExecutors":  busy,
+		"computer":       computers,
+		"displayName":    "Nodes",
+		"totalExecutors": total,
+	})
+}
+
+func computerJSON(computer *fakeComputer) map[string]interface{} {
+	labels := []map[string]string{{"name": computer.name}}
+	for _, label := range computer.labels {
+		labels = append(labels, map[string]string{"name": label})
+	}
+	return map[string]interface{}{
+		"_class":              "hudson.slaves.SlaveComputer",
+		"assignedLabels":      labels,
+		"description":         computer.description,
+		"displayName":         computer.name,
+		"idle":                true,
+		"jnlpAgent":           computer.secret != "",
+		"launchSupported":     computer.secret != "",
+		"manualLaunchAllowed": true,
+		"numExecutors":        computer.numExecutors,
+		"offline":             computer.offline,
+		"temporarilyOffline":  false,
+	}
+}
+
+func (s *Server) handleCreateComputer(w http.ResponseWriter, r *http.Request) {
+	_ = r.ParseForm()
+	name := r.Form.Get("name")
+	if _, ok := s.computers[name]; ok || name == "" {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("Agent called ‘%s’ already exists", name))
+		return
+	}
+
+	payload := struct {
+		NodeDescription string `json:"nodeDescription"`
+		NumExecutors    string `json:"numExecutors"`
+		LabelString     string `json:"labelString"`
+	}{}
+	if err := json.Unmarshal([]byte(r.Form.Get("json")), &payload); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	numExecutors, _ := strconv.Atoi(payload.NumExecutors)
+	s.computers[name] = &fakeComputer{
+		name:         name,
+		description:  payload.NodeDescription,
+		labels:       strings.Fields(payload.LabelString),
+		numExecutors: numExecutors,
+		offline:      true,
+		secret:       fmt.Sprintf("secret-of-%s", name),
+	}
+}
+
+// handleCredentials handles the requests of a credentials store, the folder is empty for the system stores.
+// The segments are the path after /credentials, such as store/system/domain/_/api/json
+func (s *Server) handleCredentials(w http.ResponseWriter, r *http.Request, segments []string, folder string) {
+	if len(segments) < 4 || segments[0] != "store" || segments[2] != "domain" || segments[3] != "_" {
+		notFound(w)
+		return
+	}
+	store := segments[1]
+	if folder != "" {
+		store = folder
+	}
+	credentials := s.credentials[store]
+
+	action := strings.Join(segments[4:], "/")
+	switch {
+	case action == "api/json":
+		list := make([]map[string]interface{}, 0, len(credentials))
+		for _, id := range sortedKeys(credentials) {
+			list = append(list, credentialJSON(store, credentials[id]))
+		}
+		writeJSON(w, map[string]interface{}{
+			"_class":          "com.cloudbees.plugins.credentials.CredentialsStoreAction$DomainWrapper",
+			"credentials":     list,
+			"description":     "Credentials that should be available irrespective of domain specification",
+			"displayName":     "Global credentials (unrestricted)",
+			"fullDisplayName": "Global credentials (unrestricted)",
+			"fullName":        store + "/_",
+			"global":          true,
+			"urlName":         "_",
+		})
+	case action == "createCredentials":
+		credential, err := parseCredential(r, "credentials")
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		id := fmt.Sprint(credential["id"])
+		if credential["id"] == nil || id == "" {
+			id = fmt.Sprintf("credential-%d", len(credentials)+1)
+			credential["id"] = id
+		}
+		if _, ok := credentials[id]; ok {
+			writeError(w, http.StatusConflict, fmt.Sprintf("A credential with the ID %s already exists", id))
+			return
+		}
+		if credentials == nil {
+			credentials = map[string]map[string]interface{}{}
+			s.credentials[store] = credentials
+		}
+		credentials[id] = credential
+	case len(segments) >= 6 && segments[4] == "credential":
+		id := segments[5]
+		credential, ok := credentials[id]
+		if !ok {
+			notFound(w)
+			return
+		}
+
+		switch strings.Join(segments[6:], "/") {
+		case "", "api/json":
+			writeJSON(w, credentialJSON(store, credential))
+		case "doDelete":
+			delete(credentials, id)
+		case "updateSubmit":
+			updated, err := parseCredential(r, "")
+			if err != nil {
+				writeError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			updated["id"] = id
+			credentials[id] = updated
+		default:
+			notFound(w)
+		}
+	default:
+		notFound(w)
+	}
+}
+
+// parseCredential parses the credential from the json field of the form, the key is the field which wraps the credential
+func parseCredential(r *http.Request, key string) (credential map[string]interface{}, err error) {
+	_ = r.ParseForm()
+	data := []byte(r.Form.Get("json"))
+	if key == "" {
+		err = json.Unmarshal(data, &credential)
+		return
+	}
+
+	wrapper := map[string]map[string]interface{}{}
+	if err = json.Unmarshal(data, &wrapper); err == nil {
+		if credential = wrapper[key]; credential == nil {
+			err = fmt.Errorf("the field %s is required", key)
+		}
+	}
+	return
+}
+
+// credentialJSON returns the credential without the secrets
+func credentialJSON(store string, credential map[string]interface{}) map[string]interface{} {
+	class := fmt.Sprint(credential["stapler-class"])
+	if credential["stapler-class"] == nil {
+		class = fmt.Sprint(credential["$class"])
+	}
+	id := fmt.Sprint(credential["id"])
+	displayName := id
+	if userName, ok := credential["username"]; ok {
+		displayName = fmt.Sprintf("%v/******", userName)
+	}
+	return map[string]interface{}{
+		"_class":      "com.cloudbees.plugins.credentials.CredentialsStoreAction$CredentialsWrapper",
+		"id":          id,
+		"description": credential["description"],
+		"displayName": displayName,
+		"fullName":    fmt.Sprintf("%s/_/%s", store, id),
+		"typeName":    class[strings.LastIndexAny(class, ".$")+1:],
+	}
+}
+
+func sortedKeys(credentials map[string]map[string]interface{}) (keys []string) {
+	for key := range credentials {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return
+}
+
+// handlePluginManager handles the requests of the plugin manager
+func (s *Server) handlePluginManager(w http.ResponseWriter, r *http.Request, segments []string) {
+	action := strings.Join(segments, "/")
+	switch {
+	case action == "api/json":
+		names := make([]string, 0, len(s.plugins))
+		for name := range s.plugins {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		plugins := make([]map[string]interface{}, 0, len(names))
+		for _, name := range names {
+			plugin := s.plugins[name]
+			_, hasUpdate := s.available[name]
+			plugins = append(plugins, map[string]interface{}{
+				"active":       plugin.enabled,
+				"enabled":      plugin.enabled,
+				"shortName":    plugin.shortName,
+				"longName":     plugin.shortName,
+				"version":      plugin.version,
+				"hasUpdate":    hasUpdate && s.available[name] != plugin.version,
+				"pinned":       false,
+				"dependencies": []interface{}{},
+			})
+		}
+		writeJSON(w, map[string]interface{}{"plugins": plugins})
+	case action == "plugins":
+		names := make([]string, 0, len(s.available))
+		for name := range s.available {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		data := make([]map[string]interface{}, 0, len(names))
+		for _, name := range names {
+			_, installed := s.plugins[name]
+			data = append(data, map[string]interface{}{
+				"name":      name,
+				"title":     name,
+				"version":   s.available[name],
+				"installed": installed,
+			})
+		}
+		writeJSON(w, map[string]interface{}{"status": "ok", "data": data})
+	case action == "install":
+		var names []string
+		for key := range r.URL.Query() {
+			if strings.HasPrefix(key, "plugin.") {
+				names = append(names, strings.TrimPrefix(key, "plugin."))
+			}
+		}
+		for _, name := range names {
+			if _, ok := s.available[name]; !ok {
+				writeError(w, http.StatusBadRequest, "No such plugin: "+name)
+				return
+			}
+		}
+		for _, name := range names {
+			s.plugins[name] = &fakePlugin{shortName: name, version: s.available[name], enabled: true}
+		}
+	case action == "uploadPlugin":
+		_, header, err := r.FormFile("@name")
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		name := strings.TrimSuffix(filepath.Base(header.Filename), filepath.Ext(header.Filename))
+		s.plugins[name] = &fakePlugin{shortName: name, version: "uploaded", enabled: true}
+	case action == "checkUpdatesServer":
+	case len(segments) == 3 && segments[0] == "plugin" && segments[2] == "doUninstall":
+		if _, ok := s.plugins[segments[1]]; !ok {
+			notFound(w)
+			return
+		}
+		delete(s.plugins, segments[1])
+	default:
+		notFound(w)
+	}
+}
+
+// handleCasC handles the requests of the configuration-as-code plugin
+func (s *Server) handleCasC(w http.ResponseWriter, r *http.Request, segments []string) {
+	if len(segments) != 1 {
+		notFound(w)
+		return
+	}
+
+	switch segments[0] {
+	case "export":
+		w.Header().Set("Content-Type", "application/x-yaml")
+		_, _ = w.Write([]byte(s.casc))
+	case "schema":
+		writeJSON(w, map[string]interface{}{
+			"$schema": "http://json-schema.org/draft-07/schema#",
+			"type":    "object",
+		})
+	case "reload", "apply":
+		s.cascApplied++
+	case "replace":
+		_ = r.ParseForm()
+		if r.Form.Get("_.newSource") == "" {
+			writeError(w, http.StatusBadRequest, "the new source is required")
+		}
+	case "checkNewSource":
+		_ = r.ParseForm()
+		if r.Form.Get("newSource") == "" {
+			writeError(w, http.StatusBadRequest, "the new source is required")
+		}
+	default:
+		notFound(w)
+	}
+}
@@ -0,0 +1,348 @@
+package fakejenkins
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/jenkins-zh/jenkins-client/pkg/view"
+)
+
+// the view classes are the ones of the client, then the fake server and the client can't drift apart
+const (
+	// ListViewClass is the class of the list view
+	ListViewClass = view.ListViewClass
+	// AllViewClass is the class of the primary view which shows all the jobs
+	AllViewClass = view.AllViewClass
+	// NestedViewClass is the class of the view which contains other views
+	NestedViewClass = view.NestedViewClass
+)
+
+type fakeView struct {
+	name         string
+	class        string
+	description  string
+	config       string
+	jobNames     []string
+	includeRegex string
+	recurse      bool
+	views        []*fakeView
+}
+
+// viewFields are the fields of a view config.xml which affect the jobs of the view
+type viewFields struct {
+	Description  string   `xml:"description"`
+	JobNames     []string `xml:"jobNames>string"`
+	IncludeRegex string   `xml:"includeRegex"`
+	Recurse      bool     `xml:"recurse"`
+}
+
+// ownerViews returns the views of Jenkins or a folder, the primary view is created at the first time
+func (s *Server) ownerViews(owner string) []*fakeView {
+	views, ok := s.views[owner]
+	if !ok {
+		name := "all"
+		if owner != "" {
+			name = "All"
+		}
+		views = []*fakeView{{
+			name:   name,
+			class:  AllViewClass,
+			config: fmt.Sprintf("<?xml version='1.1' encoding='UTF-8'?>\n<%s>\n  <name>%s</name>\n</%s>", AllViewClass, name, AllViewClass),
+		}}
+		s.views[owner] = views
+	}
+	return views
+}
+
+// deleteViews removes the views of a folder and its descendants
+func (s *Server) deleteViews(name string) {
+	for owner := range s.views {
+		if owner != "" && (owner == name || strings.HasPrefix(owner, name+"/")) {
+			delete(s.views, owner)
+		}
+	}
+}
+
+func findView(views []*fakeView, name string) *fakeView {
+	for _, view := range views {
+		if view.name == name {
+			return view
+		}
+	}
+	return nil
+}
+
+// handleViews handles the requests of the views, such as /view/parent/view/child/api/json.
+// The jobs are not scoped by the views, so /view/all/job/name is the same as /job/name.
+func (s *Server) handleViews(w http.ResponseWriter, r *http.Request, owner string, segments []string) {
+	views := s.ownerViews(owner)
+	var (
+		view   *fakeView
+		parent *fakeView
+		path   = ownerPath(owner)
+	)
+	for len(segments) > 0 {
+		if view = findView(views, segments[0]); view == nil {
+			notFound(w)
+			return
+		}
+		path += "/view/" + url.PathEscape(view.name)
+		segments = segments[1:]
+		if len(segments) < 2 || segments[0] != "view" {
+			break
+		}
+		parent, views = view, view.views
+		segments = segments[1:]
+	}
+	if view == nil {
+		notFound(w)
+		return
+	}
+
+	if len(segments) > 0 && (segments[0] == "job" || segments[0] == "createItem") {
+		s.handleJob(w, r, append(splitPath(ownerPath(owner)), segments...))
+		return
+	}
+
+	action := strings.Join(segments, "/")
+	switch action {
+	case "", "api/json":
+		writeJSON(w, s.viewJSON(owner, view, path))
+	case "config.xml":
+		s.handleViewConfigXML(w, r, view)
+	case "addJobToView", "removeJobFromView":
+		s.handleViewJob(w, r, owner, view, action)
+	case "createView":
+		if view.class != NestedViewClass {
+			notFound(w)
+			return
+		}
+		s.handleCreateView(w, r, owner, view)
+	case "doDelete":
+		if r.Method != http.MethodPost {
+			methodNotAllowed(w)
+			return
+		}
+		if parent == nil && view == s.views[owner][0] {
+			writeError(w, http.StatusBadRequest, "Cannot delete primary view")
+			return
+		}
+		if parent == nil {
+			s.views[owner] = removeView(s.views[owner], view)
+		} else {
+			parent.views = removeView(parent.views, view)
+		}
+		http.Redirect(w, r, ownerPath(owner)+"/", http.StatusFound)
+	default:
+		notFound(w)
+	}
+}
+
+func removeView(views []*fakeView, target *fakeView) (result []*fakeView) {
+	for _, view := range views {
+		if view != target {
+			result = append(result, view)
+		}
+	}
+	return
+}
+
+// ownerPath returns the URL path of the folder which owns the views, it's empty for Jenkins
+func ownerPath(owner string) string {
+	if owner == "" {
+		return ""
+	}
+	return "/job/" + strings.Join(strings.Split(owner, "/"), "/job/")
+}
+
+// handleCreateView creates a view from a config.xml, the parent is nil for the top level views
+func (s *Server) handleCreateView(w http.ResponseWriter, r *http.Request, owner string, parent *fakeView) {
+	if r.Method != http.MethodPost {
+		methodNotAllowed(w)
+		return
+	}
+	if !strings.Contains(r.Header.Get("Content-Type"), "xml") {
+		writeError(w, http.StatusBadRequest, "only the config.xml is supported")
+		return
+	}
+	name := r.URL.Query().Get("name")
+	if name == "" || strings.Contains(name, "/") {
+		writeError(w, http.StatusBadRequest, "Invalid view name: "+name)
+		return
+	}
+
+	siblings := s.ownerViews(owner)
+	if parent != nil {
+		siblings = parent.views
+	}
+	if findView(siblings, name) != nil {
+		writeError(w, http.StatusBadRequest, "A view already exists with the name "+name)
+		return
+	}
+
+	data, _ := ioutil.ReadAll(r.Body)
+	view := &fakeView{name: name, class: strings.ReplaceAll(classOfConfig(string(data)), "__", "_")}
+	if err := view.setConfig(string(data)); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if parent == nil {
+		s.views[owner] = append(siblings, view)
+	} else {
+		parent.views = append(siblings, view)
+	}
+}
+
+// setConfig keeps the config.xml, and parses the fields of it
+func (v *fakeView) setConfig(config string) (err error) {
+	var fields viewFields
+	if err = xml.Unmarshal([]byte(stripDeclaration(config)), &fields); err != nil {
+		return
+	}
+	if fields.IncludeRegex != "" {
+		if _, err = regexp.Compile(fields.IncludeRegex); err != nil {
+			return
+		}
+	}
+	v.config = config
+	v.description, v.jobNames = fields.Description, fields.JobNames
+	v.includeRegex, v.recurse = fields.IncludeRegex, fields.Recurse
+	return
+}
+
+func (s *Server) handleViewConfigXML(w http.ResponseWriter, r *http.Request, view *fakeView) {
+	switch r.Method {
+	case http.MethodGet:
+		w.Header().Set("Content-Type", "application/xml")
+		_, _ = w.Write([]byte(view.config))
+	case http.MethodPost:
+		data, _ := ioutil.ReadAll(r.Body)
+		if err := view.setConfig(string(data)); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+		}
+	default:
+		methodNotAllowed(w)
+	}
+}
+
+// handleViewJob adds a job to a list view or removes it, the job name is relative to the owner
+func (s *Server) handleViewJob(w http.ResponseWriter, r *http.Request, owner string, view *fakeView, action string) {
+	if r.Method != http.MethodPost {
+		methodNotAllowed(w)
+		return
+	}
+	if view.class != ListViewClass {
+		notFound(w)
+		return
+	}
+	name := r.URL.Query().Get("name")
+	fullName := name
+	if owner != "" {
+		fullName = owner + "/" + name
+	}
+	if _, err := s.getJob(fullName); err != nil {
+		writeError(w, http.StatusBadRequest, "Query parameter 'name' does not correspond to a known item")
+		return
+	}
+
+	jobNames := make([]string, 0, len(view.jobNames)+1)
+	for _, jobName := range view.jobNames {
+		if jobName != name {
+			jobNames = append(jobNames, jobName)
+		}
+	}
+	if action == "addJobToView" {
+		jobNames = append(jobNames, name)
+		sort.Strings(jobNames)
+	}
+	view.jobNames = jobNames
+}
+
+// viewSummary returns the fields of a view in the list
+func (s *Server) viewSummary(view *fakeView, path string) map[string]interface{} {
+	return map[string]interface{}{
+		"_class":      view.class,
+		"name":        view.name,
+		"url":         s.URL + path + "/",
+		"description": view.description,
+	}
+}
+
+// viewsJSON returns the summaries of the views of Jenkins or a folder
+func (s *Server) viewsJSON(owner string) []map[string]interface{} {
+	views := make([]map[string]interface{}, 0)
+	for _, view := range s.ownerViews(owner) {
+		views = append(views, s.viewSummary(view, ownerPath(owner)+"/view/"+url.PathEscape(view.name)))
+	}
+	return views
+}
+
+func (s *Server) viewJSON(owner string, view *fakeView, path string) map[string]interface{} {
+	result := s.viewSummary(view, path)
+	jobs := make([]map[string]interface{}, 0)
+	for _, job := range s.viewJobs(owner, view) {
+		summary := s.jobSummary(job)
+		summary["fullName"] = job.name
+		jobs = append(jobs, summary)
+	}
+	result["jobs"] = jobs
+	if view.class == NestedViewClass {
+		views := make([]map[string]interface{}, 0)
+		for _, child := range view.views {
+			views = append(views, s.viewSummary(child, path+"/view/"+url.PathEscape(child.name)))
+		}
+		result["views"] = views
+	}
+	return result
+}
+
+// viewJobs returns the jobs of a view, the list views contain the chosen jobs and the ones which match the regex
+func (s *Server) viewJobs(owner string, view *fakeView) (jobs []*fakeJob) {
+	switch view.class {
+	case NestedViewClass:
+		return
+	case ListViewClass:
+	default:
+		return s.children(owner)
+	}
+
+	var pattern *regexp.Regexp
+	if view.includeRegex != "" {
+		pattern = regexp.MustCompile("^(?:" + view.includeRegex + ")$")
+	}
+	prefix := ""
+	if owner != "" {
+		prefix = owner + "/"
+	}
+	for name, job := range s.jobs {
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		relative := strings.TrimPrefix(name, prefix)
+		if !view.recurse && strings.Contains(relative, "/") {
+			continue
+		}
+		if containsName(view.jobNames, relative) || (pattern != nil && pattern.MatchString(relative)) {
+			jobs = append(jobs, job)
+		}
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].name < jobs[j].name
+	})
+	return
+}
+
+func containsName(names []string, name string) bool {
+	for _, item := range names {
+		if item == name {
+			return true
+		}
+	}
+	return false
+}
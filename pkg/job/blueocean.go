@@ -1,6 +1,7 @@
 package job
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -32,9 +33,14 @@ type Parameter struct {
 
 // GetPipelines returns the Pipeline list which comes from the possible nest folders
 func (c *BlueOceanClient) GetPipelines(folders ...string) (pipelines []Pipeline, err error) {
+	return c.GetPipelinesWithContext(context.Background(), folders...)
+}
+
+// GetPipelinesWithContext returns the Pipeline list which comes from the possible nest folders with a context
+func (c *BlueOceanClient) GetPipelinesWithContext(ctx context.Context, folders ...string) (pipelines []Pipeline, err error) {
 	api := c.getPipelineAPI(folders...)
-	err = c.RequestWithData(http.MethodGet, api,
-		nil, nil, 200, &pipelines)
+	err = c.request(ctx, http.MethodGet, api,
+		nil, nil, &pipelines)
 	return
 }
 
@@ -48,9 +54,14 @@ func (c *BlueOceanClient) getPipelineAPI(folders ...string) (api string) {
 
 // GetPipeline obtains Pipeline metadata with Pipeline name and folders.
 func (c *BlueOceanClient) GetPipeline(pipelineName string, folders ...string) (*Pipeline, error) {
+	return c.GetPipelineWithContext(context.Background(), pipelineName, folders...)
+}
+
+// GetPipelineWithContext obtains Pipeline metadata with Pipeline name and folders with a context.
+func (c *BlueOceanClient) GetPipelineWithContext(ctx context.Context, pipelineName string, folders ...string) (*Pipeline, error) {
 	api := c.getGetPipelineAPI(pipelineName, folders...)
 	pipeline := &Pipeline{}
-	if err := c.RequestWithData(http.MethodGet, api, nil, nil, 200, pipeline); err != nil {
+	if err := c.request(ctx, http.MethodGet, api, nil, nil, pipeline); err != nil {
 		return nil, err
 	}
 	return pipeline, nil
@@ -67,10 +78,15 @@ func (c *BlueOceanClient) getGetPipelineAPI(pipelineName string, folders ...stri
 
 // Search searches jobs via the BlueOcean API
 func (c *BlueOceanClient) Search(name string, start, limit int) (items []JenkinsItem, err error) {
+	return c.SearchWithContext(context.Background(), name, start, limit)
+}
+
+// SearchWithContext searches jobs via the BlueOcean API with a context
+func (c *BlueOceanClient) SearchWithContext(ctx context.Context, name string, start, limit int) (items []JenkinsItem, err error) {
 	api := fmt.Sprintf("%s/?q=pipeline:*%s*;type:pipeline;organization:%s;excludedFromFlattening=jenkins.branch.MultiBranchProject,com.cloudbees.hudson.plugins.folder.AbstractFolder&filter=no-folders&start=%d&limit=%d",
 		searchAPIPrefix, name, c.Organization, start, limit)
-	err = c.RequestWithData(http.MethodGet, api,
-		nil, nil, 200, &items)
+	err = c.request(ctx, http.MethodGet, api,
+		nil, nil, &items)
 	return
 }
 
@@ -83,6 +99,11 @@ type BuildOption struct {
 
 // Build builds a pipeline for specific organization and pipelines.
 func (c *BlueOceanClient) Build(option BuildOption) (*PipelineRun, error) {
+	return c.BuildWithContext(context.Background(), option)
+}
+
+// BuildWithContext builds a pipeline for specific organization and pipelines with a context.
+func (c *BlueOceanClient) BuildWithContext(ctx context.Context, option BuildOption) (*PipelineRun, error) {
 	var pr PipelineRun
 	var payloadReader io.Reader
 	// we allow developers to pass an empty parameters, but nil parameters
@@ -93,7 +114,7 @@ func (c *BlueOceanClient) Build(option BuildOption) (*PipelineRun, error) {
 		})
 		payloadReader = strings.NewReader(string(payloadBytes))
 	}
-	err := c.RequestWithData(http.MethodPost, c.getBuildAPI(option), getHeaders(), payloadReader, 200, &pr)
+	err := c.request(ctx, http.MethodPost, c.getBuildAPI(option), getHeaders(), payloadReader, &pr)
 	if err != nil {
 		return nil, err
 	}
@@ -119,8 +140,13 @@ type GetBuildOption struct {
 
 // GetBuild gets build result for specific organization, run ID and pipelines.
 func (c *BlueOceanClient) GetBuild(option GetBuildOption) (*PipelineRun, error) {
+	return c.GetBuildWithContext(context.Background(), option)
+}
+
+// GetBuildWithContext gets build result for specific organization, run ID and pipelines with a context.
+func (c *BlueOceanClient) GetBuildWithContext(ctx context.Context, option GetBuildOption) (*PipelineRun, error) {
 	var pr PipelineRun
-	err := c.RequestWithData(http.MethodGet, c.getGetBuildAPI(option), getHeaders(), nil, 200, &pr)
+	err := c.request(ctx, http.MethodGet, c.getGetBuildAPI(option), getHeaders(), nil, &pr)
 	if err != nil {
 		return nil, err
 	}
@@ -129,10 +155,15 @@ func (c *BlueOceanClient) GetBuild(option GetBuildOption) (*PipelineRun, error)
 
 // GetPipelineRuns returns a PipelineRun which in the possible nest folders
 func (c *BlueOceanClient) GetPipelineRuns(pipeline string, folders ...string) (runs []PipelineRun, err error) {
+	return c.GetPipelineRunsWithContext(context.Background(), pipeline, folders...)
+}
+
+// GetPipelineRunsWithContext returns a PipelineRun which in the possible nest folders with a context
+func (c *BlueOceanClient) GetPipelineRunsWithContext(ctx context.Context, pipeline string, folders ...string) (runs []PipelineRun, err error) {
 	api := c.getPipelineAPI(folders...)
 	api = fmt.Sprintf("%s/%s/runs/", api, pipeline)
-	err = c.RequestWithData(http.MethodGet, api,
-		nil, nil, 200, &runs)
+	err = c.request(ctx, http.MethodGet, api,
+		nil, nil, &runs)
 	return
 }
 
@@ -155,8 +186,13 @@ type GetNodesOption struct {
 
 // GetNodes gets nodes details
 func (c *BlueOceanClient) GetNodes(option GetNodesOption) ([]Node, error) {
+	return c.GetNodesWithContext(context.Background(), option)
+}
+
+// GetNodesWithContext gets nodes details with a context
+func (c *BlueOceanClient) GetNodesWithContext(ctx context.Context, option GetNodesOption) ([]Node, error) {
 	var nodes []Node
-	err := c.RequestWithData(http.MethodGet, c.getGetNodesAPI(option), getHeaders(), nil, 200, &nodes)
+	err := c.request(ctx, http.MethodGet, c.getGetNodesAPI(option), getHeaders(), nil, &nodes)
 	if err != nil {
 		return nil, err
 	}
@@ -188,8 +224,14 @@ type ReplayOption struct {
 // Replay will queue up a replay of the pipeline run with the same commit id as the run used.
 // Reference: https://github.com/jenkinsci/blueocean-plugin/tree/master/blueocean-rest#replay-a-pipeline-build
 func (c *BlueOceanClient) Replay(option ReplayOption) (*PipelineRun, error) {
+	return c.ReplayWithContext(context.Background(), option)
+}
+
+// ReplayWithContext will queue up a replay of the pipeline run with the same commit id as the run used with a context.
+// Reference: https://github.com/jenkinsci/blueocean-plugin/tree/master/blueocean-rest#replay-a-pipeline-build
+func (c *BlueOceanClient) ReplayWithContext(ctx context.Context, option ReplayOption) (*PipelineRun, error) {
 	pipelineRun := &PipelineRun{}
-	if err := c.RequestWithData(http.MethodPost, c.getReplayAPI(&option), getHeaders(), nil, 200, pipelineRun); err != nil {
+	if err := c.request(ctx, http.MethodPost, c.getReplayAPI(&option), getHeaders(), nil, pipelineRun); err != nil {
 		return nil, err
 	}
 	return pipelineRun, nil
@@ -205,6 +247,15 @@ func (c *BlueOceanClient) getReplayAPI(option *ReplayOption) string {
 	return api
 }
 
+// request sends the request to the BlueOcean REST API, it fails fast if the BlueOcean plugin is missing
+func (c *BlueOceanClient) request(ctx context.Context, method, api string, headers map[string]string,
+	payload io.Reader, obj interface{}) (err error) {
+	if err = c.Require(ctx, core.RequireBlueOcean); err == nil {
+		err = c.RequestWithDataWithContext(ctx, method, api, headers, payload, http.StatusOK, obj)
+	}
+	return
+}
+
 func getHeaders() map[string]string {
 	return map[string]string{
 		"Content-Type": "application/json",
@@ -223,9 +274,15 @@ type GetStepsOption struct {
 // GetSteps returns all steps of the given Pipeline.
 // Reference: https://github.com/jenkinsci/blueocean-plugin/tree/master/blueocean-rest#get-pipeline-steps
 func (c *BlueOceanClient) GetSteps(option GetStepsOption) ([]Step, error) {
+	return c.GetStepsWithContext(context.Background(), option)
+}
+
+// GetStepsWithContext returns all steps of the given Pipeline with a context.
+// Reference: https://github.com/jenkinsci/blueocean-plugin/tree/master/blueocean-rest#get-pipeline-steps
+func (c *BlueOceanClient) GetStepsWithContext(ctx context.Context, option GetStepsOption) ([]Step, error) {
 	api := c.getGetStepsAPI(&option)
 	steps := make([]Step, 0)
-	if err := c.RequestWithData(http.MethodGet, api, nil, nil, 200, &steps); err != nil {
+	if err := c.request(ctx, http.MethodGet, api, nil, nil, &steps); err != nil {
 		return nil, err
 	}
 	return steps, nil
@@ -269,9 +326,14 @@ type GetBranchesOption struct {
 
 // GetBranches gets branches of a Pipeline.
 func (c *BlueOceanClient) GetBranches(option GetBranchesOption) ([]PipelineBranch, error) {
+	return c.GetBranchesWithContext(context.Background(), option)
+}
+
+// GetBranchesWithContext gets branches of a Pipeline with a context.
+func (c *BlueOceanClient) GetBranchesWithContext(ctx context.Context, option GetBranchesOption) ([]PipelineBranch, error) {
 	api := c.getGetBranchesAPI(&option)
 	branches := []PipelineBranch{}
-	if err := c.RequestWithData(http.MethodGet, api, nil, nil, http.StatusOK, &branches); err != nil {
+	if err := c.request(ctx, http.MethodGet, api, nil, nil, &branches); err != nil {
 		return nil, err
 	}
 	return branches, nil
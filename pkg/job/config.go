@@ -0,0 +1,141 @@
+package job
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"github.com/jenkins-zh/jenkins-client/pkg/core"
+)
+
+// ConfigHeader is the XML declaration which Jenkins writes in front of a config.xml
+const ConfigHeader = "<?xml version='1.1' encoding='UTF-8'?>\n"
+
+// GetConfigXML returns the config.xml of a job
+func (q *Client) GetConfigXML(name string) (config string, err error) {
+	return q.GetConfigXMLWithContext(context.Background(), name)
+}
+
+// GetConfigXMLWithContext returns the config.xml of a job with a context
+func (q *Client) GetConfigXMLWithContext(ctx context.Context, name string) (config string, err error) {
+	request := core.NewRequest(fmt.Sprintf("%s/config.xml", ParseJobPath(name)), &q.JenkinsCore)
+	if err = request.DoWithContext(ctx); err == nil {
+		config = string(request.GetData())
+	}
+	return
+}
+
+// UpdateConfigXML replaces the config.xml of a job
+func (q *Client) UpdateConfigXML(name, config string) (err error) {
+	return q.UpdateConfigXMLWithContext(context.Background(), name, config)
+}
+
+// UpdateConfigXMLWithContext replaces the config.xml of a job with a context
+func (q *Client) UpdateConfigXMLWithContext(ctx context.Context, name, config string) (err error) {
+	request := core.NewRequest(fmt.Sprintf("%s/config.xml", ParseJobPath(name)), &q.JenkinsCore)
+	request.WithPostMethod().AddHeader("Content-Type", "application/xml").
+		WithPayload(strings.NewReader(config))
+	err = request.DoWithContext(ctx)
+	return
+}
+
+// GetConfig returns the typed config.xml of a job
+func (q *Client) GetConfig(name string) (config ItemConfig, err error) {
+	return q.GetConfigWithContext(context.Background(), name)
+}
+
+// GetConfigWithContext returns the typed config.xml of a job with a context.
+// The type of the config depends on the job, such as *WorkflowJob.
+func (q *Client) GetConfigWithContext(ctx context.Context, name string) (config ItemConfig, err error) {
+	var data string
+	if data, err = q.GetConfigXMLWithContext(ctx, name); err == nil {
+		config, err = ParseConfig([]byte(data))
+	}
+	return
+}
+
+// UpdateConfig replaces the config.xml of a job with the typed one
+func (q *Client) UpdateConfig(name string, config ItemConfig) (err error) {
+	return q.UpdateConfigWithContext(context.Background(), name, config)
+}
+
+// UpdateConfigWithContext replaces the config.xml of a job with the typed one with a context
+func (q *Client) UpdateConfigWithContext(ctx context.Context, name string, config ItemConfig) (err error) {
+	var data []byte
+	if data, err = MarshalConfig(config); err == nil {
+		err = q.UpdateConfigXMLWithContext(ctx, name, string(data))
+	}
+	return
+}
+
+// ParseConfig decodes a config.xml by its root element. The elements which are not modeled are kept,
+// then they are written back by MarshalConfig.
+func ParseConfig(data []byte) (config ItemConfig, err error) {
+	data = stripXMLDeclaration(data)
+
+	var root string
+	if root, err = rootElement(data); err != nil {
+		return
+	}
+	switch root {
+	case "flow-definition":
+		config = &WorkflowJob{}
+	case "project":
+		config = &FreeStyleProject{}
+	case FolderClass:
+		config = &Folder{}
+	case WorkflowMultiBranchProjectClass:
+		config = &WorkflowMultiBranchProject{}
+	default:
+		err = fmt.Errorf("unsupported config.xml with the root element %q, please use GetConfigXML instead", root)
+		return
+	}
+
+	if err = xml.Unmarshal(data, config); err != nil {
+		config = nil
+		err = fmt.Errorf("cannot decode the config.xml, error is %v", err)
+	}
+	return
+}
+
+// MarshalConfig encodes a typed config.xml, it starts with ConfigHeader
+func MarshalConfig(config ItemConfig) (data []byte, err error) {
+	buf := bytes.NewBufferString(ConfigHeader)
+	encoder := xml.NewEncoder(buf)
+	encoder.Indent("", "  ")
+	if err = encoder.Encode(config); err == nil {
+		buf.WriteString("\n")
+		data = buf.Bytes()
+	}
+	return
+}
+
+// stripXMLDeclaration removes the XML declaration, because encoding/xml does not support
+// the version 1.1 which Jenkins writes
+func stripXMLDeclaration(data []byte) []byte {
+	trimmed := bytes.TrimLeft(data, " \t\r\n\ufeff")
+	if bytes.HasPrefix(trimmed, []byte("<?xml")) {
+		if end := bytes.Index(trimmed, []byte("?>")); end > 0 {
+			return trimmed[end+2:]
+		}
+	}
+	return data
+}
+
+// rootElement returns the name of the root element
+func rootElement(data []byte) (root string, err error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		var token xml.Token
+		if token, err = decoder.Token(); err != nil {
+			err = fmt.Errorf("cannot find the root element of the config.xml, error is %v", err)
+			return
+		}
+		if element, ok := token.(xml.StartElement); ok {
+			root = element.Name.Local
+			return
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package job
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseConfig(t *testing.T) {
+	tests := []struct {
+		file   string
+		class  string
+		verify func(t *testing.T, config ItemConfig)
+	}{{
+		file:  "WorkflowJob.xml",
+		class: WorkflowJobClass,
+		verify: func(t *testing.T, config ItemConfig) {
+			job := config.(*WorkflowJob)
+			assert.Equal(t, "build the app", job.Description)
+			assert.False(t, job.Disabled)
+			if assert.NotNil(t, job.Properties.BuildDiscarder) {
+				assert.Equal(t, LogRotatorConfig{ClassName: LogRotatorClass, DaysToKeep: -1, NumToKeep: 10,
+					ArtifactDaysToKeep: -1, ArtifactNumToKeep: -1}, job.Properties.BuildDiscarder.Strategy)
+			}
+			if assert.NotNil(t, job.Properties.Parameters) {
+				branch := job.Properties.Parameters.Get("branch")
+				if assert.NotNil(t, branch) {
+					assert.Equal(t, "master", branch.DefaultValue)
+					assert.NotNil(t, branch.Others.Get("trim"))
+				}
+				assert.NotNil(t, job.Properties.Parameters.Get("env"))
+			}
+			if assert.NotNil(t, job.Properties.PipelineTriggers) {
+				assert.Equal(t, "H 2 * * *", job.Properties.PipelineTriggers.Triggers.Get("hudson.triggers.TimerTrigger").Spec)
+			}
+			assert.NotNil(t, job.Properties.Others.Get("org.jenkinsci.plugins.workflow.job.properties.DisableConcurrentBuildsJobProperty"))
+			if assert.NotNil(t, job.Definition) {
+				assert.Equal(t, CpsFlowDefinitionClass, job.Definition.ClassName)
+				assert.Equal(t, "node {\n  sh 'make && make test'\n}", job.Definition.Script)
+				assert.True(t, job.Definition.Sandbox)
+			}
+		},
+	}, {
+		file:  "FreeStyleProject.xml",
+		class: FreeStyleProjectClass,
+		verify: func(t *testing.T, config ItemConfig) {
+			project := config.(*FreeStyleProject)
+			assert.True(t, project.Disabled)
+			if assert.NotNil(t, project.SCM) {
+				assert.Equal(t, "hudson.plugins.git.GitSCM", project.SCM.ClassName)
+			}
+			assert.Equal(t, "H/15 * * * *", project.Triggers.Get("hudson.triggers.SCMTrigger").Spec)
+			if assert.Len(t, project.Builders.Items, 1) {
+				assert.Equal(t, "make", project.Builders.Items[0].Command)
+			}
+			assert.Len(t, project.Publishers.Items, 1)
+		},
+	}, {
+		file:  "Folder.xml",
+		class: FolderClass,
+		verify: func(t *testing.T, config ItemConfig) {
+			folder := config.(*Folder)
+			assert.Equal(t, "Team", folder.DisplayName)
+			assert.Len(t, folder.Properties.Items, 1)
+			assert.NotNil(t, folder.Others.Get("folderViews"))
+		},
+	}, {
+		file:  "WorkflowMultiBranchProject.xml",
+		class: WorkflowMultiBranchProjectClass,
+		verify: func(t *testing.T, config ItemConfig) {
+			project := config.(*WorkflowMultiBranchProject)
+			if assert.NotNil(t, project.OrphanedItemStrategy) {
+				assert.True(t, project.OrphanedItemStrategy.PruneDeadBranches)
+				assert.Equal(t, 5, project.OrphanedItemStrategy.NumToKeep)
+			}
+			if assert.NotNil(t, project.Sources) && assert.Len(t, project.Sources.Data.Items, 1) {
+				source := project.Sources.Data.Items[0].Source
+				assert.Equal(t, "jenkins.plugins.git.GitSCMSource", source.ClassName)
+				assert.Equal(t, "https://github.com/jenkins-zh/jenkins-client", source.Others.Get("remote").Inner)
+			}
+			if assert.NotNil(t, project.Factory) {
+				assert.Equal(t, "Jenkinsfile", project.Factory.ScriptPath)
+			}
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			data, err := ioutil.ReadFile(filepath.Join("testdata", tt.file))
+			assert.Nil(t, err)
+
+			config, err := ParseConfig(data)
+			if !assert.Nil(t, err) {
+				return
+			}
+			assert.Equal(t, tt.class, config.Class())
+			tt.verify(t, config)
+
+			// every element, attribute, and text is written back
+			output, err := MarshalConfig(config)
+			assert.Nil(t, err)
+			assert.True(t, bytes.HasPrefix(output, []byte(ConfigHeader)))
+			assert.Equal(t, flattenXML(t, data), flattenXML(t, output))
+
+			again, err := ParseConfig(output)
+			assert.Nil(t, err)
+			assert.Equal(t, config, again)
+		})
+	}
+
+	_, err := ParseConfig([]byte(`<hudson.model.ExternalJob/>`))
+	assert.NotNil(t, err)
+	_, err = ParseConfig([]byte(`not xml`))
+	assert.NotNil(t, err)
+}
+
+func TestUpdateTypedConfig(t *testing.T) {
+	data, err := ioutil.ReadFile(filepath.Join("testdata", "WorkflowJob.xml"))
+	assert.Nil(t, err)
+	config, err := ParseConfig(data)
+	assert.Nil(t, err)
+
+	job := config.(*WorkflowJob)
+	job.Properties.BuildDiscarder = NewBuildDiscarder(7, -1)
+	job.Properties.Parameters.Set(NewStringParameter("branch", "main", ""))
+	job.Properties.Parameters.Set(NewBooleanParameter("debug", true, ""))
+	job.Properties.PipelineTriggers.Triggers.Set(NewTimerTrigger("@daily"))
+	job.Properties.Others.Remove("org.jenkinsci.plugins.workflow.job.properties.DisableConcurrentBuildsJobProperty")
+
+	output, err := MarshalConfig(job)
+	assert.Nil(t, err)
+	flatten := strings.Join(flattenXML(t, output), "\n")
+	assert.Contains(t, flatten, "flow-definition/properties/jenkins.model.BuildDiscarderProperty/strategy/daysToKeep=7")
+	assert.Contains(t, flatten, "hudson.model.StringParameterDefinition/defaultValue=main")
+	assert.Contains(t, flatten, "hudson.model.BooleanParameterDefinition/defaultValue=true")
+	assert.Contains(t, flatten, "hudson.triggers.TimerTrigger/spec=@daily")
+	assert.NotContains(t, flatten, "DisableConcurrentBuildsJobProperty")
+	// the other parameter is untouched
+	assert.Contains(t, flatten, "hudson.model.ChoiceParameterDefinition/choices/a/string=prod")
+}
+
+// flattenXML returns the sorted paths of the elements, attributes, and texts
+func flattenXML(t *testing.T, data []byte) (items []string) {
+	decoder := xml.NewDecoder(bytes.NewReader(stripXMLDeclaration(data)))
+	var path []string
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			break
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			path = append(path, token.Name.Local)
+			current := strings.Join(path, "/")
+			items = append(items, current)
+			for _, attr := range token.Attr {
+				items = append(items, current+"@"+attr.Name.Local+"="+attr.Value)
+			}
+		case xml.EndElement:
+			path = path[:len(path)-1]
+		case xml.CharData:
+			if text := strings.TrimSpace(string(token)); text != "" {
+				items = append(items, strings.Join(path, "/")+"="+text)
+			}
+		}
+	}
+	assert.Empty(t, path)
+	sort.Strings(items)
+	return
+}
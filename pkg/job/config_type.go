@@ -0,0 +1,392 @@
+package job
+
+import (
+	"encoding/xml"
+)
+
+const (
+	// WorkflowJobClass is the class of the Pipeline job
+	WorkflowJobClass = "org.jenkinsci.plugins.workflow.job.WorkflowJob"
+	// FreeStyleProjectClass is the class of the freestyle job
+	FreeStyleProjectClass = "hudson.model.FreeStyleProject"
+	// FolderClass is the class of the folder
+	FolderClass = "com.cloudbees.hudson.plugins.folder.Folder"
+	// WorkflowMultiBranchProjectClass is the class of the multi-branch Pipeline
+	WorkflowMultiBranchProjectClass = "org.jenkinsci.plugins.workflow.multibranch.WorkflowMultiBranchProject"
+
+	// CpsFlowDefinitionClass is the class of the Pipeline script definition
+	CpsFlowDefinitionClass = "org.jenkinsci.plugins.workflow.cps.CpsFlowDefinition"
+	// CpsScmFlowDefinitionClass is the class of the Pipeline script from SCM definition
+	CpsScmFlowDefinitionClass = "org.jenkinsci.plugins.workflow.cps.CpsScmFlowDefinition"
+	// LogRotatorClass is the class of the default build discarder
+	LogRotatorClass = "hudson.tasks.LogRotator"
+)
+
+// ItemConfig is a typed config.xml of an item, it is one of *WorkflowJob, *FreeStyleProject,
+// *Folder, and *WorkflowMultiBranchProject
+type ItemConfig interface {
+	// Class returns the class of the item
+	Class() string
+}
+
+// ConfigElement is an XML element which is not modeled, it's kept as is
+type ConfigElement struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr `xml:",any,attr"`
+	Inner   string     `xml:",innerxml"`
+}
+
+// Attr returns the value of an attribute, it's empty if there is no such attribute
+func (e *ConfigElement) Attr(name string) string {
+	for _, attr := range e.Attrs {
+		if attr.Name.Local == name {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+// ConfigElements are the XML elements which are not modeled, such as the unknown properties
+type ConfigElements []ConfigElement
+
+// Get returns the first element with the name, it's nil if there is no such element
+func (e ConfigElements) Get(name string) *ConfigElement {
+	for i := range e {
+		if e[i].XMLName.Local == name {
+			return &e[i]
+		}
+	}
+	return nil
+}
+
+// Set replaces the elements which have the same name, or appends it
+func (e *ConfigElements) Set(element ConfigElement) {
+	e.Remove(element.XMLName.Local)
+	*e = append(*e, element)
+}
+
+// Remove removes all the elements with the name
+func (e *ConfigElements) Remove(name string) {
+	kept := (*e)[:0]
+	for _, element := range *e {
+		if element.XMLName.Local != name {
+			kept = append(kept, element)
+		}
+	}
+	*e = kept
+}
+
+// DescribableConfig is an element with a class attribute whose fields are not modeled, such as a SCM
+type DescribableConfig struct {
+	ClassName string         `xml:"class,attr,omitempty"`
+	Attrs     []xml.Attr     `xml:",any,attr"`
+	Others    ConfigElements `xml:",any"`
+}
+
+// PropertiesConfig is the properties of a job
+type PropertiesConfig struct {
+	BuildDiscarder   *BuildDiscarderConfig   `xml:"jenkins.model.BuildDiscarderProperty,omitempty"`
+	Parameters       *ParametersConfig       `xml:"hudson.model.ParametersDefinitionProperty,omitempty"`
+	PipelineTriggers *PipelineTriggersConfig `xml:"org.jenkinsci.plugins.workflow.job.properties.PipelineTriggersJobProperty,omitempty"`
+	Others           ConfigElements          `xml:",any"`
+}
+
+// BuildDiscarderConfig is the property which discards the old builds
+type BuildDiscarderConfig struct {
+	Attrs    []xml.Attr       `xml:",any,attr"`
+	Strategy LogRotatorConfig `xml:"strategy"`
+	Others   ConfigElements   `xml:",any"`
+}
+
+// LogRotatorConfig keeps the builds by days or by number, -1 means no limit
+type LogRotatorConfig struct {
+	ClassName          string         `xml:"class,attr,omitempty"`
+	Attrs              []xml.Attr     `xml:",any,attr"`
+	DaysToKeep         int            `xml:"daysToKeep"`
+	NumToKeep          int            `xml:"numToKeep"`
+	ArtifactDaysToKeep int            `xml:"artifactDaysToKeep"`
+	ArtifactNumToKeep  int            `xml:"artifactNumToKeep"`
+	Others             ConfigElements `xml:",any"`
+}
+
+// NewBuildDiscarder creates a build discarder which keeps the builds by days and by number, -1 means no limit
+func NewBuildDiscarder(daysToKeep, numToKeep int) *BuildDiscarderConfig {
+	return &BuildDiscarderConfig{Strategy: LogRotatorConfig{
+		ClassName:          LogRotatorClass,
+		DaysToKeep:         daysToKeep,
+		NumToKeep:          numToKeep,
+		ArtifactDaysToKeep: -1,
+		ArtifactNumToKeep:  -1,
+	}}
+}
+
+// ParametersConfig is the property which defines the parameters of a job
+type ParametersConfig struct {
+	Attrs       []xml.Attr                 `xml:",any,attr"`
+	Definitions ParameterDefinitionsConfig `xml:"parameterDefinitions"`
+	Others      ConfigElements             `xml:",any"`
+}
+
+// ParameterDefinitionsConfig is the list of the parameter definitions
+type ParameterDefinitionsConfig struct {
+	Items []ParameterConfig `xml:",any"`
+}
+
+// ParameterConfig is a parameter definition, the element name is its class,
+// such as hudson.model.StringParameterDefinition
+type ParameterConfig struct {
+	XMLName      xml.Name
+	Attrs        []xml.Attr     `xml:",any,attr"`
+	Name         string         `xml:"name"`
+	Description  string         `xml:"description,omitempty"`
+	DefaultValue string         `xml:"defaultValue,omitempty"`
+	Others       ConfigElements `xml:",any"`
+}
+
+// NewStringParameter creates a string parameter definition
+func NewStringParameter(name, defaultValue, description string) ParameterConfig {
+	return ParameterConfig{
+		XMLName:      xml.Name{Local: "hudson.model.StringParameterDefinition"},
+		Name:         name,
+		DefaultValue: defaultValue,
+		Description:  description,
+	}
+}
+
+// NewBooleanParameter creates a boolean parameter definition
+func NewBooleanParameter(name string, defaultValue bool, description string) ParameterConfig {
+	value := "false"
+	if defaultValue {
+		value = "true"
+	}
+	return ParameterConfig{
+		XMLName:      xml.Name{Local: "hudson.model.BooleanParameterDefinition"},
+		Name:         name,
+		DefaultValue: value,
+		Description:  description,
+	}
+}
+
+// Get returns the parameter definition by name, it's nil if there is no such parameter
+func (p *ParametersConfig) Get(name string) *ParameterConfig {
+	for i := range p.Definitions.Items {
+		if p.Definitions.Items[i].Name == name {
+			return &p.Definitions.Items[i]
+		}
+	}
+	return nil
+}
+
+// Set replaces the parameter definition which has the same name, or appends it
+func (p *ParametersConfig) Set(parameter ParameterConfig) {
+	if existing := p.Get(parameter.Name); existing != nil {
+		*existing = parameter
+	} else {
+		p.Definitions.Items = append(p.Definitions.Items, parameter)
+	}
+}
+
+// PipelineTriggersConfig is the property which holds the triggers of a Pipeline job
+type PipelineTriggersConfig struct {
+	Attrs    []xml.Attr     `xml:",any,attr"`
+	Triggers TriggersConfig `xml:"triggers"`
+	Others   ConfigElements `xml:",any"`
+}
+
+// TriggersConfig is the list of the triggers
+type TriggersConfig struct {
+	Items []TriggerConfig `xml:",any"`
+}
+
+// TriggerConfig is a trigger, the element name is its class, such as hudson.triggers.TimerTrigger
+type TriggerConfig struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr     `xml:",any,attr"`
+	Spec    string         `xml:"spec,omitempty"`
+	Others  ConfigElements `xml:",any"`
+}
+
+// NewTimerTrigger creates a trigger which builds periodically, such as "H 2 * * *"
+func NewTimerTrigger(spec string) TriggerConfig {
+	return TriggerConfig{XMLName: xml.Name{Local: "hudson.triggers.TimerTrigger"}, Spec: spec}
+}
+
+// NewSCMTrigger creates a trigger which polls the SCM periodically
+func NewSCMTrigger(spec string) TriggerConfig {
+	return TriggerConfig{XMLName: xml.Name{Local: "hudson.triggers.SCMTrigger"}, Spec: spec}
+}
+
+// Get returns the trigger by its class, it's nil if there is no such trigger
+func (t *TriggersConfig) Get(class string) *TriggerConfig {
+	for i := range t.Items {
+		if t.Items[i].XMLName.Local == class {
+			return &t.Items[i]
+		}
+	}
+	return nil
+}
+
+// Set replaces the trigger which has the same class, or appends it
+func (t *TriggersConfig) Set(trigger TriggerConfig) {
+	if existing := t.Get(trigger.XMLName.Local); existing != nil {
+		*existing = trigger
+	} else {
+		t.Items = append(t.Items, trigger)
+	}
+}
+
+// FlowDefinitionConfig is the definition of a Pipeline job, it's a script or a script from SCM
+type FlowDefinitionConfig struct {
+	ClassName   string             `xml:"class,attr,omitempty"`
+	Attrs       []xml.Attr         `xml:",any,attr"`
+	Script      string             `xml:"script,omitempty"`
+	Sandbox     bool               `xml:"sandbox,omitempty"`
+	SCM         *DescribableConfig `xml:"scm,omitempty"`
+	ScriptPath  string             `xml:"scriptPath,omitempty"`
+	Lightweight bool               `xml:"lightweight,omitempty"`
+	Others      ConfigElements     `xml:",any"`
+}
+
+// NewScriptDefinition creates a Pipeline definition with the script
+func NewScriptDefinition(script string, sandbox bool) *FlowDefinitionConfig {
+	return &FlowDefinitionConfig{ClassName: CpsFlowDefinitionClass, Script: script, Sandbox: sandbox}
+}
+
+// WorkflowJob is the config.xml of a Pipeline job
+type WorkflowJob struct {
+	XMLName     xml.Name              `xml:"flow-definition"`
+	Attrs       []xml.Attr            `xml:",any,attr"`
+	Description string                `xml:"description"`
+	DisplayName string                `xml:"displayName,omitempty"`
+	Properties  PropertiesConfig      `xml:"properties"`
+	Definition  *FlowDefinitionConfig `xml:"definition,omitempty"`
+	Disabled    bool                  `xml:"disabled"`
+	Others      ConfigElements        `xml:",any"`
+}
+
+// Class returns the class of the Pipeline job
+func (c *WorkflowJob) Class() string {
+	return WorkflowJobClass
+}
+
+// BuildStepsConfig is the list of the build steps of a freestyle job
+type BuildStepsConfig struct {
+	Items []BuildStepConfig `xml:",any"`
+}
+
+// BuildStepConfig is a builder or a publisher, the element name is its class, such as hudson.tasks.Shell
+type BuildStepConfig struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr     `xml:",any,attr"`
+	Command string         `xml:"command,omitempty"`
+	Others  ConfigElements `xml:",any"`
+}
+
+// NewShellStep creates a build step which runs a shell script
+func NewShellStep(command string) BuildStepConfig {
+	return BuildStepConfig{XMLName: xml.Name{Local: "hudson.tasks.Shell"}, Command: command}
+}
+
+// NewBatchFileStep creates a build step which runs a Windows batch script
+func NewBatchFileStep(command string) BuildStepConfig {
+	return BuildStepConfig{XMLName: xml.Name{Local: "hudson.tasks.BatchFile"}, Command: command}
+}
+
+// FreeStyleProject is the config.xml of a freestyle job
+type FreeStyleProject struct {
+	XMLName     xml.Name           `xml:"project"`
+	Attrs       []xml.Attr         `xml:",any,attr"`
+	Description string             `xml:"description"`
+	DisplayName string             `xml:"displayName,omitempty"`
+	Properties  PropertiesConfig   `xml:"properties"`
+	SCM         *DescribableConfig `xml:"scm,omitempty"`
+	Disabled    bool               `xml:"disabled"`
+	Triggers    TriggersConfig     `xml:"triggers"`
+	Builders    BuildStepsConfig   `xml:"builders"`
+	Publishers  BuildStepsConfig   `xml:"publishers"`
+	Others      ConfigElements     `xml:",any"`
+}
+
+// Class returns the class of the freestyle job
+func (c *FreeStyleProject) Class() string {
+	return FreeStyleProjectClass
+}
+
+// FolderPropertiesConfig is the properties of a folder, such as the folder credentials
+// and the Pipeline libraries, they are not modeled
+type FolderPropertiesConfig struct {
+	Items ConfigElements `xml:",any"`
+}
+
+// Folder is the config.xml of a folder
+type Folder struct {
+	XMLName     xml.Name               `xml:"com.cloudbees.hudson.plugins.folder.Folder"`
+	Attrs       []xml.Attr             `xml:",any,attr"`
+	Description string                 `xml:"description"`
+	DisplayName string                 `xml:"displayName,omitempty"`
+	Properties  FolderPropertiesConfig `xml:"properties"`
+	Others      ConfigElements         `xml:",any"`
+}
+
+// Class returns the class of the folder
+func (c *Folder) Class() string {
+	return FolderClass
+}
+
+// OrphanedItemStrategyConfig decides when the branches which no longer exist are removed, -1 means no limit
+type OrphanedItemStrategyConfig struct {
+	ClassName         string         `xml:"class,attr,omitempty"`
+	Attrs             []xml.Attr     `xml:",any,attr"`
+	PruneDeadBranches bool           `xml:"pruneDeadBranches"`
+	DaysToKeep        int            `xml:"daysToKeep"`
+	NumToKeep         int            `xml:"numToKeep"`
+	Others            ConfigElements `xml:",any"`
+}
+
+// BranchSourcesConfig is the list of the branch sources of a multi-branch Pipeline
+type BranchSourcesConfig struct {
+	ClassName string                 `xml:"class,attr,omitempty"`
+	Attrs     []xml.Attr             `xml:",any,attr"`
+	Data      BranchSourceListConfig `xml:"data"`
+	Others    ConfigElements         `xml:",any"`
+}
+
+// BranchSourceListConfig holds the branch sources
+type BranchSourceListConfig struct {
+	Items  []BranchSourceConfig `xml:"jenkins.branch.BranchSource"`
+	Others ConfigElements       `xml:",any"`
+}
+
+// BranchSourceConfig is a branch source, such as jenkins.plugins.git.GitSCMSource
+type BranchSourceConfig struct {
+	Attrs  []xml.Attr        `xml:",any,attr"`
+	Source DescribableConfig `xml:"source"`
+	Others ConfigElements    `xml:",any"`
+}
+
+// BranchProjectFactoryConfig creates the Pipeline jobs of the branches
+type BranchProjectFactoryConfig struct {
+	ClassName  string         `xml:"class,attr,omitempty"`
+	Attrs      []xml.Attr     `xml:",any,attr"`
+	ScriptPath string         `xml:"scriptPath,omitempty"`
+	Others     ConfigElements `xml:",any"`
+}
+
+// WorkflowMultiBranchProject is the config.xml of a multi-branch Pipeline
+type WorkflowMultiBranchProject struct {
+	XMLName              xml.Name                    `xml:"org.jenkinsci.plugins.workflow.multibranch.WorkflowMultiBranchProject"`
+	Attrs                []xml.Attr                  `xml:",any,attr"`
+	Description          string                      `xml:"description"`
+	DisplayName          string                      `xml:"displayName,omitempty"`
+	Properties           FolderPropertiesConfig      `xml:"properties"`
+	OrphanedItemStrategy *OrphanedItemStrategyConfig `xml:"orphanedItemStrategy,omitempty"`
+	Triggers             TriggersConfig              `xml:"triggers"`
+	Sources              *BranchSourcesConfig        `xml:"sources,omitempty"`
+	Factory              *BranchProjectFactoryConfig `xml:"factory,omitempty"`
+	Others               ConfigElements              `xml:",any"`
+}
+
+// Class returns the class of the multi-branch Pipeline
+func (c *WorkflowMultiBranchProject) Class() string {
+	return WorkflowMultiBranchProjectClass
+}
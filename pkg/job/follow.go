@@ -0,0 +1,281 @@
+package job
+
+import (
+	"bufio"
+	"context"
+	"html"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultFollowMinInterval is the waiting duration after a poll which returns new data
+	DefaultFollowMinInterval = 500 * time.Millisecond
+	// DefaultFollowMaxInterval is the upper limit of the waiting duration between the polls
+	DefaultFollowMaxInterval = 5 * time.Second
+)
+
+// FollowOptions are the options of following the log of a build
+type FollowOptions struct {
+	// Start is the byte offset of the raw log to resume from, it's usually the Offset of a previous LogStream
+	Start int64
+	// HTML polls progressiveHtml instead of progressiveText, the console notes and the tags are stripped
+	HTML bool
+	// MinInterval is the waiting duration after a poll which returns new data, it's DefaultFollowMinInterval if it's zero
+	MinInterval time.Duration
+	// MaxInterval is the upper limit of the waiting duration, it's DefaultFollowMaxInterval if it's zero
+	MaxInterval time.Duration
+	// Multiplier is the factor for increasing the waiting duration after an empty poll, it's 2 if it's less than 1
+	Multiplier float64
+}
+
+// LogStream is the log of a build which keeps growing until the build finishes.
+// Read returns io.EOF once the whole log is read and the build is finished.
+type LogStream struct {
+	client  *Client
+	jobName string
+	history int
+	options FollowOptions
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// the fields are only used by Read, it's not supposed to be called at the same time
+	pending   []byte
+	finished  bool
+	interval  time.Duration
+	annotator string
+	// fetched is the byte offset of the raw log which is fetched so far, the next poll starts from it
+	fetched int64
+
+	// mutex guards offset and err, then they could be checked during a Read
+	mutex  sync.Mutex
+	offset int64
+	err    error
+}
+
+// FollowLog follows the log of a build until it finishes, the history is -1 for the last build.
+// The caller is responsible for closing the stream.
+func (q *Client) FollowLog(jobName string, history int, options FollowOptions) *LogStream {
+	return q.FollowLogWithContext(context.Background(), jobName, history, options)
+}
+
+// FollowLogWithContext follows the log of a build with a context, the stream stops once the context is done
+func (q *Client) FollowLogWithContext(ctx context.Context, jobName string, history int, options FollowOptions) *LogStream {
+	if options.MinInterval <= 0 {
+		options.MinInterval = DefaultFollowMinInterval
+	}
+	if options.MaxInterval <= 0 {
+		options.MaxInterval = DefaultFollowMaxInterval
+	}
+	if options.MaxInterval < options.MinInterval {
+		options.MaxInterval = options.MinInterval
+	}
+	if options.Multiplier < 1 {
+		options.Multiplier = 2
+	}
+
+	stream := &LogStream{
+		client:  q,
+		jobName: jobName,
+		history: history,
+		options: options,
+		fetched: options.Start,
+		offset:  options.Start,
+	}
+	stream.ctx, stream.cancel = context.WithCancel(ctx)
+	return stream
+}
+
+// Offset returns the byte offset of the raw log which is read so far, it could be the Start to resume the stream.
+// In the HTML mode, it moves once the whole text of a poll is read, because the text does not map to the raw log.
+// The lines which are buffered by Lines but not received yet are counted as read.
+func (s *LogStream) Offset() int64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.offset
+}
+
+// Read reads the log, it waits for the new data if the build is still running
+func (s *LogStream) Read(p []byte) (n int, err error) {
+	for len(s.pending) == 0 {
+		if err = s.Err(); err != nil {
+			return
+		}
+		if s.finished {
+			return 0, io.EOF
+		}
+		if s.interval > 0 {
+			if err = s.wait(); err != nil {
+				s.fail(err)
+				return
+			}
+		}
+		if err = s.poll(); err != nil {
+			s.fail(err)
+			return
+		}
+	}
+
+	n = copy(p, s.pending)
+	s.pending = s.pending[n:]
+	s.updateOffset()
+	return
+}
+
+// updateOffset moves the offset by the bytes which are read
+func (s *LogStream) updateOffset() {
+	offset := s.fetched
+	if len(s.pending) > 0 {
+		if s.options.HTML {
+			return
+		}
+		offset -= int64(len(s.pending))
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if offset > s.offset {
+		s.offset = offset
+	}
+}
+
+// Close stops following the log
+func (s *LogStream) Close() error {
+	s.cancel()
+	return nil
+}
+
+// Lines returns the lines of the log without the line breaks, the channel is closed once the stream ends.
+// Err returns the reason after the channel is closed. It should not be used together with Read.
+func (s *LogStream) Lines() <-chan string {
+	lines := make(chan string)
+	go func() {
+		defer close(lines)
+		reader := bufio.NewReader(s)
+		for {
+			line, err := reader.ReadString('\n')
+			if line != "" {
+				select {
+				case lines <- strings.TrimRight(line, "\r\n"):
+				case <-s.ctx.Done():
+					return
+				}
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+	return lines
+}
+
+// Err returns the error which stops the stream, it's nil if the build is finished and the whole log is read
+func (s *LogStream) Err() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.err
+}
+
+// fail keeps the first error which stops the stream
+func (s *LogStream) fail(err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.err == nil {
+		s.err = err
+	}
+}
+
+// wait sleeps for the interval, it returns the error of the context if the stream is stopped during the sleep
+func (s *LogStream) wait() error {
+	timer := time.NewTimer(s.interval)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-s.ctx.Done():
+		return s.ctx.Err()
+	}
+}
+
+// poll fetches the log since the offset, then adjusts the waiting duration before the next poll
+func (s *LogStream) poll() (err error) {
+	if err = s.ctx.Err(); err != nil {
+		return
+	}
+
+	var headers map[string]string
+	if s.annotator != "" {
+		headers = map[string]string{"X-ConsoleAnnotator": s.annotator}
+	}
+	var (
+		response *http.Response
+		body     io.ReadCloser
+		data     []byte
+	)
+	if response, body, err = s.client.RequestWithReaderWithContext(s.ctx, http.MethodGet,
+		getProgressiveLogAPI(s.jobName, s.history, s.fetched, s.options.HTML), headers, nil); err != nil {
+		return
+	}
+	defer func() {
+		_ = body.Close()
+	}()
+	if data, err = ioutil.ReadAll(body); err != nil {
+		return
+	}
+
+	hasMore, nextStart := parseLogHeader(response.Header)
+	if s.options.HTML {
+		s.annotator = response.Header.Get("X-ConsoleAnnotator")
+		data = []byte(StripConsoleHTML(string(data)))
+	}
+	if nextStart > s.fetched {
+		s.fetched = nextStart
+	}
+	s.pending = data
+	s.finished = !hasMore
+	s.updateOffset()
+
+	switch {
+	case len(data) > 0:
+		s.interval = s.options.MinInterval
+	case s.interval == 0:
+		s.interval = s.options.MinInterval
+	default:
+		s.interval = time.Duration(float64(s.interval) * s.options.Multiplier)
+		if s.interval > s.options.MaxInterval {
+			s.interval = s.options.MaxInterval
+		}
+	}
+	return
+}
+
+func getProgressiveLogAPI(jobName string, history int, start int64, html bool) string {
+	api := getLogAPI(jobName, history, start)
+	if html {
+		api = strings.Replace(api, "/logText/progressiveText?", "/logText/progressiveHtml?", 1)
+	}
+	return api
+}
+
+var (
+	// consoleNotePattern matches the serialized console notes which are embedded in the raw log
+	consoleNotePattern = regexp.MustCompile("\x1b\\[8mha:[^\x1b]*\x1b\\[0m")
+	// htmlTagPattern matches the tags which are added by the console annotators
+	htmlTagPattern = regexp.MustCompile(`<[^>]*>`)
+)
+
+// StripConsoleNotes removes the serialized console notes from a piece of the raw log
+func StripConsoleNotes(text string) string {
+	return consoleNotePattern.ReplaceAllString(text, "")
+}
+
+// StripConsoleHTML turns a piece of progressiveHtml into the plain text
+func StripConsoleHTML(text string) string {
+	return html.UnescapeString(htmlTagPattern.ReplaceAllString(StripConsoleNotes(text), ""))
+}
@@ -0,0 +1,63 @@
+package job_test
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/jenkins-zh/jenkins-client/pkg/fakejenkins"
+	"github.com/jenkins-zh/jenkins-client/pkg/job"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFollowLog(t *testing.T) {
+	clock := fakejenkins.NewClock(time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC))
+	server := fakejenkins.NewServer(fakejenkins.WithClock(clock.Now))
+	defer server.Close()
+	assert.Nil(t, server.CreateJob("app", job.WorkflowJobClass))
+	assert.Nil(t, server.SetScenario("app", fakejenkins.Scenario{
+		Duration: 4 * time.Second,
+		Log:      []string{"checkout", "compile <main>", "test", "archive"},
+	}))
+
+	client := &job.Client{JenkinsCore: server.JenkinsCore()}
+	assert.Nil(t, client.Build("app"))
+	options := job.FollowOptions{MinInterval: time.Millisecond, MaxInterval: 5 * time.Millisecond}
+
+	// the clock moves forward once a line is received, so the build goes on during the follow
+	stream := client.FollowLog("app", 1, options)
+	var lines []string
+	for line := range stream.Lines() {
+		lines = append(lines, line)
+		clock.Advance(time.Second)
+	}
+	assert.Nil(t, stream.Err())
+	assert.Nil(t, stream.Close())
+	assert.Equal(t, []string{"Started by remote host", "checkout", "compile <main>", "test", "archive",
+		"Finished: SUCCESS"}, lines)
+	log, err := client.Log("app", 1, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(len(log.Text)), stream.Offset())
+
+	// resume from an offset with the annotated log
+	options.Start, options.HTML = int64(len("Started by remote host\ncheckout\n")), true
+	stream = client.FollowLog("app", -1, options)
+	data, err := ioutil.ReadAll(stream)
+	assert.Nil(t, err)
+	assert.Equal(t, "compile <main>\ntest\narchive\nFinished: SUCCESS\n", string(data))
+
+	// the stream stops once the context is cancelled
+	assert.Nil(t, server.SetScenario("app", fakejenkins.Scenario{Duration: time.Hour, Log: []string{"wait"}}))
+	assert.Nil(t, client.Build("app"))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream = client.FollowLogWithContext(ctx, "app", 2, job.FollowOptions{MinInterval: time.Millisecond})
+	lines = nil
+	for line := range stream.Lines() {
+		lines = append(lines, line)
+		cancel()
+	}
+	assert.Equal(t, []string{"Started by remote host"}, lines)
+	assert.Equal(t, context.Canceled, stream.Err())
+}
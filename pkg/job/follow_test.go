@@ -0,0 +1,97 @@
+package job
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/jenkins-zh/jenkins-client/pkg/core"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStripConsoleHTML(t *testing.T) {
+	assert.Equal(t, "[Pipeline] echo\n", StripConsoleNotes("\x1b[8mha:////4Hz+AAAA\x1b[0m[Pipeline] echo\n"))
+	assert.Equal(t, "see https://example.com & <done>\n", StripConsoleHTML(
+		`see <a href="https://example.com">https://example.com</a> &amp; &lt;done&gt;`+"\n"))
+}
+
+func TestFollowLogBackoff(t *testing.T) {
+	chunks := []string{"", "", "first\n", "", "last\n"}
+	var (
+		polls      []time.Time
+		annotators []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/job/app/3/logText/progressiveHtml", r.URL.Path)
+		assert.Equal(t, strconv.Itoa(len(polls)*10), r.URL.Query().Get("start"))
+		annotators = append(annotators, r.Header.Get("X-ConsoleAnnotator"))
+
+		w.Header().Set("X-ConsoleAnnotator", "state-"+strconv.Itoa(len(polls)))
+		w.Header().Set("X-Text-Size", strconv.Itoa((len(polls)+1)*10))
+		if len(polls) < len(chunks)-1 {
+			w.Header().Set("X-More-Data", "true")
+		}
+		_, _ = w.Write([]byte(chunks[len(polls)]))
+		polls = append(polls, time.Now())
+	}))
+	defer server.Close()
+
+	client := &Client{JenkinsCore: core.JenkinsCore{URL: server.URL}}
+	stream := client.FollowLog("app", 3, FollowOptions{
+		HTML:        true,
+		MinInterval: 10 * time.Millisecond,
+		MaxInterval: 25 * time.Millisecond,
+	})
+	data, err := ioutil.ReadAll(stream)
+	assert.Nil(t, err)
+	assert.Equal(t, "first\nlast\n", string(data))
+	assert.Equal(t, int64(50), stream.Offset())
+	assert.Equal(t, []string{"", "state-0", "state-1", "state-2", "state-3"}, annotators)
+
+	// the interval grows after the empty polls, then it's reset once there is new data
+	if assert.Len(t, polls, 5) {
+		assert.True(t, polls[2].Sub(polls[1]) >= 20*time.Millisecond)
+		assert.True(t, polls[3].Sub(polls[2]) >= 10*time.Millisecond)
+	}
+}
+
+func TestFollowLogOffset(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start, _ := strconv.Atoi(r.URL.Query().Get("start"))
+		text := "0123456789"[start:]
+		if r.URL.Path == "/job/app/1/logText/progressiveHtml" {
+			text = "<b>" + text + "</b>"
+		}
+		w.Header().Set("X-Text-Size", "10")
+		_, _ = w.Write([]byte(text))
+	}))
+	defer server.Close()
+	client := &Client{JenkinsCore: core.JenkinsCore{URL: server.URL}}
+
+	// the unread bytes are not counted, then they're read again after resuming
+	stream := client.FollowLog("app", 1, FollowOptions{Start: 2})
+	buf := make([]byte, 3)
+	n, err := stream.Read(buf)
+	assert.Nil(t, err)
+	assert.Equal(t, "234", string(buf[:n]))
+	assert.Equal(t, int64(5), stream.Offset())
+
+	resumed := client.FollowLog("app", 1, FollowOptions{Start: stream.Offset()})
+	data, err := ioutil.ReadAll(resumed)
+	assert.Nil(t, err)
+	assert.Equal(t, "56789", string(data))
+	assert.Equal(t, int64(10), resumed.Offset())
+
+	// the stripped text does not map to the raw log, the offset moves once the text of a poll is read
+	stream = client.FollowLog("app", 1, FollowOptions{Start: 2, HTML: true})
+	_, err = stream.Read(buf)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), stream.Offset())
+	data, err = ioutil.ReadAll(stream)
+	assert.Nil(t, err)
+	assert.Equal(t, "56789", string(data))
+	assert.Equal(t, int64(10), stream.Offset())
+}
@@ -0,0 +1,69 @@
+package job
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/jenkins-zh/jenkins-client/pkg/core"
+)
+
+// SearchIterator walks through the jobs which match the name and kind page by page
+func (q *Client) SearchIterator(ctx context.Context, name, kind string, pageSize int) *core.Iterator[JenkinsItem] {
+	return core.NewIterator(ctx, pageSize, func(ctx context.Context, start, limit int) ([]JenkinsItem, error) {
+		return q.SearchWithContext(ctx, name, kind, start, limit)
+	})
+}
+
+// historyFields are the fields of Build, the build history is fetched with them page by page
+var historyFields = append(core.Fields("number", "url", "building", "description", "displayName", "duration",
+	"estimatedDuration", "fullDisplayName", "id", "keepLog", "queueId", "result", "timestamp"),
+	core.Field("previousBuild", core.Fields("number", "url")...),
+	core.Field("nextBuild", core.Fields("number", "url")...))
+
+// HistoryIterator walks through the build history of a job page by page, the latest build comes first.
+// Each page is fetched by one request.
+func (q *Client) HistoryIterator(ctx context.Context, name string, pageSize int) *core.Iterator[*Build] {
+	api := fmt.Sprintf("%s/api/json", ParseJobPath(name))
+	return core.NewIterator(ctx, pageSize, func(ctx context.Context, start, limit int) (builds []*Build, err error) {
+		// the builds field only contains the latest 100 builds, allBuilds contains all of them
+		job := struct {
+			AllBuilds []*Build `json:"allBuilds"`
+		}{}
+		tree := core.WithTree(core.Field("allBuilds", historyFields...).Range(start, start+limit))
+		if err = q.RequestWithDataWithContext(ctx, http.MethodGet, core.WithQuery(api, tree),
+			nil, nil, 200, &job); err == nil {
+			builds = job.AllBuilds
+		}
+		return
+	})
+}
+
+// SearchIterator walks through the pipelines which match the name page by page
+func (c *BlueOceanClient) SearchIterator(ctx context.Context, name string, pageSize int) *core.Iterator[JenkinsItem] {
+	return core.NewIterator(ctx, pageSize, func(ctx context.Context, start, limit int) ([]JenkinsItem, error) {
+		return c.SearchWithContext(ctx, name, start, limit)
+	})
+}
+
+// GetPipelineRunsIterator walks through the runs of a Pipeline which in the possible nest folders page by page
+func (c *BlueOceanClient) GetPipelineRunsIterator(ctx context.Context, pageSize int, pipeline string,
+	folders ...string) *core.Iterator[PipelineRun] {
+	api := fmt.Sprintf("%s/%s/runs/", c.getPipelineAPI(folders...), pipeline)
+	return core.NewIterator(ctx, pageSize, func(ctx context.Context, start, limit int) (runs []PipelineRun, err error) {
+		err = c.request(ctx, http.MethodGet, fmt.Sprintf("%s?start=%d&limit=%d", api, start, limit),
+			nil, nil, &runs)
+		return
+	})
+}
+
+// GetBranchesIterator walks through the branches of a Pipeline page by page, it starts from option.Start
+func (c *BlueOceanClient) GetBranchesIterator(ctx context.Context, option GetBranchesOption,
+	pageSize int) *core.Iterator[PipelineBranch] {
+	offset := option.Start
+	return core.NewIterator(ctx, pageSize, func(ctx context.Context, start, limit int) ([]PipelineBranch, error) {
+		option.Start = offset + start
+		option.Limit = limit
+		return c.GetBranchesWithContext(ctx, option)
+	})
+}
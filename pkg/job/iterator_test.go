@@ -0,0 +1,108 @@
+package job
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jenkins-zh/jenkins-client/pkg/core"
+	"github.com/jenkins-zh/jenkins-client/pkg/mock/mhttp"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("iterator test", func() {
+	var (
+		ctrl         *gomock.Controller
+		roundTripper *mhttp.MockRoundTripper
+		jobClient    Client
+		boClient     BlueOceanClient
+	)
+
+	given := func(api, body string) {
+		request, _ := http.NewRequest(http.MethodGet, "http://localhost"+api, nil)
+		response := &http.Response{
+			StatusCode: 200,
+			Request:    request,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(body)),
+		}
+		roundTripper.EXPECT().
+			RoundTrip(core.NewRequestMatcher(request).WithQuery()).Return(response, nil)
+	}
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		roundTripper = mhttp.NewMockRoundTripper(ctrl)
+		jobClient = Client{}
+		jobClient.RoundTripper = roundTripper
+		jobClient.URL = "http://localhost"
+		boClient = BlueOceanClient{Organization: "jenkins"}
+		boClient.RoundTripper = roundTripper
+		boClient.URL = "http://localhost"
+	})
+
+	AfterEach(func() {
+		ctrl.Finish()
+	})
+
+	It("search all the jobs", func() {
+		given("/items/list?name=fake&type=fake&start=0&limit=2&parent=", `[{"name":"a"},{"name":"b"}]`)
+		given("/items/list?name=fake&type=fake&start=2&limit=2&parent=", `[{"name":"c"}]`)
+
+		items, err := jobClient.SearchIterator(context.TODO(), "fake", "fake", 2).Collect()
+		Expect(err).To(BeNil())
+		Expect(len(items)).To(Equal(3))
+		Expect(items[2].Name).To(Equal("c"))
+	})
+
+	It("stop fetching once the loop breaks", func() {
+		given("/items/list?name=fake&type=fake&start=0&limit=2&parent=", `[{"name":"a"},{"name":"b"}]`)
+
+		it := jobClient.SearchIterator(context.TODO(), "fake", "fake", 2)
+		for item := range it.All() {
+			Expect(item.Name).To(Equal("a"))
+			break
+		}
+		Expect(it.Err()).To(BeNil())
+	})
+
+	It("walk through the build history", func() {
+		tree := func(start, end int) string {
+			return url.Values{"tree": {core.NewTree(core.Field("allBuilds", historyFields...).Range(start, end)).String()}}.Encode()
+		}
+		given("/job/fake/api/json?"+tree(0, 2), `{"allBuilds":[{"number":3,"building":true},{"number":2,"result":"FAILURE"}]}`)
+		given("/job/fake/api/json?"+tree(2, 4), `{"allBuilds":[{"number":1,"result":"SUCCESS","duration":1000}]}`)
+
+		builds, err := jobClient.HistoryIterator(context.TODO(), "fake", 2).Collect()
+		Expect(err).To(BeNil())
+		Expect(len(builds)).To(Equal(3))
+		Expect(builds[0].Building).To(BeTrue())
+		Expect(builds[1].Result).To(Equal("FAILURE"))
+		Expect(builds[2].Number).To(Equal(1))
+		Expect(builds[2].Duration).To(Equal(int64(1000)))
+	})
+
+	It("get all the runs of a pipeline", func() {
+		given("/blue/rest/organizations/jenkins/pipelines/fake/runs/?start=0&limit=1", `[{"id":"2"}]`)
+		given("/blue/rest/organizations/jenkins/pipelines/fake/runs/?start=1&limit=1", `[]`)
+
+		runs, err := boClient.GetPipelineRunsIterator(context.TODO(), 1, "fake").Collect()
+		Expect(err).To(BeNil())
+		Expect(len(runs)).To(Equal(1))
+		Expect(runs[0].ID).To(Equal("2"))
+	})
+
+	It("get all the branches from an offset", func() {
+		given("/blue/rest/organizations/jenkins/pipelines/fake/branches/?limit=2&start=1", `[{"name":"a"}]`)
+
+		branches, err := boClient.GetBranchesIterator(context.TODO(), GetBranchesOption{
+			PipelineName: "fake",
+			Start:        1,
+		}, 2).Collect()
+		Expect(err).To(BeNil())
+		Expect(len(branches)).To(Equal(1))
+	})
+})
@@ -2,10 +2,10 @@ package job
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
-	"io/ioutil"
 	"mime/multipart"
 	"net/http"
 	"net/url"
@@ -16,9 +16,6 @@ import (
 
 	"github.com/jenkins-zh/jenkins-client/pkg/core"
 	httpdownloader "github.com/linuxsuren/http-downloader/pkg"
-
-	"go.uber.org/zap"
-	"moul.io/http2curl"
 )
 
 const (
@@ -37,7 +34,12 @@ type Client struct {
 
 // Search find a set of jobs by name
 func (q *Client) Search(name, kind string, start, limit int) (items []JenkinsItem, err error) {
-	err = q.RequestWithData(http.MethodGet, fmt.Sprintf("/items/list?name=%s&type=%s&start=%d&limit=%d&parent=%s",
+	return q.SearchWithContext(context.Background(), name, kind, start, limit)
+}
+
+// SearchWithContext find a set of jobs by name with a context
+func (q *Client) SearchWithContext(ctx context.Context, name, kind string, start, limit int) (items []JenkinsItem, err error) {
+	err = q.RequestWithDataWithContext(ctx, http.MethodGet, fmt.Sprintf("/items/list?name=%s&type=%s&start=%d&limit=%d&parent=%s",
 		name, kind, start, limit, q.Parent),
 		nil, nil, 200, &items)
 	return
@@ -47,14 +49,26 @@ func (q *Client) Search(name, kind string, start, limit int) (items []JenkinsIte
 //
 // Deprecated: For clearer client of BlueOcean, please use BlueOceanClient#Search instead
 func (q *Client) SearchViaBlue(name string, start, limit int) (items []JenkinsItem, err error) {
+	return q.SearchViaBlueWithContext(context.Background(), name, start, limit)
+}
+
+// SearchViaBlueWithContext searches jobs via the BlueOcean API with a context
+//
+// Deprecated: For clearer client of BlueOcean, please use BlueOceanClient#SearchWithContext instead
+func (q *Client) SearchViaBlueWithContext(ctx context.Context, name string, start, limit int) (items []JenkinsItem, err error) {
 	boClient := BlueOceanClient{JenkinsCore: q.JenkinsCore, Organization: "jenkins"}
-	return boClient.Search(name, start, limit)
+	return boClient.SearchWithContext(ctx, name, start, limit)
 }
 
 // Build trigger a job
 func (q *Client) Build(jobName string) (err error) {
+	return q.BuildWithContext(context.Background(), jobName)
+}
+
+// BuildWithContext trigger a job with a context
+func (q *Client) BuildWithContext(ctx context.Context, jobName string) (err error) {
 	path := ParseJobPath(jobName)
-	_, err = q.RequestWithoutData(http.MethodPost, fmt.Sprintf("%s/build", path), nil, nil, 201)
+	_, err = q.RequestWithoutDataWithContext(ctx, http.MethodPost, fmt.Sprintf("%s/build", path), nil, nil, 201)
 	return
 }
 
@@ -73,6 +87,11 @@ type IdentityCause struct {
 
 // BuildAndReturn trigger a job then returns the build info
 func (q *Client) BuildAndReturn(jobName, cause string, timeout, delay int) (build IdentityBuild, err error) {
+	return q.BuildAndReturnWithContext(context.Background(), jobName, cause, timeout, delay)
+}
+
+// BuildAndReturnWithContext trigger a job then returns the build info with a context
+func (q *Client) BuildAndReturnWithContext(ctx context.Context, jobName, cause string, timeout, delay int) (build IdentityBuild, err error) {
 	path := ParseJobPath(jobName)
 
 	api := fmt.Sprintf("%s/restFul/build?1=1", path)
@@ -86,12 +105,19 @@ func (q *Client) BuildAndReturn(jobName, cause string, timeout, delay int) (buil
 		api += fmt.Sprintf("&identifyCause=%s", cause)
 	}
 
-	err = q.RequestWithData(http.MethodPost, api, nil, nil, 200, &build)
+	if err = q.Require(ctx, core.RequireRestfulBuild); err == nil {
+		err = q.RequestWithDataWithContext(ctx, http.MethodPost, api, nil, nil, 200, &build)
+	}
 	return
 }
 
 // GetBuild get build information of a job
 func (q *Client) GetBuild(jobName string, id int) (job *Build, err error) {
+	return q.GetBuildWithContext(context.Background(), jobName, id)
+}
+
+// GetBuildWithContext get build information of a job with a context
+func (q *Client) GetBuildWithContext(ctx context.Context, jobName string, id int) (job *Build, err error) {
 	path := ParseJobPath(jobName)
 	var api string
 	if id == -1 {
@@ -100,12 +126,17 @@ func (q *Client) GetBuild(jobName string, id int) (job *Build, err error) {
 		api = fmt.Sprintf("%s/%d/api/json", path, id)
 	}
 
-	err = q.RequestWithData("GET", api, nil, nil, 200, &job)
+	err = q.RequestWithDataWithContext(ctx, "GET", api, nil, nil, 200, &job)
 	return
 }
 
 // BuildWithParams build a job which has params
 func (q *Client) BuildWithParams(jobName string, parameters []ParameterDefinition) (err error) {
+	return q.BuildWithParamsWithContext(context.Background(), jobName, parameters)
+}
+
+// BuildWithParamsWithContext build a job which has params with a context
+func (q *Client) BuildWithParamsWithContext(ctx context.Context, jobName string, parameters []ParameterDefinition) (err error) {
 	path := ParseJobPath(jobName)
 	api := fmt.Sprintf("%s/build", path)
 
@@ -161,13 +192,13 @@ func (q *Client) BuildWithParams(jobName string, parameters []ParameterDefinitio
 			return
 		}
 
-		_, err = q.RequestWithoutData(http.MethodPost, api,
+		_, err = q.RequestWithoutDataWithContext(ctx, http.MethodPost, api,
 			map[string]string{httpdownloader.ContentType: writer.FormDataContentType()}, body, 201)
 	} else {
 		formData := url.Values{"json": {fmt.Sprintf("{\"parameter\": %s}", string(paramJSON))}}
 		payload := strings.NewReader(formData.Encode())
 
-		_, err = q.RequestWithoutData(http.MethodPost, api,
+		_, err = q.RequestWithoutDataWithContext(ctx, http.MethodPost, api,
 			map[string]string{httpdownloader.ContentType: httpdownloader.ApplicationForm}, payload, 201)
 	}
 	return
@@ -175,24 +206,39 @@ func (q *Client) BuildWithParams(jobName string, parameters []ParameterDefinitio
 
 // DisableJob disable a job
 func (q *Client) DisableJob(jobName string) (err error) {
+	return q.DisableJobWithContext(context.Background(), jobName)
+}
+
+// DisableJobWithContext disable a job with a context
+func (q *Client) DisableJobWithContext(ctx context.Context, jobName string) (err error) {
 	path := ParseJobPath(jobName)
 	api := fmt.Sprintf("%s/disable", path)
 
-	_, err = q.RequestWithoutData(http.MethodPost, api, nil, nil, 200)
+	_, err = q.RequestWithoutDataWithContext(ctx, http.MethodPost, api, nil, nil, 200)
 	return
 }
 
 // EnableJob disable a job
 func (q *Client) EnableJob(jobName string) (err error) {
+	return q.EnableJobWithContext(context.Background(), jobName)
+}
+
+// EnableJobWithContext disable a job with a context
+func (q *Client) EnableJobWithContext(ctx context.Context, jobName string) (err error) {
 	path := ParseJobPath(jobName)
 	api := fmt.Sprintf("%s/enable", path)
 
-	_, err = q.RequestWithoutData(http.MethodPost, api, nil, nil, 200)
+	_, err = q.RequestWithoutDataWithContext(ctx, http.MethodPost, api, nil, nil, 200)
 	return
 }
 
 // StopJob stops a job build
 func (q *Client) StopJob(jobName string, num int) (err error) {
+	return q.StopJobWithContext(context.Background(), jobName, num)
+}
+
+// StopJobWithContext stops a job build with a context
+func (q *Client) StopJobWithContext(ctx context.Context, jobName string, num int) (err error) {
 	path := ParseJobPath(jobName)
 
 	var api string
@@ -202,21 +248,31 @@ func (q *Client) StopJob(jobName string, num int) (err error) {
 		api = fmt.Sprintf("%s/%d/stop", path, num)
 	}
 
-	_, err = q.RequestWithoutData(http.MethodPost, api, nil, nil, 200)
+	_, err = q.RequestWithoutDataWithContext(ctx, http.MethodPost, api, nil, nil, 200)
 	return
 }
 
-// GetJob returns the job info
-func (q *Client) GetJob(name string) (job *Job, err error) {
+// GetJob returns the job info, the options could limit the fields of the job
+func (q *Client) GetJob(name string, options ...core.QueryOption) (job *Job, err error) {
+	return q.GetJobWithContext(context.Background(), name, options...)
+}
+
+// GetJobWithContext returns the job info with a context
+func (q *Client) GetJobWithContext(ctx context.Context, name string, options ...core.QueryOption) (job *Job, err error) {
 	path := ParseJobPath(name)
-	api := fmt.Sprintf("%s/api/json", path)
+	api := core.WithQuery(fmt.Sprintf("%s/api/json", path), options...)
 
-	err = q.RequestWithData(http.MethodGet, api, nil, nil, 200, &job)
+	err = q.RequestWithDataWithContext(ctx, http.MethodGet, api, nil, nil, 200, &job)
 	return
 }
 
 // AddParameters add parameters to a SimplePipeline
 func (q *Client) AddParameters(name, parameters string) (err error) {
+	return q.AddParametersWithContext(context.Background(), name, parameters)
+}
+
+// AddParametersWithContext add parameters to a SimplePipeline with a context
+func (q *Client) AddParametersWithContext(ctx context.Context, name, parameters string) (err error) {
 	path := ParseJobPath(name)
 	api := fmt.Sprintf("%s/restFul/addParameter", path)
 
@@ -224,27 +280,37 @@ func (q *Client) AddParameters(name, parameters string) (err error) {
 		"params": {parameters},
 	}
 	payload := strings.NewReader(formData.Encode())
-	_, err = q.RequestWithoutData(http.MethodPost, api, map[string]string{httpdownloader.ContentType: httpdownloader.ApplicationForm}, payload, 200)
+	_, err = q.RequestWithoutDataWithContext(ctx, http.MethodPost, api, map[string]string{httpdownloader.ContentType: httpdownloader.ApplicationForm}, payload, 200)
 	return
 }
 
 // RemoveParameters add parameters to a SimplePipeline
 func (q *Client) RemoveParameters(name, parameters string) (err error) {
+	return q.RemoveParametersWithContext(context.Background(), name, parameters)
+}
+
+// RemoveParametersWithContext add parameters to a SimplePipeline with a context
+func (q *Client) RemoveParametersWithContext(ctx context.Context, name, parameters string) (err error) {
 	path := ParseJobPath(name)
 	api := fmt.Sprintf("%s/restFul/removeParameter?params=%s", path, parameters)
 
-	_, err = q.RequestWithoutData(http.MethodPost, api, nil, nil, 200)
+	_, err = q.RequestWithoutDataWithContext(ctx, http.MethodPost, api, nil, nil, 200)
 	return
 }
 
 // GetJobTypeCategories returns all categories of jobs
 func (q *Client) GetJobTypeCategories() (jobCategories []Category, err error) {
+	return q.GetJobTypeCategoriesWithContext(context.Background())
+}
+
+// GetJobTypeCategoriesWithContext returns all categories of jobs with a context
+func (q *Client) GetJobTypeCategoriesWithContext(ctx context.Context) (jobCategories []Category, err error) {
 	var (
 		statusCode int
 		data       []byte
 	)
 
-	if statusCode, data, err = q.Request("GET", "/view/all/itemCategories?depth=3", nil, nil); err == nil {
+	if statusCode, data, err = q.RequestWithContext(ctx, "GET", "/view/all/itemCategories?depth=3", nil, nil); err == nil {
 		if statusCode == 200 {
 			type innerJobCategories struct {
 				Categories []Category
@@ -253,7 +319,7 @@ func (q *Client) GetJobTypeCategories() (jobCategories []Category, err error) {
 			err = json.Unmarshal(data, result)
 			jobCategories = result.Categories
 		} else {
-			err = fmt.Errorf("unexpected status code: %d", statusCode)
+			err = q.ErrorHandle(statusCode, data)
 		}
 	}
 	return
@@ -261,33 +327,48 @@ func (q *Client) GetJobTypeCategories() (jobCategories []Category, err error) {
 
 // GetPipeline return the pipeline object
 func (q *Client) GetPipeline(name string) (pipeline *SimplePipeline, err error) {
+	return q.GetPipelineWithContext(context.Background(), name)
+}
+
+// GetPipelineWithContext return the pipeline object with a context
+func (q *Client) GetPipelineWithContext(ctx context.Context, name string) (pipeline *SimplePipeline, err error) {
 	path := ParseJobPath(name)
 	api := fmt.Sprintf("%s/restFul", path)
-	err = q.RequestWithData("GET", api, nil, nil, 200, &pipeline)
+	err = q.RequestWithDataWithContext(ctx, "GET", api, nil, nil, 200, &pipeline)
 	return
 }
 
 // UpdatePipeline updates the pipeline script
 func (q *Client) UpdatePipeline(name, script string) (err error) {
+	return q.UpdatePipelineWithContext(context.Background(), name, script)
+}
+
+// UpdatePipelineWithContext updates the pipeline script with a context
+func (q *Client) UpdatePipelineWithContext(ctx context.Context, name, script string) (err error) {
 	formData := url.Values{}
 	formData.Add("script", script)
 
 	path := ParseJobPath(name)
 	api := fmt.Sprintf("%s/restFul/update?%s", path, formData.Encode())
 
-	_, err = q.RequestWithoutData(http.MethodPost, api, nil, nil, 200)
+	_, err = q.RequestWithoutDataWithContext(ctx, http.MethodPost, api, nil, nil, 200)
 	return
 }
 
 // GetHistory returns the build history of a job
 func (q *Client) GetHistory(name string) (builds []*Build, err error) {
+	return q.GetHistoryWithContext(context.Background(), name)
+}
+
+// GetHistoryWithContext returns the build history of a job with a context
+func (q *Client) GetHistoryWithContext(ctx context.Context, name string) (builds []*Build, err error) {
 	var job *Job
-	if job, err = q.GetJob(name); err == nil {
+	if job, err = q.GetJobWithContext(ctx, name); err == nil {
 		buildList := job.Builds // only contains basic info
 
 		var build *Build
 		for _, buildItem := range buildList {
-			build, err = q.GetBuild(name, buildItem.Number)
+			build, err = q.GetBuildWithContext(ctx, name, buildItem.Number)
 			if err != nil {
 				break
 			}
@@ -299,61 +380,91 @@ func (q *Client) GetHistory(name string) (builds []*Build, err error) {
 
 // DeleteHistory returns the build history of a job
 func (q *Client) DeleteHistory(jobName string, num int) (err error) {
+	return q.DeleteHistoryWithContext(context.Background(), jobName, num)
+}
+
+// DeleteHistoryWithContext returns the build history of a job with a context
+func (q *Client) DeleteHistoryWithContext(ctx context.Context, jobName string, num int) (err error) {
 	path := ParseJobPath(jobName)
 	api := fmt.Sprintf("%s/%d/doDelete", path, num)
-	_, err = q.RequestWithoutData(http.MethodPost, api, nil, nil, 200)
+	_, err = q.RequestWithoutDataWithContext(ctx, http.MethodPost, api, nil, nil, 200)
 	return
 }
 
 // Log get the log of a job
 func (q *Client) Log(jobName string, history int, start int64) (jobLog Log, err error) {
-	path := ParseJobPath(jobName)
-	var api string
-	if history == -1 {
-		api = fmt.Sprintf("%s%s/lastBuild/logText/progressiveText?start=%d", q.URL, path, start)
-	} else {
-		api = fmt.Sprintf("%s%s/%d/logText/progressiveText?start=%d", q.URL, path, history, start)
-	}
-	var (
-		req      *http.Request
-		response *http.Response
-	)
-
-	req, err = http.NewRequest("GET", api, nil)
-	if err == nil {
-		err = q.AuthHandle(req)
-	}
-	if err != nil {
-		return
-	}
+	return q.LogWithContext(context.Background(), jobName, history, start)
+}
 
-	client := q.GetClient()
+// LogWithContext get the log of a job with a context
+func (q *Client) LogWithContext(ctx context.Context, jobName string, history int, start int64) (jobLog Log, err error) {
 	jobLog = Log{
 		HasMore:   false,
 		Text:      "",
 		NextStart: int64(0),
 	}
 
-	if curlCmd, curlErr := http2curl.GetCurlCommand(req); curlErr == nil {
-		core.Logger.Debug("HTTP request as curl", zap.String("cmd", curlCmd.String()))
-	}
-
-	if response, err = client.Do(req); err == nil {
+	var response *http.Response
+	if response, err = q.RequestWithResponseWithContext(ctx, http.MethodGet, getLogAPI(jobName, history, start),
+		nil, nil); err == nil {
+		defer func() {
+			_ = response.Body.Close()
+		}()
 		code := response.StatusCode
 		var data []byte
-		data, err = ioutil.ReadAll(response.Body)
+		data, err = q.ReadBody(response)
 		if code == 200 {
 			jobLog.Text = string(data)
-
-			if response.Header != nil {
-				jobLog.HasMore = strings.ToLower(response.Header.Get("X-More-Data")) == "true"
-				jobLog.NextStart, _ = strconv.ParseInt(response.Header.Get("X-Text-Size"), 10, 64)
-			}
+			jobLog.HasMore, jobLog.NextStart = parseLogHeader(response.Header)
 		}
 	}
 	return
 }
 
+// LogReader is the stream of a piece of the build log
+type LogReader struct {
+	io.ReadCloser
+	// HasMore and NextStart are available before reading the stream
+	HasMore   bool
+	NextStart int64
+}
+
+// OpenLog returns the log of a job as a stream, the caller is responsible for closing it
+func (q *Client) OpenLog(jobName string, history int, start int64) (reader *LogReader, err error) {
+	return q.OpenLogWithContext(context.Background(), jobName, history, start)
+}
+
+// OpenLogWithContext returns the log of a job as a stream with a context
+func (q *Client) OpenLogWithContext(ctx context.Context, jobName string, history int, start int64) (reader *LogReader, err error) {
+	var (
+		response *http.Response
+		body     io.ReadCloser
+	)
+	if response, body, err = q.RequestWithReaderWithContext(ctx, http.MethodGet, getLogAPI(jobName, history, start),
+		nil, nil); err == nil {
+		reader = &LogReader{ReadCloser: body}
+		reader.HasMore, reader.NextStart = parseLogHeader(response.Header)
+	}
+	return
+}
+
+func getLogAPI(jobName string, history int, start int64) string {
+	path := ParseJobPath(jobName)
+	if history == -1 {
+		return fmt.Sprintf("%s/lastBuild/logText/progressiveText?start=%d", path, start)
+	}
+	return fmt.Sprintf("%s/%d/logText/progressiveText?start=%d", path, history, start)
+}
+
+// parseLogHeader parses the progressive log headers
+func parseLogHeader(header http.Header) (hasMore bool, nextStart int64) {
+	if header != nil {
+		hasMore = strings.ToLower(header.Get("X-More-Data")) == "true"
+		nextStart, _ = strconv.ParseInt(header.Get("X-Text-Size"), 10, 64)
+	}
+	return
+}
+
 // CreateJobPayload the payload for creating a job
 type CreateJobPayload struct {
 	Name string `json:"name"`
@@ -363,11 +474,21 @@ type CreateJobPayload struct {
 
 // Create can create a job
 func (q *Client) Create(jobPayload CreateJobPayload) (err error) {
-	return q.CreateJobInFolder(jobPayload, "")
+	return q.CreateWithContext(context.Background(), jobPayload)
+}
+
+// CreateWithContext can create a job with a context
+func (q *Client) CreateWithContext(ctx context.Context, jobPayload CreateJobPayload) (err error) {
+	return q.CreateJobInFolderWithContext(ctx, jobPayload, "")
 }
 
 // CreateJobInFolder creates a job in a specific folder and create folder first if the folder does not exist
 func (q *Client) CreateJobInFolder(jobPayload CreateJobPayload, path string) (err error) {
+	return q.CreateJobInFolderWithContext(context.Background(), jobPayload, path)
+}
+
+// CreateJobInFolderWithContext is the context-aware version of CreateJobInFolder
+func (q *Client) CreateJobInFolderWithContext(ctx context.Context, jobPayload CreateJobPayload, path string) (err error) {
 	// create a job in path
 	playLoadData, _ := json.Marshal(jobPayload)
 	formData := url.Values{
@@ -380,7 +501,7 @@ func (q *Client) CreateJobInFolder(jobPayload CreateJobPayload, path string) (er
 	path = ParseJobPath(path)
 	api := fmt.Sprintf("/view/all%s/createItem", path)
 	var code int
-	code, err = q.RequestWithoutData(http.MethodPost, api,
+	code, err = q.RequestWithoutDataWithContext(ctx, http.MethodPost, api,
 		map[string]string{httpdownloader.ContentType: httpdownloader.ApplicationForm}, payload, 200)
 	if code == 302 {
 		err = nil
@@ -390,8 +511,14 @@ func (q *Client) CreateJobInFolder(jobPayload CreateJobPayload, path string) (er
 
 // Delete will delete a job by name
 func (q *Client) Delete(jobName string) (err error) {
+	return q.DeleteWithContext(context.Background(), jobName)
+}
+
+// DeleteWithContext will delete a job by name with a context
+func (q *Client) DeleteWithContext(ctx context.Context, jobName string) (err error) {
 	var (
 		statusCode int
+		data       []byte
 	)
 
 	jobName = ParseJobPath(jobName)
@@ -400,9 +527,9 @@ func (q *Client) Delete(jobName string) (err error) {
 		httpdownloader.ContentType: httpdownloader.ApplicationForm,
 	}
 
-	if statusCode, _, err = q.Request(http.MethodPost, api, header, nil); err == nil {
+	if statusCode, data, err = q.RequestWithContext(ctx, http.MethodPost, api, header, nil); err == nil {
 		if statusCode != 200 && statusCode != 302 {
-			err = fmt.Errorf("unexpected status code: %d", statusCode)
+			err = q.ErrorHandle(statusCode, data)
 		}
 	}
 	return
@@ -410,8 +537,16 @@ func (q *Client) Delete(jobName string) (err error) {
 
 // GetJobInputActions returns the all pending actions
 func (q *Client) GetJobInputActions(jobName string, buildID int) (actions []InputItem, err error) {
+	return q.GetJobInputActionsWithContext(context.Background(), jobName, buildID)
+}
+
+// GetJobInputActionsWithContext returns the all pending actions with a context
+func (q *Client) GetJobInputActionsWithContext(ctx context.Context, jobName string, buildID int) (actions []InputItem, err error) {
+	if err = q.Require(ctx, core.RequirePipelineRestAPI); err != nil {
+		return
+	}
 	path := ParseJobPath(jobName)
-	err = q.RequestWithData("GET", fmt.Sprintf("%s/%d/wfapi/pendingInputActions", path, buildID), nil, nil, 200, &actions)
+	err = q.RequestWithDataWithContext(ctx, "GET", fmt.Sprintf("%s/%d/wfapi/pendingInputActions", path, buildID), nil, nil, 200, &actions)
 	return
 }
 
@@ -422,6 +557,11 @@ type JenkinsInputParametersRequest struct {
 
 // JobInputSubmit submit the pending input request
 func (q *Client) JobInputSubmit(jobName, inputID string, buildID int, abort bool, params map[string]string) (err error) {
+	return q.JobInputSubmitWithContext(context.Background(), jobName, inputID, buildID, abort, params)
+}
+
+// JobInputSubmitWithContext submit the pending input request with a context
+func (q *Client) JobInputSubmitWithContext(ctx context.Context, jobName, inputID string, buildID int, abort bool, params map[string]string) (err error) {
 	jobPath := ParseJobPath(jobName)
 	var api string
 	if abort {
@@ -444,7 +584,7 @@ func (q *Client) JobInputSubmit(jobName, inputID string, buildID int, abort bool
 	paramData, _ := json.Marshal(request)
 
 	api = fmt.Sprintf("%s?json=%s", api, string(paramData))
-	_, err = q.RequestWithoutData(http.MethodPost, api, nil, nil, 200)
+	_, err = q.RequestWithoutDataWithContext(ctx, http.MethodPost, api, nil, nil, 200)
 
 	return
 }
@@ -2,6 +2,7 @@ package job
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -265,6 +266,27 @@ var _ = Describe("job test", func() {
 			Expect(result).NotTo(BeNil())
 			Expect(result.Name).To(Equal(jobName))
 		})
+
+		It("with a tree query", func() {
+			jobName := "fake"
+
+			request, _ := http.NewRequest("GET", fmt.Sprintf("%s/job/%s/api/json?tree=%s", jobClient.URL, jobName,
+				"name%2Cbuilds%5Bnumber%5D%7B0%2C10%7D"), nil)
+			response := &http.Response{
+				StatusCode: 200,
+				Proto:      "HTTP/1.1",
+				Request:    request,
+				Body:       ioutil.NopCloser(bytes.NewBufferString(`{"name":"fake","builds":[{"number":1}]}`)),
+			}
+			roundTripper.EXPECT().
+				RoundTrip(core.NewRequestMatcher(request).WithQuery()).Return(response, nil)
+
+			result, err := jobClient.GetJob(jobName, core.WithTree(core.Field("name"),
+				core.Field("builds", core.Field("number")).Range(0, 10)))
+			Expect(err).To(BeNil())
+			Expect(result.Name).To(Equal(jobName))
+			Expect(len(result.Builds)).To(Equal(1))
+		})
 	})
 
 	Context("GetJobTypeCategories", func() {
@@ -391,6 +413,18 @@ var _ = Describe("job test", func() {
 			Expect(builds).NotTo(BeNil())
 			Expect(len(builds)).To(Equal(2))
 		})
+
+		It("with a context", func() {
+			jobName := "fakeJob"
+
+			PrepareForGetJob(roundTripper, jobClient.URL, jobName, "", "")
+			PrepareForGetBuild(roundTripper, jobClient.URL, jobName, 1, "", "")
+			PrepareForGetBuild(roundTripper, jobClient.URL, jobName, 2, "", "")
+
+			builds, err := jobClient.GetHistoryWithContext(context.TODO(), jobName)
+			Expect(err).To(BeNil())
+			Expect(len(builds)).To(Equal(2))
+		})
 	})
 
 	Context("Log", func() {
@@ -413,6 +447,34 @@ var _ = Describe("job test", func() {
 			Expect(err).To(BeNil())
 			Expect(log.Text).To(Equal("fake log"))
 		})
+
+		It("open the log as a stream", func() {
+			jobName := "fakeJob"
+
+			PrepareForJobLog(roundTripper, jobClient.URL, jobName, 1, "", "")
+
+			reader, err := jobClient.OpenLog(jobName, 1, 0)
+			Expect(err).To(BeNil())
+			defer func() {
+				_ = reader.Close()
+			}()
+			Expect(reader.HasMore).To(BeFalse())
+			Expect(reader.NextStart).To(Equal(int64(8)))
+
+			data, err := ioutil.ReadAll(reader)
+			Expect(err).To(BeNil())
+			Expect(string(data)).To(Equal("fake log"))
+		})
+
+		It("the log exceeds the size limit", func() {
+			jobName := "fakeJob"
+			jobClient.MaxResponseSize = 4
+
+			PrepareForJobLog(roundTripper, jobClient.URL, jobName, 1, "", "")
+
+			_, err := jobClient.Log(jobName, 1, 0)
+			Expect(core.IsResponseTooLarge(err)).To(BeTrue())
+		})
 	})
 
 	Context("Disable or enable a job", func() {
@@ -434,6 +496,45 @@ var _ = Describe("job test", func() {
 			Expect(err).To(BeNil())
 		})
 	})
+
+	Context("config.xml", func() {
+		It("get the config.xml of a job in a folder", func() {
+			config := `<project><description>fake</description></project>`
+			PrepareForGetConfigXML(roundTripper, jobClient.URL, "/job/folder/job/fake", "", "", config)
+
+			result, err := jobClient.GetConfigXML("folder fake")
+			Expect(err).To(BeNil())
+			Expect(result).To(Equal(config))
+		})
+
+		It("get the typed config", func() {
+			PrepareForGetConfigXML(roundTripper, jobClient.URL, "/job/fake", "", "",
+				`<?xml version='1.1' encoding='UTF-8'?><flow-definition><description>fake</description></flow-definition>`)
+
+			result, err := jobClient.GetConfig("fake")
+			Expect(err).To(BeNil())
+			Expect(result).To(BeAssignableToTypeOf(&WorkflowJob{}))
+			Expect(result.(*WorkflowJob).Description).To(Equal("fake"))
+		})
+
+		It("update the config.xml", func() {
+			config := `<project><description>fake</description></project>`
+			PrepareForUpdateConfigXML(roundTripper, jobClient.URL, "/job/fake", "", "", config)
+
+			err := jobClient.UpdateConfigXML("fake", config)
+			Expect(err).To(BeNil())
+		})
+
+		It("update the typed config", func() {
+			config := &FreeStyleProject{Description: "fake"}
+			data, err := MarshalConfig(config)
+			Expect(err).To(BeNil())
+			PrepareForUpdateConfigXML(roundTripper, jobClient.URL, "/job/fake", "", "", string(data))
+
+			err = jobClient.UpdateConfig("fake", config)
+			Expect(err).To(BeNil())
+		})
+	})
 })
 
 var _ = Describe("test function ParseJobPath", func() {
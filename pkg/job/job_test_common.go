@@ -269,3 +269,25 @@ func PrepareForCreatePipelineJob(roundTripper *mhttp.MockRoundTripper, rootURL,
 	request.Header.Add(httpdownloader.ContentType, httpdownloader.ApplicationForm)
 	core.PrepareCommonPost(request, "", roundTripper, user, password, rootURL)
 }
+
+// PrepareForGetConfigXML only for test
+func PrepareForGetConfigXML(roundTripper *mhttp.MockRoundTripper, rootURL, jobPath, user, password, config string) {
+	request, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("%s%s/config.xml", rootURL, jobPath), nil)
+	if user != "" && password != "" {
+		request.SetBasicAuth(user, password)
+	}
+	response := &http.Response{
+		StatusCode: 200,
+		Request:    request,
+		Body:       ioutil.NopCloser(bytes.NewBufferString(config)),
+	}
+	roundTripper.EXPECT().
+		RoundTrip(core.NewRequestMatcher(request)).Return(response, nil)
+}
+
+// PrepareForUpdateConfigXML only for test
+func PrepareForUpdateConfigXML(roundTripper *mhttp.MockRoundTripper, rootURL, jobPath, user, password, config string) {
+	request, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("%s%s/config.xml", rootURL, jobPath), strings.NewReader(config))
+	request.Header.Add(httpdownloader.ContentType, "application/xml")
+	core.PrepareCommonPost(request, "", roundTripper, user, password, rootURL)
+}
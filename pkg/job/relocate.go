@@ -0,0 +1,248 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/jenkins-zh/jenkins-client/pkg/core"
+	httpdownloader "github.com/linuxsuren/http-downloader/pkg"
+)
+
+// ErrJobExists indicates that a job already exists with the target name
+var ErrJobExists = errors.New("job already exists")
+
+// JobExistsError is returned if the target of a rename, move, or copy already exists
+type JobExistsError struct {
+	// Name is the full name of the existing job, such as folder/job
+	Name string
+}
+
+// Error returns the name of the existing job
+func (e *JobExistsError) Error() string {
+	return fmt.Sprintf("%v: %s", ErrJobExists, e.Name)
+}
+
+// Is reports whether the target is ErrJobExists
+func (e *JobExistsError) Is(target error) bool {
+	return target == ErrJobExists
+}
+
+// IsJobExists returns true if the error indicates that the target job already exists
+func IsJobExists(err error) bool {
+	return errors.Is(err, ErrJobExists)
+}
+
+// ErrCopyNotFixed indicates that a job is copied, but some of the CopyOptions cannot be applied to it
+var ErrCopyNotFixed = errors.New("copy not fixed")
+
+// CopyNotFixedError is returned if some of the CopyOptions cannot be applied to the copied job
+type CopyNotFixedError struct {
+	// Name is the full name of the copied job, such as folder/job
+	Name string
+	// Options are the names of the options which are not applied, such as DisplayName
+	Options []string
+}
+
+// Error returns the name of the copied job and the options which are not applied
+func (e *CopyNotFixedError) Error() string {
+	return fmt.Sprintf("%v: %s of %s", ErrCopyNotFixed, strings.Join(e.Options, ", "), e.Name)
+}
+
+// Is reports whether the target is ErrCopyNotFixed
+func (e *CopyNotFixedError) Is(target error) bool {
+	return target == ErrCopyNotFixed
+}
+
+// IsCopyNotFixed returns true if the error indicates that some of the CopyOptions are not applied
+func IsCopyNotFixed(err error) bool {
+	return errors.Is(err, ErrCopyNotFixed)
+}
+
+// CopyOptions are the fixups of the new job after copying.
+// A *CopyNotFixedError is returned if the job is copied but some of the fixups cannot be applied,
+// such as disabling a folder, or the display name of a job whose config is unknown.
+type CopyOptions struct {
+	// DisplayName is the display name of the new job, the one of the source job is dropped if it's empty
+	DisplayName string
+	// Disabled disables the new job, otherwise it's enabled even if the source job is disabled
+	Disabled bool
+}
+
+// Rename renames a job in its folder, the new name does not contain the folder
+func (q *Client) Rename(name, newName string) (err error) {
+	return q.RenameWithContext(context.Background(), name, newName)
+}
+
+// RenameWithContext renames a job in its folder with a context
+func (q *Client) RenameWithContext(ctx context.Context, name, newName string) (err error) {
+	if newName == "" || strings.ContainsAny(newName, "/ ") {
+		err = fmt.Errorf("invalid new name of the job: %q", newName)
+		return
+	}
+	names := jobNames(name)
+	if len(names) == 0 {
+		err = errors.New("the job name is required")
+		return
+	}
+	if err = q.checkNotExists(ctx, append(names[:len(names)-1:len(names)-1], newName)); err != nil {
+		return
+	}
+
+	api := fmt.Sprintf("%s/confirmRename", jobPathOf(names))
+	err = q.relocate(ctx, api, url.Values{"newName": {newName}})
+	return
+}
+
+// Move moves a job into another folder, it's moved to the top level if the folder is empty.
+// It requires the cloudbees-folder plugin.
+func (q *Client) Move(name, folder string) (err error) {
+	return q.MoveWithContext(context.Background(), name, folder)
+}
+
+// MoveWithContext moves a job into another folder with a context
+func (q *Client) MoveWithContext(ctx context.Context, name, folder string) (err error) {
+	names := jobNames(name)
+	if len(names) == 0 {
+		err = errors.New("the job name is required")
+		return
+	}
+	folderNames := jobNames(folder)
+	if err = q.checkNotExists(ctx, append(folderNames, names[len(names)-1])); err != nil {
+		return
+	}
+
+	api := fmt.Sprintf("%s/move/move", jobPathOf(names))
+	err = q.relocate(ctx, api, url.Values{"destination": {"/" + strings.Join(folderNames, "/")}})
+	return
+}
+
+// Copy copies a job to the target, which could be in another folder, such as "folder job".
+// Jenkins does not build a copied job until its config is saved, so the config is saved with the fixups.
+func (q *Client) Copy(name, target string, options CopyOptions) (err error) {
+	return q.CopyWithContext(context.Background(), name, target, options)
+}
+
+// CopyWithContext copies a job to the target with a context
+func (q *Client) CopyWithContext(ctx context.Context, name, target string, options CopyOptions) (err error) {
+	names, targetNames := jobNames(name), jobNames(target)
+	if len(names) == 0 || len(targetNames) == 0 {
+		err = errors.New("the names of the source job and the target job are required")
+		return
+	}
+	if err = q.checkNotExists(ctx, targetNames); err != nil {
+		return
+	}
+
+	payload := CreateJobPayload{
+		Name: targetNames[len(targetNames)-1],
+		Mode: "copy",
+		From: "/" + strings.Join(names, "/"),
+	}
+	if err = q.CreateJobInFolderWithContext(ctx, payload, jobPathOf(targetNames[:len(targetNames)-1])); err == nil {
+		err = q.fixCopy(ctx, targetNames, options)
+	}
+	return
+}
+
+// fixCopy saves the config of the copied job with the display name, then enables or disables it
+func (q *Client) fixCopy(ctx context.Context, names []string, options CopyOptions) (err error) {
+	path := jobPathOf(names)
+	var data string
+	if data, err = q.GetConfigXMLWithContext(ctx, path); err != nil {
+		return
+	}
+
+	var notFixed []string
+	config, parseErr := ParseConfig([]byte(data))
+	switch item := config.(type) {
+	case *WorkflowJob:
+		item.DisplayName, item.Disabled = options.DisplayName, options.Disabled
+	case *FreeStyleProject:
+		item.DisplayName, item.Disabled = options.DisplayName, options.Disabled
+	case *Folder:
+		item.DisplayName = options.DisplayName
+	case *WorkflowMultiBranchProject:
+		item.DisplayName = options.DisplayName
+	}
+	if parseErr != nil {
+		// the config of an unknown kind is saved as is
+		err = q.UpdateConfigXMLWithContext(ctx, path, data)
+		if options.DisplayName != "" || strings.Contains(data, "<displayName>") {
+			notFixed = append(notFixed, "DisplayName")
+		}
+	} else {
+		err = q.UpdateConfigWithContext(ctx, path, config)
+	}
+	if err != nil {
+		return
+	}
+
+	switch config.(type) {
+	case *Folder, *WorkflowMultiBranchProject:
+		if options.Disabled {
+			notFixed = append(notFixed, "Disabled")
+		}
+	case nil:
+		// the config of an unknown kind is not changed, then the copy is enabled or disabled via the endpoints
+		if options.Disabled {
+			err = q.DisableJobWithContext(ctx, path)
+		} else if disabledPattern.MatchString(data) {
+			err = q.EnableJobWithContext(ctx, path)
+		}
+	}
+	if err == nil && len(notFixed) > 0 {
+		err = &CopyNotFixedError{Name: strings.Join(names, "/"), Options: notFixed}
+	}
+	return
+}
+
+// disabledPattern matches the disabled state in the config.xml of a job
+var disabledPattern = regexp.MustCompile(`<disabled>\s*true\s*</disabled>`)
+
+// relocate sends the form of a rename or a move, Jenkins redirects to the new location
+func (q *Client) relocate(ctx context.Context, api string, values url.Values) (err error) {
+	var code int
+	code, err = q.RequestWithoutDataWithContext(ctx, http.MethodPost, api,
+		map[string]string{httpdownloader.ContentType: httpdownloader.ApplicationForm},
+		strings.NewReader(values.Encode()), 200)
+	if code == http.StatusFound {
+		err = nil
+	}
+	return
+}
+
+// checkNotExists returns a *JobExistsError if the job exists
+func (q *Client) checkNotExists(ctx context.Context, names []string) (err error) {
+	_, err = q.GetJobWithContext(ctx, jobPathOf(names), core.WithTree(core.Field("name")))
+	switch {
+	case err == nil:
+		err = &JobExistsError{Name: strings.Join(names, "/")}
+	case core.IsNotFound(err):
+		err = nil
+	}
+	return
+}
+
+// jobNames returns the names of the folders and the job, such as [folder job] of "folder job" or "/job/folder/job/job"
+func jobNames(name string) (names []string) {
+	segments := strings.Split(strings.Trim(ParseJobPath(name), "/"), "/")
+	for i := 1; i < len(segments); i += 2 {
+		if segments[i] != "" {
+			names = append(names, segments[i])
+		}
+	}
+	return
+}
+
+// jobPathOf returns the URL path of the job, such as /job/folder/job/job, it's empty for the top level
+func jobPathOf(names []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	return "/job/" + strings.Join(names, "/job/")
+}
@@ -0,0 +1,69 @@
+package job_test
+
+import (
+	"testing"
+
+	"github.com/jenkins-zh/jenkins-client/pkg/fakejenkins"
+	"github.com/jenkins-zh/jenkins-client/pkg/job"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRelocateJobs(t *testing.T) {
+	server := fakejenkins.NewServer()
+	defer server.Close()
+	for _, folder := range []string{"team", "team/sub", "other"} {
+		assert.Nil(t, server.CreateFolder(folder))
+	}
+	assert.Nil(t, server.CreateJob("team/sub/app", job.WorkflowJobClass))
+	assert.Nil(t, server.CreateJob("other/app", job.WorkflowJobClass))
+
+	client := &job.Client{JenkinsCore: server.JenkinsCore()}
+	assert.Nil(t, client.Rename("team sub", "renamed"))
+	assert.Equal(t, []string{"other", "other/app", "team", "team/renamed", "team/renamed/app"}, server.JobNames())
+	assert.True(t, job.IsJobExists(client.Rename("team renamed", "renamed")))
+	assert.NotNil(t, client.Rename("team renamed", "a/b"))
+
+	assert.True(t, job.IsJobExists(client.Move("team renamed app", "other")))
+	assert.Nil(t, client.Move("team renamed app", ""))
+	assert.Nil(t, client.Move("app", "team"))
+	assert.Equal(t, []string{"other", "other/app", "team", "team/app", "team/renamed"}, server.JobNames())
+
+	assert.Nil(t, client.DisableJob("team app"))
+	assert.True(t, job.IsJobExists(client.Copy("team app", "other app", job.CopyOptions{})))
+	assert.Nil(t, client.Copy("team app", "/job/team/job/renamed/job/copy", job.CopyOptions{DisplayName: "Copy"}))
+	copied, err := client.GetJob("team renamed copy")
+	if assert.Nil(t, err) {
+		assert.True(t, copied.Buildable)
+	}
+	config, err := client.GetConfig("team renamed copy")
+	if assert.Nil(t, err) {
+		assert.Equal(t, "Copy", config.(*job.WorkflowJob).DisplayName)
+	}
+
+	assert.Nil(t, client.Copy("team app", "other disabled", job.CopyOptions{Disabled: true}))
+	copied, err = client.GetJob("other disabled")
+	if assert.Nil(t, err) {
+		assert.False(t, copied.Buildable)
+	}
+
+	assert.Nil(t, client.Copy("team renamed", "other renamed", job.CopyOptions{}))
+	config, err = client.GetConfig("other renamed")
+	if assert.Nil(t, err) {
+		assert.Equal(t, job.FolderClass, config.Class())
+	}
+	err = client.Copy("team renamed", "other folder", job.CopyOptions{Disabled: true})
+	assert.True(t, job.IsCopyNotFixed(err))
+	assert.Equal(t, "copy not fixed: Disabled of other/folder", err.Error())
+
+	// the config of an unknown kind is saved as is
+	assert.Nil(t, server.CreateJob("matrix", "hudson.matrix.MatrixProject"))
+	assert.Nil(t, client.UpdateConfigXML("matrix", "<hudson.matrix.MatrixProject>"+
+		"<displayName>Matrix</displayName><disabled>true</disabled></hudson.matrix.MatrixProject>"))
+	err = client.Copy("matrix", "other matrix", job.CopyOptions{})
+	assert.True(t, job.IsCopyNotFixed(err))
+	assert.Equal(t, "copy not fixed: DisplayName of other/matrix", err.Error())
+	copied, err = client.GetJob("other matrix")
+	if assert.Nil(t, err) {
+		assert.True(t, copied.Buildable, "the copy is enabled even if the source job is disabled")
+	}
+}
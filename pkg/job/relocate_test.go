@@ -0,0 +1,44 @@
+package job
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJobNames(t *testing.T) {
+	tests := []struct {
+		name   string
+		expect []string
+		path   string
+	}{{
+		name: "",
+	}, {
+		name:   "app",
+		expect: []string{"app"},
+		path:   "/job/app",
+	}, {
+		name:   "team sub app",
+		expect: []string{"team", "sub", "app"},
+		path:   "/job/team/job/sub/job/app",
+	}, {
+		name:   "/job/team/job/app/",
+		expect: []string{"team", "app"},
+		path:   "/job/team/job/app",
+	}}
+	for _, tt := range tests {
+		names := jobNames(tt.name)
+		assert.Equal(t, tt.expect, names, tt.name)
+		assert.Equal(t, tt.path, jobPathOf(names), tt.name)
+	}
+}
+
+func TestJobExistsError(t *testing.T) {
+	err := fmt.Errorf("cannot rename: %w", &JobExistsError{Name: "team/app"})
+	assert.True(t, IsJobExists(err))
+	assert.True(t, errors.Is(err, ErrJobExists))
+	assert.Equal(t, "cannot rename: job already exists: team/app", err.Error())
+	assert.False(t, IsJobExists(errors.New("fake")))
+}
@@ -1,6 +1,7 @@
 package job
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/jenkins-zh/jenkins-client/pkg/core"
@@ -41,10 +42,16 @@ type JenkinsStatusClient struct {
 }
 
 // Get returns status of Jenkins
-func (q *JenkinsStatusClient) Get() (status *JenkinsStatus, err error) {
+func (q *JenkinsStatusClient) Get(options ...core.QueryOption) (status *JenkinsStatus, err error) {
+	return q.GetWithContext(context.Background(), options...)
+}
+
+// GetWithContext returns status of Jenkins with a context
+func (q *JenkinsStatusClient) GetWithContext(ctx context.Context, options ...core.QueryOption) (status *JenkinsStatus, err error) {
 	status = &JenkinsStatus{}
 	var response *http.Response
-	response, err = q.RequestWithResponseHeader(http.MethodGet, "/api/json", nil, nil, status)
+	response, err = q.RequestWithResponseHeaderWithContext(ctx, http.MethodGet, core.WithQuery("/api/json", options...),
+		nil, nil, status)
 	if err == nil {
 		if ver, ok := response.Header["X-Jenkins"]; ok && len(ver) > 0 {
 			status.Version = ver[0]
@@ -0,0 +1,304 @@
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/jenkins-zh/jenkins-client/pkg/core"
+)
+
+// DefaultWalkConcurrency is the number of the folders which are listed at the same time by default
+const DefaultWalkConcurrency = 4
+
+// OrganizationFolderClass is the class of the organization folder, such as a GitHub organization
+const OrganizationFolderClass = "jenkins.branch.OrganizationFolder"
+
+// folderClasses are the classes of the items which contain other items
+var folderClasses = map[string]bool{
+	FolderClass:                     true,
+	WorkflowMultiBranchProjectClass: true,
+	OrganizationFolderClass:         true,
+}
+
+// TreeItem is an item which is found by the walker
+type TreeItem struct {
+	// FullName is the path of the item, such as folder/job
+	FullName string `json:"-"`
+	// Depth is the number of the folders above the item, it's 1 for the top level items of the walk
+	Depth     int            `json:"-"`
+	Name      string         `json:"name"`
+	Class     string         `json:"_class"`
+	URL       string         `json:"url"`
+	Color     string         `json:"color"`
+	Disabled  bool           `json:"disabled"`
+	LastBuild *TreeItemBuild `json:"lastBuild"`
+	IsFolder  bool           `json:"-"`
+	// Fields are the extra fields which are chosen by WalkOptions.Fields
+	Fields map[string]json.RawMessage `json:"-"`
+}
+
+// TreeItemBuild is the last build of an item
+type TreeItemBuild struct {
+	Number int    `json:"number"`
+	Result string `json:"result"`
+}
+
+// WalkOptions are the filters and the limits of the walker, the filters do not stop descending into the folders
+type WalkOptions struct {
+	// Root is the folder to start from, such as "folder sub", it starts from the top level if it's empty
+	Root string
+	// Fields are the extra fields of the items, such as description or healthReport[score]
+	Fields []string
+
+	// Classes only yields the items of the classes
+	Classes []string
+	// NameGlob only yields the items whose name matches the glob, such as *-release
+	NameGlob string
+	// NameRegexp only yields the items whose full name matches the regexp
+	NameRegexp *regexp.Regexp
+	// Disabled only yields the items which are disabled or enabled
+	Disabled *bool
+	// LastResults only yields the items whose last build has one of the results, such as FAILURE.
+	// An empty result matches the items which have never been built or are building.
+	LastResults []string
+
+	// Prune skips the descendants of a folder if it returns true
+	Prune func(item TreeItem) bool
+	// MaxDepth is the depth of the deepest items, there is no limit if it's zero
+	MaxDepth int
+	// Concurrency is the number of the folders which are listed at the same time, it's DefaultWalkConcurrency if it's zero
+	Concurrency int
+}
+
+// matches returns true if the item passes all the filters
+func (o *WalkOptions) matches(item TreeItem) bool {
+	if len(o.Classes) > 0 && !containsString(o.Classes, item.Class) {
+		return false
+	}
+	if o.NameGlob != "" {
+		if matched, _ := path.Match(o.NameGlob, item.Name); !matched {
+			return false
+		}
+	}
+	if o.NameRegexp != nil && !o.NameRegexp.MatchString(item.FullName) {
+		return false
+	}
+	if o.Disabled != nil && *o.Disabled != item.Disabled {
+		return false
+	}
+	if len(o.LastResults) > 0 {
+		result := ""
+		if item.LastBuild != nil {
+			result = item.LastBuild.Result
+		}
+		if !containsString(o.LastResults, result) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsString(items []string, target string) bool {
+	for _, item := range items {
+		if item == target {
+			return true
+		}
+	}
+	return false
+}
+
+// WalkTree calls the function with every item under the root which passes the filters
+func (q *Client) WalkTree(options WalkOptions, fn func(item TreeItem) error) error {
+	return q.WalkTreeWithContext(context.Background(), options, fn)
+}
+
+// WalkTreeWithContext calls the function with every item under the root which passes the filters with a context.
+// The folders are listed concurrently, but the function and WalkOptions.Prune are never called at the same time.
+// The walk stops once the function returns an error, then the error is returned.
+func (q *Client) WalkTreeWithContext(ctx context.Context, options WalkOptions, fn func(item TreeItem) error) (err error) {
+	if options.NameGlob != "" {
+		if _, err = path.Match(options.NameGlob, ""); err != nil {
+			err = fmt.Errorf("invalid name glob %q, error is %v", options.NameGlob, err)
+			return
+		}
+	}
+	concurrency := options.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultWalkConcurrency
+	}
+
+	walkCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	w := &treeWalker{
+		client:    q,
+		options:   options,
+		fn:        fn,
+		ctx:       walkCtx,
+		cancel:    cancel,
+		semaphore: make(chan struct{}, concurrency),
+		tree:      walkTree(options.Fields),
+	}
+	root := jobNames(options.Root)
+	w.rootDepth = len(root)
+	w.group.Add(1)
+	go w.visit(root)
+	w.group.Wait()
+
+	if err = w.err; err == nil {
+		err = ctx.Err()
+	}
+	return
+}
+
+// GetTree returns all the items under the root which pass the filters, they are sorted by the full name
+func (q *Client) GetTree(options WalkOptions) (items []TreeItem, err error) {
+	return q.GetTreeWithContext(context.Background(), options)
+}
+
+// GetTreeWithContext returns all the items under the root which pass the filters with a context
+func (q *Client) GetTreeWithContext(ctx context.Context, options WalkOptions) (items []TreeItem, err error) {
+	err = q.WalkTreeWithContext(ctx, options, func(item TreeItem) error {
+		items = append(items, item)
+		return nil
+	})
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].FullName < items[j].FullName
+	})
+	return
+}
+
+// walkTree returns the fields of a level, jobs[name]{0,0} tells whether an item is a folder of any kind
+func walkTree(fields []string) core.Tree {
+	children := append(core.Fields("name", "_class", "url", "color", "disabled"),
+		core.Field("lastBuild", core.Fields("number", "result")...),
+		core.Field("jobs", core.Field("name")).Range(0, 0))
+	for _, field := range fields {
+		children = append(children, core.Field(field))
+	}
+	return core.NewTree(core.Field("jobs", children...))
+}
+
+type treeWalker struct {
+	client    *Client
+	options   WalkOptions
+	fn        func(item TreeItem) error
+	ctx       context.Context
+	cancel    context.CancelFunc
+	semaphore chan struct{}
+	tree      core.Tree
+	rootDepth int
+	group     sync.WaitGroup
+
+	// mutex guards fn, Prune, and err
+	mutex sync.Mutex
+	err   error
+}
+
+// visit lists a folder, yields its items, then visits its sub-folders
+func (w *treeWalker) visit(folder []string) {
+	defer w.group.Done()
+	select {
+	case w.semaphore <- struct{}{}:
+	case <-w.ctx.Done():
+		return
+	}
+	items, err := w.client.listTreeItems(w.ctx, folder, w.tree, w.options.Fields)
+	<-w.semaphore
+	if err != nil {
+		w.fail(err)
+		return
+	}
+
+	for _, item := range items {
+		if w.ctx.Err() != nil {
+			return
+		}
+		item.Depth = len(folder) + 1 - w.rootDepth
+		if w.options.matches(item) {
+			w.mutex.Lock()
+			err = w.fn(item)
+			w.mutex.Unlock()
+			if err != nil {
+				w.fail(err)
+				return
+			}
+		}
+
+		if item.IsFolder && (w.options.MaxDepth <= 0 || item.Depth < w.options.MaxDepth) && !w.prune(item) {
+			w.group.Add(1)
+			go w.visit(append(folder[:len(folder):len(folder)], item.Name))
+		}
+	}
+}
+
+// prune returns true if the descendants of the folder should be skipped
+func (w *treeWalker) prune(item TreeItem) bool {
+	if w.options.Prune == nil {
+		return false
+	}
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.options.Prune(item)
+}
+
+// fail keeps the first error then stops the walk
+func (w *treeWalker) fail(err error) {
+	w.mutex.Lock()
+	if w.err == nil {
+		w.err = err
+	}
+	w.mutex.Unlock()
+	w.cancel()
+}
+
+// listTreeItems returns the direct items of a folder, or the top level items if the folder is empty
+func (q *Client) listTreeItems(ctx context.Context, folder []string, tree core.Tree,
+	fields []string) (items []TreeItem, err error) {
+	result := struct {
+		Jobs []json.RawMessage `json:"jobs"`
+	}{}
+	api := core.WithQuery(fmt.Sprintf("%s/api/json", jobPathOf(folder)), core.WithTree(tree...))
+	if err = q.RequestWithDataWithContext(ctx, http.MethodGet, api, nil, nil, 200, &result); err != nil {
+		return
+	}
+
+	items = make([]TreeItem, 0, len(result.Jobs))
+	for _, data := range result.Jobs {
+		var (
+			item TreeItem
+			raw  map[string]json.RawMessage
+		)
+		if err = json.Unmarshal(data, &item); err != nil {
+			return
+		}
+		if err = json.Unmarshal(data, &raw); err != nil {
+			return
+		}
+
+		_, hasJobs := raw["jobs"]
+		item.IsFolder = hasJobs || folderClasses[item.Class]
+		item.FullName = strings.Join(append(folder[:len(folder):len(folder)], item.Name), "/")
+		item.Disabled = item.Disabled || strings.HasPrefix(item.Color, "disabled")
+		if len(fields) > 0 {
+			item.Fields = map[string]json.RawMessage{}
+			for _, field := range fields {
+				name := field
+				if index := strings.IndexAny(name, "[{"); index >= 0 {
+					name = name[:index]
+				}
+				if value, ok := raw[name]; ok {
+					item.Fields[name] = value
+				}
+			}
+		}
+		items = append(items, item)
+	}
+	return
+}
@@ -0,0 +1,72 @@
+package job_test
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/jenkins-zh/jenkins-client/pkg/core"
+	"github.com/jenkins-zh/jenkins-client/pkg/fakejenkins"
+	"github.com/jenkins-zh/jenkins-client/pkg/job"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWalkTree(t *testing.T) {
+	server := fakejenkins.NewServer()
+	defer server.Close()
+	for _, folder := range []string{"team", "team/sub", "archive"} {
+		assert.Nil(t, server.CreateFolder(folder))
+	}
+	assert.Nil(t, server.CreateJob("team/branches", job.WorkflowMultiBranchProjectClass))
+	assert.Nil(t, server.CreateJob("team/branches/main", job.WorkflowJobClass))
+	for _, name := range []string{"app", "team/app-release", "team/sub/lib-release", "archive/old"} {
+		assert.Nil(t, server.CreateJob(name, job.WorkflowJobClass))
+	}
+	assert.Nil(t, server.SetScenario("team/app-release", fakejenkins.BuildFailsAfter(0)))
+
+	client := &job.Client{JenkinsCore: server.JenkinsCore()}
+	assert.Nil(t, client.Build("team app-release"))
+	assert.Nil(t, client.DisableJob("archive old"))
+
+	fullNames := func(options job.WalkOptions) (names []string) {
+		items, err := client.GetTree(options)
+		assert.Nil(t, err)
+		for _, item := range items {
+			names = append(names, item.FullName)
+		}
+		return
+	}
+	assert.Equal(t, []string{"app", "archive", "archive/old", "team", "team/app-release", "team/branches",
+		"team/branches/main", "team/sub", "team/sub/lib-release"}, fullNames(job.WalkOptions{Concurrency: 1}))
+	assert.Equal(t, []string{"team/app-release", "team/sub/lib-release"},
+		fullNames(job.WalkOptions{NameGlob: "*-release", Classes: []string{job.WorkflowJobClass}}))
+	assert.Equal(t, []string{"team/app-release"}, fullNames(job.WalkOptions{LastResults: []string{fakejenkins.ResultFailure}}))
+	disabled := true
+	assert.Equal(t, []string{"archive/old"}, fullNames(job.WalkOptions{Disabled: &disabled}))
+	assert.Equal(t, []string{"team/branches", "team/sub"}, fullNames(job.WalkOptions{Root: "team", MaxDepth: 1,
+		NameRegexp: regexp.MustCompile(`^team/(branches|sub)$`)}))
+	assert.Equal(t, []string{"app", "archive", "team", "team/app-release", "team/branches", "team/sub"},
+		fullNames(job.WalkOptions{Prune: func(item job.TreeItem) bool {
+			return item.Name == "archive" || item.Depth > 1
+		}}))
+
+	items, err := client.GetTree(job.WalkOptions{Root: "team sub", Fields: []string{"description"}})
+	if assert.Nil(t, err) && assert.Len(t, items, 1) {
+		assert.Equal(t, 1, items[0].Depth)
+		assert.Contains(t, items[0].Fields, "description")
+	}
+
+	stop := errors.New("stop")
+	count := 0
+	err = client.WalkTree(job.WalkOptions{}, func(item job.TreeItem) error {
+		count++
+		return stop
+	})
+	assert.Equal(t, stop, err)
+	assert.Equal(t, 1, count)
+
+	_, err = client.GetTree(job.WalkOptions{Root: "missing"})
+	assert.True(t, core.IsNotFound(err))
+	_, err = client.GetTree(job.WalkOptions{NameGlob: "["})
+	assert.NotNil(t, err)
+}
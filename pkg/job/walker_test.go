@@ -0,0 +1,64 @@
+package job
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWalkTree(t *testing.T) {
+	assert.Equal(t, "jobs[name,_class,url,color,disabled,lastBuild[number,result],jobs[name]{0,0}]",
+		walkTree(nil).String())
+	assert.Equal(t, "jobs[name,_class,url,color,disabled,lastBuild[number,result],jobs[name]{0,0},description,healthReport[score]]",
+		walkTree([]string{"description", "healthReport[score]"}).String())
+}
+
+func TestWalkOptionsMatches(t *testing.T) {
+	disabled, enabled := true, false
+	item := TreeItem{
+		FullName:  "team/app-release",
+		Name:      "app-release",
+		Class:     WorkflowJobClass,
+		LastBuild: &TreeItemBuild{Number: 3, Result: "FAILURE"},
+	}
+	tests := []struct {
+		name    string
+		options WalkOptions
+		expect  bool
+	}{{
+		name:   "no filters",
+		expect: true,
+	}, {
+		name:    "class",
+		options: WalkOptions{Classes: []string{FolderClass}},
+	}, {
+		name:    "glob",
+		options: WalkOptions{NameGlob: "*-release", Classes: []string{WorkflowJobClass}},
+		expect:  true,
+	}, {
+		name:    "glob does not match the full name",
+		options: WalkOptions{NameGlob: "team/*"},
+	}, {
+		name:    "regexp",
+		options: WalkOptions{NameRegexp: regexp.MustCompile(`^team/`)},
+		expect:  true,
+	}, {
+		name:    "disabled",
+		options: WalkOptions{Disabled: &disabled},
+	}, {
+		name:    "enabled",
+		options: WalkOptions{Disabled: &enabled},
+		expect:  true,
+	}, {
+		name:    "last result",
+		options: WalkOptions{LastResults: []string{"FAILURE", "UNSTABLE"}},
+		expect:  true,
+	}, {
+		name:    "never built",
+		options: WalkOptions{LastResults: []string{""}},
+	}}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expect, tt.options.matches(item), tt.name)
+	}
+}
@@ -1,6 +1,7 @@
 package plugin
 
 import (
+	"context"
 	"crypto/tls"
 	"encoding/json"
 	"fmt"
@@ -127,6 +128,11 @@ func (d *API) ShowTrend(name string) (trend string, err error) {
 
 // DownloadPlugins will download those plugins from update center
 func (d *API) DownloadPlugins(names []string) (err error) {
+	return d.DownloadPluginsWithContext(context.Background(), names)
+}
+
+// DownloadPluginsWithContext will download those plugins from update center with a context
+func (d *API) DownloadPluginsWithContext(ctx context.Context, names []string) (err error) {
 	d.dependencyMap = make(map[string]string)
 	core.Logger.Info("start to collect plugin dependencies...")
 	plugins := make([]Info, 0)
@@ -144,7 +150,7 @@ func (d *API) DownloadPlugins(names []string) (err error) {
 				UseMirror:    d.UseMirror,
 				MirrorURL:    d.MirrorURL,
 			}
-			if err = jclient.DownloadPluginWithVersion(name); err != nil {
+			if err = jclient.DownloadPluginWithVersionWithContext(ctx, name); err != nil {
 				return
 			}
 		}
@@ -158,7 +164,7 @@ func (d *API) DownloadPlugins(names []string) (err error) {
 			zap.String("url", plugin.URL),
 			zap.Int("number", i))
 
-		if err = d.download(plugin.URL, plugin.Name); err != nil {
+		if err = d.downloadWithContext(ctx, plugin.URL, plugin.Name); err != nil {
 			core.Logger.Error("download plugin error", zap.String("name", plugin.Name), zap.Error(err))
 			break
 		}
@@ -175,7 +181,7 @@ func (d *API) getMirrorURL(url string) (mirror string) {
 	return
 }
 
-func (d *API) download(url string, name string) (err error) {
+func (d *API) downloadWithContext(ctx context.Context, url string, name string) (err error) {
 	url = d.getMirrorURL(url)
 	core.Logger.Info("prepare to download", zap.String("name", name), zap.String("url", url))
 
@@ -184,6 +190,7 @@ func (d *API) download(url string, name string) (err error) {
 		TargetFilePath: path.Join(d.DownloadDir, fmt.Sprintf("%s.hpi", name)),
 		URL:            url,
 		ShowProgress:   d.ShowProgress,
+		Context:        ctx,
 	}
 	err = downloader.DownloadFile()
 	return
@@ -1,6 +1,9 @@
 package plugin
 
 import (
+	"context"
+	"errors"
+	"net/http"
 	"os"
 
 	"github.com/golang/mock/gomock"
@@ -155,6 +158,17 @@ var _ = Describe("plugin api test", func() {
 			}()
 		})
 
+		It("the download of a plugin with version is cancelled", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			roundTripper.EXPECT().RoundTrip(gomock.Any()).DoAndReturn(func(request *http.Request) (*http.Response, error) {
+				return nil, request.Context().Err()
+			})
+
+			err := pluginAPI.DownloadPluginsWithContext(ctx, []string{"fake@1.0.0"})
+			Expect(errors.Is(err, context.Canceled)).To(BeTrue())
+		})
+
 		It("batch search plugins", func() {
 			names = []string{"fake"}
 
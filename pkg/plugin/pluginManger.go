@@ -2,6 +2,7 @@ package plugin
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -78,9 +79,14 @@ var debugLogFile = "debug.html"
 
 // CheckUpdate fetch the latest plugins from update center site
 func (p *Manager) CheckUpdate(handle func(*http.Response)) (err error) {
+	return p.CheckUpdateWithContext(context.Background(), handle)
+}
+
+// CheckUpdateWithContext fetch the latest plugins from update center site with a context
+func (p *Manager) CheckUpdateWithContext(ctx context.Context, handle func(*http.Response)) (err error) {
 	api := "/pluginManager/checkUpdatesServer"
 	var response *http.Response
-	response, err = p.RequestWithResponseHeader(http.MethodPost, api, nil, nil, nil)
+	response, err = p.RequestWithResponseHeaderWithContext(ctx, http.MethodPost, api, nil, nil, nil)
 	if err == nil {
 		p.handleCheck(handle)(response)
 	}
@@ -89,31 +95,51 @@ func (p *Manager) CheckUpdate(handle func(*http.Response)) (err error) {
 
 // GetAvailablePlugins get the aviable plugins from Jenkins
 func (p *Manager) GetAvailablePlugins() (pluginList *AvailablePluginList, err error) {
-	err = p.RequestWithData(http.MethodGet, "/pluginManager/plugins", nil, nil, 200, &pluginList)
+	return p.GetAvailablePluginsWithContext(context.Background())
+}
+
+// GetAvailablePluginsWithContext get the aviable plugins from Jenkins with a context
+func (p *Manager) GetAvailablePluginsWithContext(ctx context.Context) (pluginList *AvailablePluginList, err error) {
+	err = p.RequestWithDataWithContext(ctx, http.MethodGet, "/pluginManager/plugins", nil, nil, 200, &pluginList)
 	return
 }
 
 // GetPlugins get installed plugins
 func (p *Manager) GetPlugins(depth int) (pluginList *InstalledPluginList, err error) {
+	return p.GetPluginsWithContext(context.Background(), depth)
+}
+
+// GetPluginsWithContext get installed plugins with a context
+func (p *Manager) GetPluginsWithContext(ctx context.Context, depth int) (pluginList *InstalledPluginList, err error) {
 	if depth > 1 {
-		err = p.RequestWithData(http.MethodGet, fmt.Sprintf("/pluginManager/api/json?depth=%d", depth), nil, nil, 200, &pluginList)
+		err = p.RequestWithDataWithContext(ctx, http.MethodGet, fmt.Sprintf("/pluginManager/api/json?depth=%d", depth), nil, nil, 200, &pluginList)
 	} else {
-		err = p.RequestWithData(http.MethodGet, "/pluginManager/api/json?depth=1", nil, nil, 200, &pluginList)
+		err = p.RequestWithDataWithContext(ctx, http.MethodGet, "/pluginManager/api/json?depth=1", nil, nil, 200, &pluginList)
 	}
 	return
 }
 
 // GetPluginsFormula get the plugin list with Jenkins formula format
 func (p *Manager) GetPluginsFormula(data interface{}) (err error) {
+	return p.GetPluginsFormulaWithContext(context.Background(), data)
+}
+
+// GetPluginsFormulaWithContext get the plugin list with Jenkins formula format with a context
+func (p *Manager) GetPluginsFormulaWithContext(ctx context.Context, data interface{}) (err error) {
 	api := "jcliPluginManager/pluginList"
-	err = p.RequestWithData(http.MethodGet, api, nil, nil, 200, data)
+	err = p.RequestWithDataWithContext(ctx, http.MethodGet, api, nil, nil, 200, data)
 	return
 }
 
 // FindInstalledPlugin find the exist plugin by name
 func (p *Manager) FindInstalledPlugin(name string) (targetPlugin *InstalledPlugin, err error) {
+	return p.FindInstalledPluginWithContext(context.Background(), name)
+}
+
+// FindInstalledPluginWithContext find the exist plugin by name with a context
+func (p *Manager) FindInstalledPluginWithContext(ctx context.Context, name string) (targetPlugin *InstalledPlugin, err error) {
 	var plugins *InstalledPluginList
-	if plugins, err = p.GetPlugins(1); err == nil {
+	if plugins, err = p.GetPluginsWithContext(ctx, 1); err == nil {
 		for _, plugin := range plugins.Plugins {
 			if plugin.ShortName == name {
 				targetPlugin = &plugin
@@ -152,26 +178,31 @@ func (p *Manager) getVersionalPlugins(names []string) []string {
 
 // InstallPlugin install a plugin by name
 func (p *Manager) InstallPlugin(names []string) (err error) {
+	return p.InstallPluginWithContext(context.Background(), names)
+}
+
+// InstallPluginWithContext install a plugin by name with a context
+func (p *Manager) InstallPluginWithContext(ctx context.Context, names []string) (err error) {
 	plugins := p.getPluginsInstallQuery(names)
 	versionalPlugins := p.getVersionalPlugins(names)
 	if plugins != "" {
 		for _, plugin := range strings.Split(plugins, "&") {
-			if err = p.installPluginsWithoutVersion(plugin); err != nil {
+			if err = p.installPluginsWithoutVersion(ctx, plugin); err != nil {
 				return
 			}
 		}
 	}
 
 	if err == nil && len(versionalPlugins) > 0 {
-		err = p.installPluginsWithVersion(versionalPlugins)
+		err = p.installPluginsWithVersion(ctx, versionalPlugins)
 	}
 	return
 }
 
-func (p *Manager) installPluginsWithoutVersion(plugins string) (err error) {
+func (p *Manager) installPluginsWithoutVersion(ctx context.Context, plugins string) (err error) {
 	api := fmt.Sprintf("/pluginManager/install?%s", plugins)
 	var response *http.Response
-	response, err = p.RequestWithResponse(http.MethodPost, api, nil, nil)
+	response, err = p.RequestWithResponseWithContext(ctx, http.MethodPost, api, nil, nil)
 	if response != nil && response.StatusCode == 400 {
 		if errMsg, ok := response.Header["X-Error"]; ok {
 			for _, msg := range errMsg {
@@ -184,9 +215,9 @@ func (p *Manager) installPluginsWithoutVersion(plugins string) (err error) {
 	return
 }
 
-func (p *Manager) installPluginsWithVersion(plugins []string) (err error) {
+func (p *Manager) installPluginsWithVersion(ctx context.Context, plugins []string) (err error) {
 	for _, plugin := range plugins {
-		if err = p.installPluginWithVersion(plugin); err != nil {
+		if err = p.installPluginWithVersion(ctx, plugin); err != nil {
 			break
 		}
 	}
@@ -194,21 +225,26 @@ func (p *Manager) installPluginsWithVersion(plugins []string) (err error) {
 }
 
 // installPluginWithVersion install a plugin by name & version
-func (p *Manager) installPluginWithVersion(name string) (err error) {
+func (p *Manager) installPluginWithVersion(ctx context.Context, name string) (err error) {
 	pluginName := fmt.Sprintf("%s.hpi", strings.Split(name, "@")[0])
 	defer func(name string) {
 		// ignore error
 		_ = os.Remove(name)
 	}(pluginName)
 
-	if err = p.DownloadPluginWithVersion(name); err == nil {
-		err = p.Upload(pluginName)
+	if err = p.DownloadPluginWithVersionWithContext(ctx, name); err == nil {
+		err = p.UploadWithContext(ctx, pluginName)
 	}
 	return
 }
 
 // DownloadPluginWithVersion downloads a plugin with name and version
 func (p *Manager) DownloadPluginWithVersion(nameWithVer string) error {
+	return p.DownloadPluginWithVersionWithContext(context.Background(), nameWithVer)
+}
+
+// DownloadPluginWithVersionWithContext downloads a plugin with name and version with a context
+func (p *Manager) DownloadPluginWithVersionWithContext(ctx context.Context, nameWithVer string) error {
 	pluginAPI := API{
 		RoundTripper: p.RoundTripper,
 		UseMirror:    p.UseMirror,
@@ -221,20 +257,25 @@ func (p *Manager) DownloadPluginWithVersion(nameWithVer string) error {
 	version := pluginVersion[1]
 	url := fmt.Sprintf("https://updates.jenkins-ci.org/download/plugins/%s/%s/%s.hpi", name, version, name)
 
-	return pluginAPI.download(pluginAPI.getMirrorURL(url), name)
+	return pluginAPI.downloadWithContext(ctx, pluginAPI.getMirrorURL(url), name)
 }
 
 // UninstallPlugin uninstall a plugin by name
 func (p *Manager) UninstallPlugin(name string) (err error) {
+	return p.UninstallPluginWithContext(context.Background(), name)
+}
+
+// UninstallPluginWithContext uninstall a plugin by name with a context
+func (p *Manager) UninstallPluginWithContext(ctx context.Context, name string) (err error) {
 	api := fmt.Sprintf("/pluginManager/plugin/%s/doUninstall", name)
 	var (
 		statusCode int
 		data       []byte
 	)
 
-	if statusCode, data, err = p.Request(http.MethodPost, api, nil, nil); err == nil {
+	if statusCode, data, err = p.RequestWithContext(ctx, http.MethodPost, api, nil, nil); err == nil {
 		if statusCode != 200 {
-			err = fmt.Errorf("unexpected status code: %d", statusCode)
+			err = p.ErrorHandle(statusCode, data)
 			if p.Debug {
 				// ignore error
 				_ = ioutil.WriteFile(debugLogFile, data, 0664)
@@ -246,10 +287,15 @@ func (p *Manager) UninstallPlugin(name string) (err error) {
 
 // Upload will upload a file from local filesystem into Jenkins
 func (p *Manager) Upload(pluginFile string) (err error) {
+	return p.UploadWithContext(context.Background(), pluginFile)
+}
+
+// UploadWithContext will upload a file from local filesystem into Jenkins with a context
+func (p *Manager) UploadWithContext(ctx context.Context, pluginFile string) (err error) {
 	api := fmt.Sprintf("%s/pluginManager/uploadPlugin", p.URL)
 	extraParams := map[string]string{}
 	var request *http.Request
-	if request, err = p.newfileUploadRequest(api, extraParams, "@name", pluginFile); err != nil {
+	if request, err = p.newfileUploadRequest(ctx, api, extraParams, "@name", pluginFile); err != nil {
 		return
 	}
 
@@ -262,7 +308,8 @@ func (p *Manager) Upload(pluginFile string) (err error) {
 	if response, err = jcli.Do(request); err != nil {
 		return
 	} else if response.StatusCode != 200 {
-		err = fmt.Errorf("StatusCode: %d", response.StatusCode)
+		data, _ := ioutil.ReadAll(response.Body)
+		err = core.NewHTTPError(response, data)
 	}
 	return err
 }
@@ -276,7 +323,7 @@ func (p *Manager) handleCheck(handle func(*http.Response)) func(*http.Response)
 	return handle
 }
 
-func (p *Manager) newfileUploadRequest(uri string, params map[string]string, paramName, path string) (req *http.Request, err error) {
+func (p *Manager) newfileUploadRequest(ctx context.Context, uri string, params map[string]string, paramName, path string) (req *http.Request, err error) {
 	var file *os.File
 	file, err = os.Open(path)
 	if err != nil {
@@ -322,9 +369,9 @@ func (p *Manager) newfileUploadRequest(uri string, params map[string]string, par
 			Title:  "Uploading",
 		}
 		progressWriter.Init()
-		req, err = http.NewRequest(http.MethodPost, uri, progressWriter)
+		req, err = http.NewRequestWithContext(ctx, http.MethodPost, uri, progressWriter)
 	} else {
-		req, err = http.NewRequest(http.MethodPost, uri, bytesBuffer)
+		req, err = http.NewRequestWithContext(ctx, http.MethodPost, uri, bytesBuffer)
 	}
 
 	req.Header.Set("Content-Type", writer.FormDataContentType())
@@ -1,6 +1,7 @@
 package plugin
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -105,13 +106,23 @@ type CenterPlugin struct {
 
 // Status returns the status of Jenkins
 func (u *UpdateCenterManager) Status() (status *UpdateCenter, err error) {
-	err = u.RequestWithData(http.MethodGet, "/updateCenter/api/json?pretty=false&depth=1", nil, nil, 200, &status)
+	return u.StatusWithContext(context.Background())
+}
+
+// StatusWithContext returns the status of Jenkins with a context
+func (u *UpdateCenterManager) StatusWithContext(ctx context.Context) (status *UpdateCenter, err error) {
+	err = u.RequestWithDataWithContext(ctx, http.MethodGet, "/updateCenter/api/json?pretty=false&depth=1", nil, nil, 200, &status)
 	return
 }
 
 // Upgrade the Jenkins core
 func (u *UpdateCenterManager) Upgrade() (err error) {
-	_, err = u.RequestWithoutData(http.MethodPost, "/updateCenter/upgrade",
+	return u.UpgradeWithContext(context.Background())
+}
+
+// UpgradeWithContext upgrades the Jenkins core with a context
+func (u *UpdateCenterManager) UpgradeWithContext(ctx context.Context) (err error) {
+	_, err = u.RequestWithoutDataWithContext(ctx, http.MethodPost, "/updateCenter/upgrade",
 		nil, nil, 200)
 	return
 }
@@ -155,30 +166,45 @@ func (u *UpdateCenterManager) GetJenkinsWarURL() (warURL string) {
 
 // GetSite is get Available Plugins and Updated Plugins from UpdateCenter
 func (u *UpdateCenterManager) GetSite() (site *CenterSite, err error) {
-	err = u.RequestWithData(http.MethodGet, "/updateCenter/site/default/api/json?pretty=true&depth=2", nil, nil, 200, &site)
+	return u.GetSiteWithContext(context.Background())
+}
+
+// GetSiteWithContext is get Available Plugins and Updated Plugins from UpdateCenter with a context
+func (u *UpdateCenterManager) GetSiteWithContext(ctx context.Context) (site *CenterSite, err error) {
+	err = u.RequestWithDataWithContext(ctx, http.MethodGet, "/updateCenter/site/default/api/json?pretty=true&depth=2", nil, nil, 200, &site)
 	return
 }
 
 // ChangeUpdateCenterSite updates the update center address
 func (u *UpdateCenterManager) ChangeUpdateCenterSite(name, updateCenterURL string) (err error) {
+	return u.ChangeUpdateCenterSiteWithContext(context.Background(), name, updateCenterURL)
+}
+
+// ChangeUpdateCenterSiteWithContext updates the update center address with a context
+func (u *UpdateCenterManager) ChangeUpdateCenterSiteWithContext(ctx context.Context, name, updateCenterURL string) (err error) {
 	formData := url.Values{}
 	formData.Add("site", updateCenterURL)
 	payload := strings.NewReader(formData.Encode())
 
 	api := "/pluginManager/siteConfigure"
-	_, err = u.RequestWithoutData(http.MethodPost, api,
+	_, err = u.RequestWithoutDataWithContext(ctx, http.MethodPost, api,
 		map[string]string{httpdownloader.ContentType: httpdownloader.ApplicationForm}, payload, 200)
 	return
 }
 
 // SetMirrorCertificate take the mirror certificate file or not
 func (u *UpdateCenterManager) SetMirrorCertificate(enable bool) (err error) {
+	return u.SetMirrorCertificateWithContext(context.Background(), enable)
+}
+
+// SetMirrorCertificateWithContext take the mirror certificate file or not with a context
+func (u *UpdateCenterManager) SetMirrorCertificateWithContext(ctx context.Context, enable bool) (err error) {
 	api := "/update-center-mirror/use"
 	if !enable {
 		api = "/update-center-mirror/remove"
 	}
 
-	_, err = u.RequestWithoutData(http.MethodPost, api,
+	_, err = u.RequestWithoutDataWithContext(ctx, http.MethodPost, api,
 		map[string]string{httpdownloader.ContentType: httpdownloader.ApplicationForm}, nil, 200)
 	return
 }
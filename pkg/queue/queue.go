@@ -1,6 +1,7 @@
 package queue
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 
@@ -12,17 +13,28 @@ type Client struct {
 	core.JenkinsCore
 }
 
-// Get returns the job queue
-func (q *Client) Get() (status *JobQueue, err error) {
-	err = q.RequestWithData(http.MethodGet, "/queue/api/json", nil, nil, 200, &status)
+// Get returns the job queue, the options could limit the fields of the queue
+func (q *Client) Get(options ...core.QueryOption) (status *JobQueue, err error) {
+	return q.GetWithContext(context.Background(), options...)
+}
+
+// GetWithContext returns the job queue with a context
+func (q *Client) GetWithContext(ctx context.Context, options ...core.QueryOption) (status *JobQueue, err error) {
+	err = q.RequestWithDataWithContext(ctx, http.MethodGet, core.WithQuery("/queue/api/json", options...),
+		nil, nil, 200, &status)
 	return
 }
 
 // Cancel will cancel a job from the queue
 func (q *Client) Cancel(id int) (err error) {
+	return q.CancelWithContext(context.Background(), id)
+}
+
+// CancelWithContext will cancel a job from the queue with a context
+func (q *Client) CancelWithContext(ctx context.Context, id int) (err error) {
 	api := fmt.Sprintf("/queue/cancelItem?id=%d", id)
 	var statusCode int
-	if statusCode, err = q.RequestWithoutData(http.MethodPost, api, nil, nil, 302); err != nil &&
+	if statusCode, err = q.RequestWithoutDataWithContext(ctx, http.MethodPost, api, nil, nil, 302); err != nil &&
 		(statusCode == 200 ||
 			statusCode == 404) { // 404 should be an error, but no idea why it can be triggered successful
 		err = nil
@@ -0,0 +1,90 @@
+package script
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name     string
+		script   string
+		bindings map[string]interface{}
+		expect   string
+		hasErr   bool
+	}{{
+		name:   "without bindings",
+		script: "println 'hello'",
+		expect: prelude + "\nprintln 'hello'",
+	}, {
+		name:     "escape the bindings",
+		script:   "println name",
+		bindings: map[string]interface{}{"name": "it's\n'); System.exit(0); ('\\", "count": 1},
+		expect: prelude + `def __bindings = new groovy.json.JsonSlurperClassic().parseText('{"count":1,` +
+			`"name":"it\'s\\n\'); System.exit(0); (\'\\\\"}'); def count = __bindings['count']; ` +
+			"def name = __bindings['name']; \nprintln name",
+	}, {
+		name:   "keep the imports in front",
+		script: "import jenkins.model.*\n\n// comment\nprintln Jenkins.instance.numExecutors",
+		expect: "import jenkins.model.*\n\n// comment\n" + prelude + "\nprintln Jenkins.instance.numExecutors",
+	}, {
+		name:     "invalid name",
+		bindings: map[string]interface{}{"a b": 1},
+		hasErr:   true,
+	}, {
+		name:     "reserved name",
+		bindings: map[string]interface{}{"jsonResult": 1},
+		hasErr:   true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source, err := Render(tt.script, tt.bindings)
+			if tt.hasErr {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, tt.expect, source)
+			}
+		})
+	}
+}
+
+func TestParseGroovyError(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		class   string
+		message string
+	}{{
+		name:   "no exception",
+		output: "java.lang.Exception: it's printed by the script\nok\n",
+	}, {
+		name: "runtime exception",
+		output: "before\njava.lang.IllegalStateException: bad state\n" +
+			"\tat Script1.run(Script1.groovy:3)\n\tat groovy.lang.GroovyShell.evaluate(GroovyShell.java:574)\n",
+		class:   "java.lang.IllegalStateException",
+		message: "bad state",
+	}, {
+		name: "compilation errors",
+		output: "org.codehaus.groovy.control.MultipleCompilationErrorsException: startup failed:\n" +
+			"Script1.groovy: 2: unexpected token: } @ line 2, column 1.\n   }\n   ^\n\n1 error\n\n" +
+			"\tat org.codehaus.groovy.control.ErrorCollector.failIfErrors(ErrorCollector.java:310)\n",
+		class:   "org.codehaus.groovy.control.MultipleCompilationErrorsException",
+		message: "startup failed:\nScript1.groovy: 2: unexpected token: } @ line 2, column 1.\n   }\n   ^\n\n1 error",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			groovyErr := ParseGroovyError(tt.output)
+			if tt.class == "" {
+				assert.Nil(t, groovyErr)
+				return
+			}
+			if assert.NotNil(t, groovyErr) {
+				assert.Equal(t, tt.class, groovyErr.Class)
+				assert.Equal(t, tt.message, groovyErr.Message)
+				assert.Equal(t, tt.output, groovyErr.Output)
+			}
+		})
+	}
+}
@@ -0,0 +1,239 @@
+// Package script runs Groovy scripts via the script console of Jenkins, on the controller or on an agent.
+package script
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jenkins-zh/jenkins-client/pkg/core"
+)
+
+// ResultMarker is the prefix of the line which the helper jsonResult prints, the rest of the line is JSON
+const ResultMarker = "@@jenkins-client-result@@"
+
+// ErrGroovyException indicates that the script throws an exception
+var ErrGroovyException = errors.New("groovy exception")
+
+// Client runs Groovy scripts via the script console, it requires the Overall/Administer permission
+type Client struct {
+	core.JenkinsCore
+}
+
+// Options are the options of running a script
+type Options struct {
+	// Node is the name of the agent which runs the script, it runs on the controller if it's empty
+	Node string
+	// Bindings are the variables of the script, they are serialized as JSON then parsed in the script,
+	// so the values should be able to be marshaled as JSON
+	Bindings map[string]interface{}
+	// Timeout is how long the client waits for the output, the timeout of the client is used if it's zero.
+	// Jenkins does not interrupt the script once the client gives up.
+	Timeout time.Duration
+}
+
+// GroovyError is an exception which is thrown by the script
+type GroovyError struct {
+	// Class is the class name of the exception, such as groovy.lang.MissingPropertyException
+	Class      string
+	Message    string
+	StackTrace string
+	// Output is the whole output of the script
+	Output string
+}
+
+// Error returns the class and the message of the exception
+func (e *GroovyError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("%v: %s", ErrGroovyException, e.Class)
+	}
+	return fmt.Sprintf("%v: %s: %s", ErrGroovyException, e.Class, e.Message)
+}
+
+// Is reports whether the target is ErrGroovyException
+func (e *GroovyError) Is(target error) bool {
+	return target == ErrGroovyException
+}
+
+// IsGroovyException returns true if the error is thrown by the script
+func IsGroovyException(err error) bool {
+	return errors.Is(err, ErrGroovyException)
+}
+
+// Run runs the script then returns its output
+func (c *Client) Run(script string, options Options) (output string, err error) {
+	return c.RunWithContext(context.Background(), script, options)
+}
+
+// RunWithContext runs the script with a context then returns its output.
+// The error is a *GroovyError if the output contains an exception which is thrown by the script.
+// The script could change anything, then it's not allowed in the dry-run mode.
+func (c *Client) RunWithContext(ctx context.Context, script string, options Options) (output string, err error) {
+	if c.DryRun {
+		err = errors.New("cannot run the script in the dry-run mode")
+		return
+	}
+
+	var source string
+	if source, err = Render(script, options.Bindings); err != nil {
+		return
+	}
+
+	if options.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, options.Timeout)
+		defer cancel()
+		// the request is limited by the context instead of the timeout of the HTTP client
+		ctx = core.WithClientTimeout(ctx, 0)
+	}
+
+	api := "/scriptText"
+	if options.Node != "" {
+		api = fmt.Sprintf("/computer/%s/scriptText", url.PathEscape(options.Node))
+	}
+	request := core.NewRequest(api, &c.JenkinsCore)
+	request.AsPostFormRequest().WithValues(url.Values{"script": {source}})
+	if err = request.DoWithContext(ctx); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			err = fmt.Errorf("the script does not finish in %v: %w", options.Timeout, ctx.Err())
+		}
+		return
+	}
+
+	output = string(request.GetData())
+	if groovyErr := ParseGroovyError(output); groovyErr != nil {
+		err = groovyErr
+	}
+	return
+}
+
+// RunJSON runs the script then decodes the value which is printed by jsonResult into the result
+func (c *Client) RunJSON(script string, options Options, result interface{}) (output string, err error) {
+	return c.RunJSONWithContext(context.Background(), script, options, result)
+}
+
+// RunJSONWithContext runs the script with a context then decodes the value which is printed by jsonResult
+// into the result. For instance, jsonResult(Jenkins.instance.pluginManager.plugins*.shortName).
+// The output does not contain the result line.
+func (c *Client) RunJSONWithContext(ctx context.Context, script string, options Options,
+	result interface{}) (output string, err error) {
+	if output, err = c.RunWithContext(ctx, script, options); err != nil {
+		return
+	}
+
+	var data string
+	lines := strings.Split(output, "\n")
+	kept := lines[:0]
+	for _, line := range lines {
+		if strings.HasPrefix(line, ResultMarker) {
+			data = strings.TrimPrefix(line, ResultMarker)
+		} else {
+			kept = append(kept, line)
+		}
+	}
+	output = strings.Join(kept, "\n")
+
+	if data == "" {
+		err = fmt.Errorf("the script does not call jsonResult, the output is: %s", output)
+	} else if err = json.Unmarshal([]byte(data), result); err != nil {
+		err = fmt.Errorf("cannot decode the result of the script, error is %v", err)
+	}
+	return
+}
+
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// prelude defines the helper jsonResult, it's the first line of every script
+const prelude = `def jsonResult = { value -> println('` + ResultMarker + `' + groovy.json.JsonOutput.toJson(value)) }; `
+
+// Render puts the helper jsonResult and the bindings in front of the script, after the leading imports.
+// The bindings are serialized as a JSON string literal then parsed in the script, so the values
+// cannot inject code into the script. The helper and the bindings take one line.
+func Render(script string, bindings map[string]interface{}) (source string, err error) {
+	names := make([]string, 0, len(bindings))
+	for name := range bindings {
+		if !identifierPattern.MatchString(name) || name == "jsonResult" || strings.HasPrefix(name, "__") {
+			err = fmt.Errorf("invalid name of the binding variable: %q", name)
+			return
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	lines := strings.Split(script, "\n")
+	header := 0
+	for header < len(lines) && isHeaderLine(lines[header]) {
+		header++
+	}
+
+	buf := &strings.Builder{}
+	for _, line := range lines[:header] {
+		buf.WriteString(line + "\n")
+	}
+	buf.WriteString(prelude)
+	if len(names) > 0 {
+		var data []byte
+		if data, err = json.Marshal(bindings); err != nil {
+			err = fmt.Errorf("cannot serialize the bindings, error is %v", err)
+			return
+		}
+		fmt.Fprintf(buf, "def __bindings = new groovy.json.JsonSlurperClassic().parseText('%s'); ", groovyString(string(data)))
+		for _, name := range names {
+			fmt.Fprintf(buf, "def %s = __bindings['%s']; ", name, name)
+		}
+	}
+	buf.WriteString("\n")
+	buf.WriteString(strings.Join(lines[header:], "\n"))
+	source = buf.String()
+	return
+}
+
+// isHeaderLine returns true if the line should stay in front of the helper, such as an import
+func isHeaderLine(line string) bool {
+	line = strings.TrimSpace(line)
+	return line == "" || strings.HasPrefix(line, "import ") || strings.HasPrefix(line, "//")
+}
+
+// groovyString escapes the text as the content of a single-quoted Groovy string, which has no interpolation
+func groovyString(text string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `'`, `\'`, "\n", `\n`, "\r", `\r`)
+	return replacer.Replace(text)
+}
+
+// exceptionPattern matches the first line of an exception, such as groovy.lang.MissingPropertyException: message
+var exceptionPattern = regexp.MustCompile(`(?m)^((?:[a-zA-Z_$][\w$]*\.)+[A-Z][\w$]*(?:Exception|Error))(?::[ \t]*(.*))?$`)
+
+// stackFramePattern matches a line of the stack trace, such as "\tat Script1.run(Script1.groovy:1)"
+var stackFramePattern = regexp.MustCompile(`(?m)^[ \t]+at [^\n]*$`)
+
+// ParseGroovyError returns the exception in the output of a script, it's nil if there is no exception.
+// An exception is followed by the stack trace, the lines between them belong to the message,
+// for instance, the errors of a compilation failure.
+func ParseGroovyError(output string) (groovyErr *GroovyError) {
+	for _, matches := range exceptionPattern.FindAllStringSubmatchIndex(output, -1) {
+		rest := output[matches[1]:]
+		frame := stackFramePattern.FindStringIndex(rest)
+		if frame == nil {
+			continue
+		}
+
+		groovyErr = &GroovyError{
+			Class:      output[matches[2]:matches[3]],
+			StackTrace: strings.TrimSpace(output[matches[0]:]),
+			Output:     output,
+		}
+		message := rest[:frame[0]]
+		if matches[4] >= 0 {
+			message = output[matches[4]:matches[5]] + message
+		}
+		groovyErr.Message = strings.TrimSpace(message)
+		return
+	}
+	return
+}
@@ -0,0 +1,82 @@
+package script_test
+
+import (
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jenkins-zh/jenkins-client/pkg/mock/mhttp"
+	"github.com/jenkins-zh/jenkins-client/pkg/script"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("script console test", func() {
+	var (
+		ctrl         *gomock.Controller
+		roundTripper *mhttp.MockRoundTripper
+		client       script.Client
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		roundTripper = mhttp.NewMockRoundTripper(ctrl)
+		client = script.Client{}
+		client.RoundTripper = roundTripper
+		client.URL = "http://localhost"
+	})
+
+	AfterEach(func() {
+		ctrl.Finish()
+	})
+
+	It("run a script on the controller", func() {
+		script.PrepareForRunScript(roundTripper, client.URL, "", "", "", "println name",
+			map[string]interface{}{"name": "it's"}, "it's\n")
+
+		output, err := client.Run("println name", script.Options{Bindings: map[string]interface{}{"name": "it's"}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(output).To(Equal("it's\n"))
+	})
+
+	It("run a script with a timeout on the client itself", func() {
+		script.PrepareForRunScript(roundTripper, client.URL, "", "", "", "println 'hello'", nil, "hello\n")
+
+		Expect(client.Jar).To(BeNil())
+		output, err := client.Run("println 'hello'", script.Options{Timeout: time.Minute})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(output).To(Equal("hello\n"))
+		Expect(client.Jar).NotTo(BeNil())
+		Expect(client.Crumb).NotTo(BeEmpty())
+	})
+
+	It("run a script on an agent, and decode the result", func() {
+		script.PrepareForRunScript(roundTripper, client.URL, "", "", "agent 1", "jsonResult([count: 2])", nil,
+			"hello\n"+script.ResultMarker+`{"count":2}`+"\n")
+
+		result := struct {
+			Count int `json:"count"`
+		}{}
+		output, err := client.RunJSON("jsonResult([count: 2])", script.Options{Node: "agent 1"}, &result)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(output).To(Equal("hello\n"))
+		Expect(result.Count).To(Equal(2))
+	})
+
+	It("the script is not run in the dry-run mode", func() {
+		client.DryRun = true
+		_, err := client.Run("println 'hello'", script.Options{})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("the script throws an exception", func() {
+		script.PrepareForRunScript(roundTripper, client.URL, "", "", "", "foo", nil,
+			"groovy.lang.MissingPropertyException: No such property: foo for class: Script1\n"+
+				"\tat org.codehaus.groovy.runtime.ScriptBytecodeAdapter.unwrap(ScriptBytecodeAdapter.java:66)\n"+
+				"\tat Script1.run(Script1.groovy:2)\n")
+
+		_, err := client.Run("foo", script.Options{})
+		Expect(script.IsGroovyException(err)).To(BeTrue())
+		Expect(err.Error()).To(Equal("groovy exception: groovy.lang.MissingPropertyException: " +
+			"No such property: foo for class: Script1"))
+	})
+})
@@ -0,0 +1,28 @@
+package script
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/jenkins-zh/jenkins-client/pkg/core"
+	"github.com/jenkins-zh/jenkins-client/pkg/mock/mhttp"
+	httpdownloader "github.com/linuxsuren/http-downloader/pkg"
+)
+
+// PrepareForRunScript only for test
+func PrepareForRunScript(roundTripper *mhttp.MockRoundTripper, rootURL, user, password, node, script string,
+	bindings map[string]interface{}, output string) (response *http.Response) {
+	api := "/scriptText"
+	if node != "" {
+		api = fmt.Sprintf("/computer/%s/scriptText", url.PathEscape(node))
+	}
+	source, _ := Render(script, bindings)
+	formValue := url.Values{"script": {source}}
+
+	request, _ := http.NewRequest(http.MethodPost, rootURL+api, strings.NewReader(formValue.Encode()))
+	request.Header.Set(httpdownloader.ContentType, httpdownloader.ApplicationForm)
+	response = core.PrepareCommonPost(request, output, roundTripper, user, password, rootURL)
+	return
+}
@@ -1,6 +1,7 @@
 package user
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -37,24 +38,39 @@ type TokenData struct {
 
 // Get returns a user's detail
 func (q *Client) Get() (status *User, err error) {
+	return q.GetWithContext(context.Background())
+}
+
+// GetWithContext returns a user's detail with a context
+func (q *Client) GetWithContext(ctx context.Context) (status *User, err error) {
 	api := fmt.Sprintf("/user/%s/api/json", q.UserName)
-	err = q.RequestWithData(http.MethodGet, api, nil, nil, 200, &status)
+	err = q.RequestWithDataWithContext(ctx, http.MethodGet, api, nil, nil, 200, &status)
 	return
 }
 
 // EditDesc update the description of a user
 func (q *Client) EditDesc(description string) (err error) {
+	return q.EditDescWithContext(context.Background(), description)
+}
+
+// EditDescWithContext update the description of a user with a context
+func (q *Client) EditDescWithContext(ctx context.Context, description string) (err error) {
 	formData := url.Values{}
 	formData.Add("description", description)
 	payload := strings.NewReader(formData.Encode())
-	_, err = q.RequestWithoutData(http.MethodPost, fmt.Sprintf("/user/%s/submitDescription", q.UserName),
+	_, err = q.RequestWithoutDataWithContext(ctx, http.MethodPost, fmt.Sprintf("/user/%s/submitDescription", q.UserName),
 		map[string]string{httpdownloader.ContentType: httpdownloader.ApplicationForm}, payload, 200)
 	return
 }
 
 // Delete will remove a user from Jenkins
 func (q *Client) Delete(username string) (err error) {
-	_, err = q.RequestWithoutData(http.MethodPost, fmt.Sprintf("/securityRealm/user/%s/doDelete", username),
+	return q.DeleteWithContext(context.Background(), username)
+}
+
+// DeleteWithContext will remove a user from Jenkins with a context
+func (q *Client) DeleteWithContext(ctx context.Context, username string) (err error) {
+	_, err = q.RequestWithoutDataWithContext(ctx, http.MethodPost, fmt.Sprintf("/securityRealm/user/%s/doDelete", username),
 		map[string]string{httpdownloader.ContentType: httpdownloader.ApplicationForm}, nil, 200)
 	return
 }
@@ -83,6 +99,11 @@ func genSimpleUserAsPayload(username, password string) (payload io.Reader, user
 
 // Create will create a user in Jenkins
 func (q *Client) Create(username, password string) (user *ForCreate, err error) {
+	return q.CreateWithContext(context.Background(), username, password)
+}
+
+// CreateWithContext will create a user in Jenkins with a context
+func (q *Client) CreateWithContext(ctx context.Context, username, password string) (user *ForCreate, err error) {
 	var (
 		payload io.Reader
 		code    int
@@ -93,7 +114,7 @@ func (q *Client) Create(username, password string) (user *ForCreate, err error)
 	}
 
 	payload, user = genSimpleUserAsPayload(username, password)
-	code, err = q.RequestWithoutData(http.MethodPost, "/securityRealm/createAccountByAdmin",
+	code, err = q.RequestWithoutDataWithContext(ctx, http.MethodPost, "/securityRealm/createAccountByAdmin",
 		map[string]string{httpdownloader.ContentType: httpdownloader.ApplicationForm}, payload, 200)
 	if code == 302 {
 		err = nil
@@ -121,6 +142,11 @@ func genUserAsPayload(data ForCreate) (io.Reader, error) {
 
 // CreateWithParams will create a user in Jenkins
 func (q *Client) CreateWithParams(data ForCreate) (user *ForCreate, err error) {
+	return q.CreateWithParamsWithContext(context.Background(), data)
+}
+
+// CreateWithParamsWithContext will create a user in Jenkins with a context
+func (q *Client) CreateWithParamsWithContext(ctx context.Context, data ForCreate) (user *ForCreate, err error) {
 	var (
 		payload io.Reader
 		code    int
@@ -131,7 +157,7 @@ func (q *Client) CreateWithParams(data ForCreate) (user *ForCreate, err error) {
 		return nil, err
 	}
 
-	code, err = q.RequestWithoutData(http.MethodPost, "/securityRealm/createAccountByAdmin",
+	code, err = q.RequestWithoutDataWithContext(ctx, http.MethodPost, "/securityRealm/createAccountByAdmin",
 		map[string]string{httpdownloader.ContentType: httpdownloader.ApplicationForm}, payload, 200)
 	if code == 302 {
 		return &data, nil
@@ -141,6 +167,14 @@ func (q *Client) CreateWithParams(data ForCreate) (user *ForCreate, err error) {
 
 // CreateToken create a token in Jenkins
 func (q *Client) CreateToken(targetUser, newTokenName string) (status *Token, err error) {
+	return q.CreateTokenWithContext(context.Background(), targetUser, newTokenName)
+}
+
+// CreateTokenWithContext create a token in Jenkins with a context
+func (q *Client) CreateTokenWithContext(ctx context.Context, targetUser, newTokenName string) (status *Token, err error) {
+	if err = q.Require(ctx, core.RequireTokenAPI); err != nil {
+		return
+	}
 	if newTokenName == "" {
 		newTokenName = fmt.Sprintf("jcli-%s", randomdata.SillyName())
 	}
@@ -155,7 +189,7 @@ func (q *Client) CreateToken(targetUser, newTokenName string) (status *Token, er
 	formData.Add("newTokenName", newTokenName)
 	payload := strings.NewReader(formData.Encode())
 
-	err = q.RequestWithData(http.MethodPost, api,
+	err = q.RequestWithDataWithContext(ctx, http.MethodPost, api,
 		map[string]string{httpdownloader.ContentType: httpdownloader.ApplicationForm}, payload, 200, &status)
 	return
 }
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/golang/mock/gomock"
+	"github.com/jenkins-zh/jenkins-client/pkg/core"
 	"github.com/jenkins-zh/jenkins-client/pkg/mock/mhttp"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -100,6 +101,13 @@ var _ = Describe("user test", func() {
 			Expect(token).NotTo(BeNil())
 			Expect(token.Status).To(Equal("ok"))
 		})
+
+		It("should fail, given an old Jenkins", func() {
+			userClient.SetCapabilities(&core.Capabilities{Version: "2.100"})
+
+			_, err := userClient.CreateToken("", "fakeName")
+			Expect(core.IsCapabilityMissing(err)).To(BeTrue())
+		})
 	})
 
 	Context("CreateWithParams", func() {
@@ -0,0 +1,130 @@
+package view
+
+import (
+	"encoding/xml"
+	"fmt"
+	"regexp"
+)
+
+// DefaultColumns are the columns of a new list view, they are the same as the ones of Jenkins
+var DefaultColumns = []string{
+	"hudson.views.StatusColumn",
+	"hudson.views.WeatherColumn",
+	"hudson.views.JobColumn",
+	"hudson.views.LastSuccessColumn",
+	"hudson.views.LastFailureColumn",
+	"hudson.views.LastDurationColumn",
+	"hudson.views.BuildButtonColumn",
+}
+
+// CreateOptions are the typed options of a new view
+type CreateOptions struct {
+	// Class is ListViewClass, MyViewClass, or NestedViewClass, it's ListViewClass if it's empty
+	Class       string
+	Description string
+
+	// Jobs are the names of the jobs in a list view, they are relative to the folder which owns the view
+	Jobs []string
+	// IncludeRegex adds the jobs whose name matches the regex to a list view
+	IncludeRegex string
+	// Recurse makes a list view contain the jobs in the folders
+	Recurse bool
+	// Columns are the classes of the columns of a list view, it's DefaultColumns if it's empty
+	Columns []string
+}
+
+type classElement struct {
+	Class string `xml:"class,attr"`
+}
+
+type emptyElement struct {
+	XMLName xml.Name
+}
+
+type viewConfig struct {
+	XMLName         xml.Name
+	Name            string       `xml:"name"`
+	Description     string       `xml:"description,omitempty"`
+	FilterExecutors bool         `xml:"filterExecutors"`
+	FilterQueue     bool         `xml:"filterQueue"`
+	Properties      classElement `xml:"properties"`
+
+	// the fields of a list view
+	JobNames *struct {
+		Comparator classElement `xml:"comparator"`
+		Names      []string     `xml:"string"`
+	} `xml:"jobNames,omitempty"`
+	JobFilters *struct{} `xml:"jobFilters,omitempty"`
+	Columns    *struct {
+		Items []emptyElement
+	} `xml:"columns,omitempty"`
+	IncludeRegex string `xml:"includeRegex,omitempty"`
+	Recurse      *bool  `xml:"recurse,omitempty"`
+
+	// the fields of a nested view
+	Views *struct{} `xml:"views,omitempty"`
+}
+
+// ConfigXML returns the config.xml of a new view with the name
+func (o CreateOptions) ConfigXML(name string) (config string, err error) {
+	class := o.Class
+	if class == "" {
+		class = ListViewClass
+	}
+
+	view := viewConfig{
+		// XStream escapes the underscore of the class name
+		XMLName:     xml.Name{Local: escapeClass(class)},
+		Name:        name,
+		Description: o.Description,
+		Properties:  classElement{Class: "hudson.model.View$PropertyList"},
+	}
+	switch class {
+	case ListViewClass:
+		if o.IncludeRegex != "" {
+			if _, err = regexp.Compile(o.IncludeRegex); err != nil {
+				err = fmt.Errorf("invalid regex of the jobs: %v", err)
+				return
+			}
+		}
+		view.JobNames = &struct {
+			Comparator classElement `xml:"comparator"`
+			Names      []string     `xml:"string"`
+		}{Comparator: classElement{Class: "hudson.util.CaseInsensitiveComparator"}, Names: o.Jobs}
+		view.JobFilters = &struct{}{}
+		columns := o.Columns
+		if len(columns) == 0 {
+			columns = DefaultColumns
+		}
+		view.Columns = &struct{ Items []emptyElement }{}
+		for _, column := range columns {
+			view.Columns.Items = append(view.Columns.Items, emptyElement{XMLName: xml.Name{Local: column}})
+		}
+		view.IncludeRegex = o.IncludeRegex
+		view.Recurse = &o.Recurse
+	case NestedViewClass:
+		view.Views = &struct{}{}
+	case MyViewClass:
+	default:
+		err = fmt.Errorf("unsupported view class %q, please use CreateFromXML instead", class)
+		return
+	}
+
+	var data []byte
+	if data, err = xml.MarshalIndent(view, "", "  "); err == nil {
+		config = xml.Header + string(data)
+	}
+	return
+}
+
+// escapeClass escapes the class name as an XML element name like XStream, such as nested__view
+func escapeClass(class string) string {
+	escaped := make([]rune, 0, len(class))
+	for _, r := range class {
+		if r == '_' {
+			escaped = append(escaped, '_')
+		}
+		escaped = append(escaped, r)
+	}
+	return string(escaped)
+}
@@ -0,0 +1,93 @@
+package view
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigXML(t *testing.T) {
+	tests := []struct {
+		name    string
+		options CreateOptions
+		expect  string
+	}{{
+		name: "list view",
+		options: CreateOptions{
+			Description:  "the team jobs",
+			Jobs:         []string{"app", "sub/lib"},
+			IncludeRegex: "team-.*",
+			Recurse:      true,
+			Columns:      []string{"hudson.views.StatusColumn", "hudson.views.JobColumn"},
+		},
+		expect: `<?xml version="1.0" encoding="UTF-8"?>
+<hudson.model.ListView>
+  <name>team</name>
+  <description>the team jobs</description>
+  <filterExecutors>false</filterExecutors>
+  <filterQueue>false</filterQueue>
+  <properties class="hudson.model.View$PropertyList"></properties>
+  <jobNames>
+    <comparator class="hudson.util.CaseInsensitiveComparator"></comparator>
+    <string>app</string>
+    <string>sub/lib</string>
+  </jobNames>
+  <jobFilters></jobFilters>
+  <columns>
+    <hudson.views.StatusColumn></hudson.views.StatusColumn>
+    <hudson.views.JobColumn></hudson.views.JobColumn>
+  </columns>
+  <includeRegex>team-.*</includeRegex>
+  <recurse>true</recurse>
+</hudson.model.ListView>`,
+	}, {
+		name:    "nested view",
+		options: CreateOptions{Class: NestedViewClass},
+		expect: `<?xml version="1.0" encoding="UTF-8"?>
+<hudson.plugins.nested__view.NestedView>
+  <name>team</name>
+  <filterExecutors>false</filterExecutors>
+  <filterQueue>false</filterQueue>
+  <properties class="hudson.model.View$PropertyList"></properties>
+  <views></views>
+</hudson.plugins.nested__view.NestedView>`,
+	}, {
+		name:    "my view",
+		options: CreateOptions{Class: MyViewClass},
+		expect: `<?xml version="1.0" encoding="UTF-8"?>
+<hudson.model.MyView>
+  <name>team</name>
+  <filterExecutors>false</filterExecutors>
+  <filterQueue>false</filterQueue>
+  <properties class="hudson.model.View$PropertyList"></properties>
+</hudson.model.MyView>`,
+	}}
+	for _, tt := range tests {
+		config, err := tt.options.ConfigXML("team")
+		assert.Nil(t, err, tt.name)
+		assert.Equal(t, tt.expect, config, tt.name)
+	}
+
+	config, err := CreateOptions{}.ConfigXML("team")
+	assert.Nil(t, err)
+	assert.Contains(t, config, "<hudson.views.BuildButtonColumn>")
+
+	_, err = CreateOptions{IncludeRegex: "("}.ConfigXML("team")
+	assert.NotNil(t, err)
+	_, err = CreateOptions{Class: "hudson.model.ProxyView"}.ConfigXML("team")
+	assert.NotNil(t, err)
+}
+
+func TestSplitName(t *testing.T) {
+	names, err := splitName("/parent/child/")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"parent", "child"}, names)
+
+	_, err = splitName("")
+	assert.NotNil(t, err)
+	_, err = splitName("parent//child")
+	assert.NotNil(t, err)
+
+	client := &Client{Folder: "team sub"}
+	assert.Equal(t, "/job/team/job/sub/view/a%20b/view/c", client.viewPath([]string{"a b", "c"}))
+}
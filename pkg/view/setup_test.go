@@ -0,0 +1,13 @@
+package view
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestJenkinsClient(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "jenkins client test")
+}
@@ -0,0 +1,272 @@
+// Package view manages the views of Jenkins or a folder, such as the list views of the team dashboards.
+package view
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/jenkins-zh/jenkins-client/pkg/core"
+	"github.com/jenkins-zh/jenkins-client/pkg/job"
+)
+
+const (
+	// ListViewClass is the class of the list view
+	ListViewClass = "hudson.model.ListView"
+	// MyViewClass is the class of the view which shows the jobs of the current user
+	MyViewClass = "hudson.model.MyView"
+	// NestedViewClass is the class of the view which contains other views, it requires the nested-view plugin
+	NestedViewClass = "hudson.plugins.nested_view.NestedView"
+	// AllViewClass is the class of the view which shows all the jobs
+	AllViewClass = "hudson.model.AllView"
+)
+
+// Client manages the views of Jenkins or a folder.
+// The name of a nested view is the path from the top level view, such as "parent/child".
+type Client struct {
+	core.JenkinsCore
+	// Folder owns the views, such as "folder sub", the views of Jenkins are managed if it's empty
+	Folder string
+}
+
+// View is a view of Jenkins or a folder
+type View struct {
+	Class       string `json:"_class"`
+	Name        string `json:"name"`
+	URL         string `json:"url"`
+	Description string `json:"description"`
+	// FullName is the path of the view, such as parent/child
+	FullName string `json:"-"`
+	// Views are the nested views
+	Views []View `json:"views"`
+	// Jobs are only returned by Get
+	Jobs []Job `json:"jobs"`
+}
+
+// Job is a job in a view
+type Job struct {
+	Class string `json:"_class"`
+	Name  string `json:"name"`
+	// FullName is the full name of the job, such as folder/job
+	FullName string `json:"fullName"`
+	URL      string `json:"url"`
+	Color    string `json:"color"`
+}
+
+// viewFields are the fields of a view in the list
+var viewFields = core.Fields("_class", "name", "url", "description")
+
+// List returns all the views, the nested views are in the field Views
+func (c *Client) List() (views []View, err error) {
+	return c.ListWithContext(context.Background())
+}
+
+// ListWithContext returns all the views with a context
+func (c *Client) ListWithContext(ctx context.Context) (views []View, err error) {
+	return c.listViews(ctx, nil)
+}
+
+// listViews returns the views in the parent view, and the nested views of them
+func (c *Client) listViews(ctx context.Context, parent []string) (views []View, err error) {
+	result := struct {
+		Views []View `json:"views"`
+	}{}
+	api := core.WithQuery(c.viewPath(parent)+"/api/json", core.WithTree(core.Field("views", viewFields...)))
+	if err = c.RequestWithDataWithContext(ctx, http.MethodGet, api, nil, nil, 200, &result); err != nil {
+		return
+	}
+
+	views = result.Views
+	for i := range views {
+		names := append(parent[:len(parent):len(parent)], views[i].Name)
+		views[i].FullName = strings.Join(names, "/")
+		if views[i].Class == NestedViewClass {
+			if views[i].Views, err = c.listViews(ctx, names); err != nil {
+				return
+			}
+		}
+	}
+	return
+}
+
+// Get returns a view with its jobs and its direct nested views
+func (c *Client) Get(name string) (view *View, err error) {
+	return c.GetWithContext(context.Background(), name)
+}
+
+// GetWithContext returns a view with its jobs and its direct nested views with a context
+func (c *Client) GetWithContext(ctx context.Context, name string) (view *View, err error) {
+	var names []string
+	if names, err = splitName(name); err != nil {
+		return
+	}
+	tree := core.WithTree(append(viewFields,
+		core.Field("jobs", core.Fields("_class", "name", "fullName", "url", "color")...),
+		core.Field("views", viewFields...))...)
+	api := core.WithQuery(c.viewPath(names)+"/api/json", tree)
+	// decode into a value, then a null body doesn't leave a nil view
+	result := View{}
+	if err = c.RequestWithDataWithContext(ctx, http.MethodGet, api, nil, nil, 200, &result); err == nil {
+		result.FullName = strings.Join(names, "/")
+		for i := range result.Views {
+			result.Views[i].FullName = result.FullName + "/" + result.Views[i].Name
+		}
+		view = &result
+	}
+	return
+}
+
+// GetJobs returns the jobs in a view
+func (c *Client) GetJobs(name string) (jobs []Job, err error) {
+	return c.GetJobsWithContext(context.Background(), name)
+}
+
+// GetJobsWithContext returns the jobs in a view with a context
+func (c *Client) GetJobsWithContext(ctx context.Context, name string) (jobs []Job, err error) {
+	var view *View
+	if view, err = c.GetWithContext(ctx, name); err == nil {
+		jobs = view.Jobs
+	}
+	return
+}
+
+// Create creates a view with the options, it's created in the parent view if the name is a path
+func (c *Client) Create(name string, options CreateOptions) (err error) {
+	return c.CreateWithContext(context.Background(), name, options)
+}
+
+// CreateWithContext creates a view with the options with a context
+func (c *Client) CreateWithContext(ctx context.Context, name string, options CreateOptions) (err error) {
+	var names []string
+	if names, err = splitName(name); err != nil {
+		return
+	}
+	var config string
+	if config, err = options.ConfigXML(names[len(names)-1]); err == nil {
+		err = c.CreateFromXMLWithContext(ctx, name, config)
+	}
+	return
+}
+
+// CreateFromXML creates a view with the config.xml
+func (c *Client) CreateFromXML(name, config string) (err error) {
+	return c.CreateFromXMLWithContext(context.Background(), name, config)
+}
+
+// CreateFromXMLWithContext creates a view with the config.xml with a context
+func (c *Client) CreateFromXMLWithContext(ctx context.Context, name, config string) (err error) {
+	var names []string
+	if names, err = splitName(name); err != nil {
+		return
+	}
+	last := len(names) - 1
+	api := fmt.Sprintf("%s/createView?%s", c.viewPath(names[:last]), url.Values{"name": {names[last]}}.Encode())
+	request := core.NewRequest(api, &c.JenkinsCore)
+	request.WithPostMethod().AddHeader("Content-Type", "application/xml").WithPayload(strings.NewReader(config))
+	err = request.DoWithContext(ctx)
+	return
+}
+
+// AddJob adds a job to a view, the job name is relative to the folder which owns the view, such as sub/job
+func (c *Client) AddJob(name, jobName string) (err error) {
+	return c.AddJobWithContext(context.Background(), name, jobName)
+}
+
+// AddJobWithContext adds a job to a view with a context
+func (c *Client) AddJobWithContext(ctx context.Context, name, jobName string) (err error) {
+	return c.post(ctx, name, "addJobToView", url.Values{"name": {jobName}})
+}
+
+// RemoveJob removes a job from a view
+func (c *Client) RemoveJob(name, jobName string) (err error) {
+	return c.RemoveJobWithContext(context.Background(), name, jobName)
+}
+
+// RemoveJobWithContext removes a job from a view with a context
+func (c *Client) RemoveJobWithContext(ctx context.Context, name, jobName string) (err error) {
+	return c.post(ctx, name, "removeJobFromView", url.Values{"name": {jobName}})
+}
+
+// GetConfigXML returns the config.xml of a view
+func (c *Client) GetConfigXML(name string) (config string, err error) {
+	return c.GetConfigXMLWithContext(context.Background(), name)
+}
+
+// GetConfigXMLWithContext returns the config.xml of a view with a context
+func (c *Client) GetConfigXMLWithContext(ctx context.Context, name string) (config string, err error) {
+	var names []string
+	if names, err = splitName(name); err != nil {
+		return
+	}
+	request := core.NewRequest(c.viewPath(names)+"/config.xml", &c.JenkinsCore)
+	if err = request.DoWithContext(ctx); err == nil {
+		config = string(request.GetData())
+	}
+	return
+}
+
+// UpdateConfigXML replaces the config.xml of a view
+func (c *Client) UpdateConfigXML(name, config string) (err error) {
+	return c.UpdateConfigXMLWithContext(context.Background(), name, config)
+}
+
+// UpdateConfigXMLWithContext replaces the config.xml of a view with a context
+func (c *Client) UpdateConfigXMLWithContext(ctx context.Context, name, config string) (err error) {
+	var names []string
+	if names, err = splitName(name); err != nil {
+		return
+	}
+	request := core.NewRequest(c.viewPath(names)+"/config.xml", &c.JenkinsCore)
+	request.WithPostMethod().AddHeader("Content-Type", "application/xml").WithPayload(strings.NewReader(config))
+	err = request.DoWithContext(ctx)
+	return
+}
+
+// Delete deletes a view, the nested views are deleted as well
+func (c *Client) Delete(name string) (err error) {
+	return c.DeleteWithContext(context.Background(), name)
+}
+
+// DeleteWithContext deletes a view with a context
+func (c *Client) DeleteWithContext(ctx context.Context, name string) (err error) {
+	return c.post(ctx, name, "doDelete", nil)
+}
+
+// post sends an action of a view, Jenkins redirects to the view once it's done
+func (c *Client) post(ctx context.Context, name, action string, values url.Values) (err error) {
+	var names []string
+	if names, err = splitName(name); err != nil {
+		return
+	}
+	api := fmt.Sprintf("%s/%s", c.viewPath(names), action)
+	if len(values) > 0 {
+		api += "?" + values.Encode()
+	}
+	err = core.NewRequest(api, &c.JenkinsCore).WithPostMethod().
+		AcceptStatusCode(http.StatusFound).DoWithContext(ctx)
+	return
+}
+
+// viewPath returns the URL path of a view, such as /job/folder/view/parent/view/child
+func (c *Client) viewPath(names []string) string {
+	path := job.ParseJobPath(c.Folder)
+	for _, name := range names {
+		path += "/view/" + url.PathEscape(name)
+	}
+	return path
+}
+
+// splitName splits the path of a nested view, the names of the views never contain a slash
+func splitName(name string) (names []string, err error) {
+	for _, item := range strings.Split(strings.Trim(name, "/"), "/") {
+		if item == "" {
+			err = errors.New("the view name is required")
+			return
+		}
+		names = append(names, item)
+	}
+	return
+}
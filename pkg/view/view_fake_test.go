@@ -0,0 +1,76 @@
+package view_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jenkins-zh/jenkins-client/pkg/core"
+	"github.com/jenkins-zh/jenkins-client/pkg/fakejenkins"
+	"github.com/jenkins-zh/jenkins-client/pkg/job"
+	"github.com/jenkins-zh/jenkins-client/pkg/view"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestViews(t *testing.T) {
+	server := fakejenkins.NewServer()
+	defer server.Close()
+	for _, folder := range []string{"team", "team/sub"} {
+		assert.Nil(t, server.CreateFolder(folder))
+	}
+	for _, name := range []string{"app", "team-api", "team/web", "team/sub/lib"} {
+		assert.Nil(t, server.CreateJob(name, job.WorkflowJobClass))
+	}
+
+	client := &view.Client{JenkinsCore: server.JenkinsCore()}
+	assert.Nil(t, client.Create("dashboards", view.CreateOptions{Class: view.NestedViewClass}))
+	assert.Nil(t, client.Create("dashboards/team", view.CreateOptions{IncludeRegex: "team-.*", Jobs: []string{"app"}}))
+	assert.Nil(t, client.Create("mine", view.CreateOptions{Class: view.MyViewClass, Description: "my jobs"}))
+	assert.NotNil(t, client.Create("mine", view.CreateOptions{}))
+
+	views, err := client.List()
+	if assert.Nil(t, err) && assert.Len(t, views, 3) {
+		assert.Equal(t, []string{"all", "dashboards", "mine"}, []string{views[0].Name, views[1].Name, views[2].Name})
+		assert.Equal(t, "my jobs", views[2].Description)
+		if assert.Len(t, views[1].Views, 1) {
+			assert.Equal(t, "dashboards/team", views[1].Views[0].FullName)
+			assert.Equal(t, view.ListViewClass, views[1].Views[0].Class)
+		}
+	}
+
+	jobNames := func(client *view.Client, name string) (names []string) {
+		jobs, err := client.GetJobs(name)
+		assert.Nil(t, err)
+		for _, item := range jobs {
+			names = append(names, item.FullName)
+		}
+		return
+	}
+	assert.Equal(t, []string{"app", "team-api"}, jobNames(client, "dashboards/team"))
+	assert.Nil(t, client.RemoveJob("dashboards/team", "app"))
+	assert.Equal(t, []string{"team-api"}, jobNames(client, "dashboards/team"))
+	assert.NotNil(t, client.AddJob("dashboards/team", "missing"))
+
+	config, err := client.GetConfigXML("dashboards/team")
+	assert.Nil(t, err)
+	assert.Nil(t, client.UpdateConfigXML("dashboards/team", strings.Replace(config, "team-.*", "app", 1)))
+	assert.Equal(t, []string{"app"}, jobNames(client, "dashboards/team"))
+
+	// the views of a folder, the job names are relative to the folder
+	folderClient := &view.Client{JenkinsCore: server.JenkinsCore(), Folder: "team"}
+	assert.Nil(t, folderClient.Create("libs", view.CreateOptions{IncludeRegex: ".*lib", Recurse: true}))
+	assert.Equal(t, []string{"team/sub/lib"}, jobNames(folderClient, "libs"))
+	assert.Nil(t, folderClient.AddJob("libs", "web"))
+	assert.Equal(t, []string{"team/sub/lib", "team/web"}, jobNames(folderClient, "libs"))
+	views, err = folderClient.List()
+	assert.Nil(t, err)
+	assert.Len(t, views, 2)
+
+	assert.Nil(t, client.Delete("dashboards"))
+	_, err = client.Get("dashboards/team")
+	assert.True(t, core.IsNotFound(err))
+	assert.NotNil(t, client.Delete("all"))
+	assert.Nil(t, folderClient.Delete("libs"))
+	views, err = folderClient.List()
+	assert.Nil(t, err)
+	assert.Len(t, views, 1)
+}
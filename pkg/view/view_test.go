@@ -0,0 +1,84 @@
+package view_test
+
+import (
+	"github.com/golang/mock/gomock"
+	"github.com/jenkins-zh/jenkins-client/pkg/mock/mhttp"
+	"github.com/jenkins-zh/jenkins-client/pkg/view"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("view test", func() {
+	var (
+		ctrl         *gomock.Controller
+		roundTripper *mhttp.MockRoundTripper
+		client       view.Client
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		roundTripper = mhttp.NewMockRoundTripper(ctrl)
+		client = view.Client{}
+		client.RoundTripper = roundTripper
+		client.URL = "http://localhost"
+	})
+
+	AfterEach(func() {
+		ctrl.Finish()
+	})
+
+	It("list the views with the nested ones", func() {
+		view.PrepareForGetView(roundTripper, client.URL, "", "", "", `{"views":[
+			{"_class":"hudson.model.AllView","name":"all"},
+			{"_class":"hudson.plugins.nested_view.NestedView","name":"team"}]}`)
+		view.PrepareForGetView(roundTripper, client.URL, "/view/team", "", "", `{"views":[
+			{"_class":"hudson.model.ListView","name":"release"}]}`)
+
+		views, err := client.List()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(views).To(HaveLen(2))
+		Expect(views[1].Views).To(HaveLen(1))
+		Expect(views[1].Views[0].FullName).To(Equal("team/release"))
+	})
+
+	It("get the jobs of a view in a folder", func() {
+		client.Folder = "folder"
+		view.PrepareForGetView(roundTripper, client.URL, "/job/folder/view/team/view/release", "", "", `{
+			"name":"release","jobs":[{"name":"app","fullName":"folder/app","color":"blue"}]}`)
+
+		jobs, err := client.GetJobs("team/release")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(jobs).To(HaveLen(1))
+		Expect(jobs[0].FullName).To(Equal("folder/app"))
+	})
+
+	It("get a view whose body is null", func() {
+		view.PrepareForGetView(roundTripper, client.URL, "/view/release", "", "", "null")
+
+		result, err := client.Get("release")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).NotTo(BeNil())
+		Expect(result.FullName).To(Equal("release"))
+	})
+
+	It("create a nested view from the typed options", func() {
+		config, err := view.CreateOptions{Class: view.ListViewClass, IncludeRegex: ".*-release"}.ConfigXML("release")
+		Expect(err).NotTo(HaveOccurred())
+		view.PrepareForCreateView(roundTripper, client.URL, "/view/team", "release", "", "", config)
+
+		err = client.Create("team/release", view.CreateOptions{IncludeRegex: ".*-release"})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("add a job, then delete the view", func() {
+		view.PrepareForViewAction(roundTripper, client.URL, "/view/release", "addJobToView?name=sub%2Fapp", "", "")
+		view.PrepareForViewAction(roundTripper, client.URL, "/view/release", "doDelete", "", "")
+
+		Expect(client.AddJob("release", "sub/app")).To(Succeed())
+		Expect(client.Delete("release")).To(Succeed())
+	})
+
+	It("the view name is empty", func() {
+		Expect(client.Delete("")).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,42 @@
+package view
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/jenkins-zh/jenkins-client/pkg/core"
+	"github.com/jenkins-zh/jenkins-client/pkg/mock/mhttp"
+)
+
+// PrepareForGetView only for test, the viewPath is like /view/parent/view/child
+func PrepareForGetView(roundTripper *mhttp.MockRoundTripper, rootURL, viewPath, user, password, body string) {
+	request, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("%s%s/api/json", rootURL, viewPath), nil)
+	if user != "" && password != "" {
+		request.SetBasicAuth(user, password)
+	}
+	response := &http.Response{
+		StatusCode: 200,
+		Request:    request,
+		Body:       ioutil.NopCloser(bytes.NewBufferString(body)),
+	}
+	roundTripper.EXPECT().
+		RoundTrip(core.NewRequestMatcher(request)).Return(response, nil)
+}
+
+// PrepareForCreateView only for test
+func PrepareForCreateView(roundTripper *mhttp.MockRoundTripper, rootURL, parentPath, name, user, password, config string) {
+	request, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("%s%s/createView?%s", rootURL, parentPath,
+		url.Values{"name": {name}}.Encode()), strings.NewReader(config))
+	request.Header.Add("Content-Type", "application/xml")
+	core.PrepareCommonPost(request, "", roundTripper, user, password, rootURL)
+}
+
+// PrepareForViewAction only for test, the action is like addJobToView?name=job
+func PrepareForViewAction(roundTripper *mhttp.MockRoundTripper, rootURL, viewPath, action, user, password string) {
+	request, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("%s%s/%s", rootURL, viewPath, action), nil)
+	core.PrepareCommonPost(request, "", roundTripper, user, password, rootURL)
+}